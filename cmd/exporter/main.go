@@ -1,71 +1,279 @@
 package main
 
 import (
-	"net/http"
+	"context"
+	"flag"
+	"fmt"
 	"os"
+	"sync/atomic"
 	"time"
 
+	"github.com/Entrio/subenv"
 	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/capture"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/exchange"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/heartbeat"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/leader"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/limits"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/logging"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/reporting"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/server"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/snapshot"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/store"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/systemd"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// shutdownTimeout is how long in-flight requests may take once the exporter is asked to stop
+const shutdownTimeout = 5 * time.Second
+
 func main() {
-	highPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= zapcore.ErrorLevel
-	})
-	lowPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl < zapcore.ErrorLevel
-	})
-
-	consoleDebugging := zapcore.Lock(os.Stdout)
-	consoleErrors := zapcore.Lock(os.Stderr)
-
-	consoleEncoder := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
-
-	core := zapcore.NewTee(
-		//zapcore.NewCore(kafkaEncoder, topicErrors, highPriority),
-		zapcore.NewCore(consoleEncoder, consoleErrors, highPriority),
-		//zapcore.NewCore(kafkaEncoder, topicDebugging, lowPriority),
-		zapcore.NewCore(consoleEncoder, consoleDebugging, lowPriority),
-	)
-
-	logger := zap.New(core)
+	demo := flag.Bool("demo", false, "Serve synthetic balances and prices without contacting binance")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-demo] [install|uninstall|run]\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Without a command the exporter runs in the foreground, the commands manage it as a Windows service.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if command := flag.Arg(0); len(command) > 0 {
+		if err := serviceCommand(command, *demo); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to %s the service: %s\n", command, err)
+			os.Exit(1)
+		}
+		return
+	}
+	run(context.Background(), *demo)
+}
+
+/*
+run starts the exporter and serves until ctx is done, then it shuts the servers down and returns. Failures to start and
+servers stopping on their own exit the process.
+*/
+func run(ctx context.Context, demo bool) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %s\n", err)
+		os.Exit(1)
+	}
+
+	logger, err := logging.New(cfg.Log, subenv.Env("B_PUBLIC_KEY", ""), subenv.Env("B_PRIVATE_KEY", ""))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create logger: %s\n", err)
+		os.Exit(1)
+	}
 	defer logger.Sync()
+	limits.Setup(cfg.Runtime.MemoryLimitRatio, logger)
 
-	bc := binance.NewBinanceClient(logger)
-	ss, err := bc.GetSystemStatus()
+	flushReports, err := reporting.Setup(cfg.Sentry.DSN, cfg.Sentry.Environment, cfg.Sentry.FailureThreshold)
 	if err != nil {
-		logger.Error("Failed to get Binance API status!", zap.Error(err))
+		logger.Error("Failed to set up error reporting!", zap.Error(err))
 		os.Exit(1)
 	}
+	defer flushReports()
+	defer reporting.Recover()
 
-	if ss != binance.Online {
-		logger.Error("Binance API is currently under maintenance, exiting...")
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		logger.Error("Failed to set up tracing!", zap.Error(err))
 		os.Exit(1)
 	}
+	// Not ctx, which is done by the time pending spans are flushed
+	defer shutdownTracing(context.Background())
 
-	bc.GetFundingWallet()
-	bc.GetUserAssets()
+	if cfg.Admin.CaptureRequests > 0 {
+		if len(cfg.Admin.Token) == 0 {
+			logger.Warn("EXPORTER_DEBUG_REQUESTS is set without EXPORTER_ADMIN_TOKEN, /debug/requests is not served")
+		}
+		capture.Default = capture.New(cfg.Admin.CaptureRequests, cfg.Admin.CaptureBodyLimit)
+	}
+	var bc binance.BinanceAPI
+	if demo {
+		logger.Warn("Running in demo mode, all metrics are synthetic!")
+		bc = binance.NewDemoClient(logger)
+	} else {
+		bc = binance.NewBinanceClient(cfg.UserAgent, logger)
+	}
+	provider := exchange.NewBinance(bc)
+	if cfg.Startup.Policy == config.StartupFailFast {
+		if err := checkOnline(ctx, provider); err != nil {
+			logger.Error("Binance API is not available, exiting...", zap.Error(err))
+			os.Exit(1)
+		}
+	}
 
-	e := echo.New()
-	e.HideBanner = true
-	e.Use(ZapLogger(logger))
+	if store.Default, err = store.Open(cfg.Store.Path); err != nil {
+		logger.Error("Failed to open the store!", zap.String("path", cfg.Store.Path), zap.Error(err))
+		os.Exit(1)
+	}
 
-	e.GET("/metrics", func(c echo.Context) error {
+	col := collector.New(provider, bc, cfg, logger)
+	var leading func() bool
+	if cfg.Leader.Enabled {
+		elector, err := leader.New(cfg.Leader, cfg.UserAgent, logger)
+		if err != nil {
+			logger.Error("Failed to set up leader election!", zap.Error(err))
+			os.Exit(1)
+		}
+		prometheus.Default.MustRegister(elector)
+		leading = elector.IsLeader
+		col.PollWhile(leading)
+		go func() {
+			defer reporting.Recover()
+			elector.Run(ctx)
+		}()
+	}
+	if len(cfg.Heartbeat.URL) > 0 {
+		col.OnSuccess(heartbeat.New(cfg.Heartbeat, cfg.UserAgent, logger).Ping)
+	}
+	poll := func() {
+		if cfg.Scrape.OnDemand {
+			logger.Info("Collecting on every scrape, not polling in the background", zap.Duration("deadline", cfg.Scrape.Deadline))
+			return
+		}
+		col.Poll(ctx)
+	}
+	// Scrapes only collect on demand once binance is known to be online
+	online := atomic.Bool{}
+	if cfg.Startup.Policy == config.StartupFailFast {
+		// Standbys collect once as well, so they have metrics to serve until they become the leader
+		col.Collect(ctx)
+		online.Store(true)
+		go func() {
+			defer reporting.Recover()
+			poll()
+		}()
+	} else {
+		go func() {
+			defer reporting.Recover()
+			if waitOnline(ctx, provider, clock.Real, cfg.Startup.RetryInterval, logger) {
+				online.Store(true)
+				col.Collect(ctx)
+				poll()
+			}
+		}()
+	}
 
-		funding := bc.GetFundingAssets()
-		spot := bc.GetSpotAssets()
+	if len(cfg.Snapshot.Bucket) > 0 {
+		uploader, err := snapshot.New(cfg.Snapshot, cfg.UserAgent, cfg.Account, col.Snapshot, leading, logger)
+		if err != nil {
+			logger.Error("Failed to set up snapshots!", zap.Error(err))
+			os.Exit(1)
+		}
+		go func() {
+			defer reporting.Recover()
+			uploader.Run(ctx)
+		}()
+	}
+
+	if len(cfg.Admin.Token) == 0 {
+		logger.Info("EXPORTER_ADMIN_TOKEN is not set, admin endpoints are disabled")
+	}
+	if len(cfg.Auth.Accounts) > 0 && len(cfg.Auth.Metrics) == 0 {
+		logger.Warn("EXPORTER_ACCOUNT_TOKENS is set without EXPORTER_METRICS_TOKEN, /metrics serves every account to anyone")
+	}
+	collect := func() bool {
+		return online.Load() && (leading == nil || leading())
+	}
+	// Shared by all listeners, the limits protect binance and the collectors rather than a listener
+	scrapeLimit := server.ScrapeRateLimit(cfg.Scrape.RateLimit)
+	scrapeConcurrency := server.ScrapeConcurrency(cfg.Scrape.Concurrency)
+	links := append(append([]server.Link(nil), server.DefaultLinks...), server.Link{Path: "/metrics/" + cfg.Account, Description: "Prometheus metrics of the " + cfg.Account + " account only"})
+
+	// newServer creates the server of a listener with the route groups it serves
+	newServer := func(l config.Listener) *echo.Echo {
+		e := echo.New()
+		e.HideBanner = true
+		e.Use(server.RequestID())
+		e.Use(ZapLogger(logger, cfg.Log.AccessSample))
+		e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				defer reporting.Recover()
+				return next(c)
+			}
+		})
+
+		e.GET("/healthz", server.HealthHandler)
+		e.GET("/readyz", server.ReadyHandler(col, cfg.Startup))
+		if l.Serves(config.RoutesWeb) {
+			e.GET("/", server.LandingHandler([]string{cfg.Account}, col, links))
+			e.GET("/dashboard.json", server.DashboardHandler(cfg, []string{cfg.Account}, col))
+			e.GET("/alerts.yaml", server.AlertsHandler(cfg, col))
+			e.GET("/metrics-docs", server.MetricsDocsHandler(col, cfg.Metrics.Naming()))
+		}
+		if l.Serves(config.RoutesAPI) {
+			api := e.Group("/api/v1", server.BearerAuth(cfg.Auth.API))
+			api.GET("/events", server.EventsHandler(col))
+		}
+		if l.Serves(config.RoutesAdmin) && len(cfg.Admin.Token) > 0 {
+			admin := e.Group("", server.AdminAuth(cfg.Admin.Token))
+			admin.GET("/debug/config", server.ConfigHandler(cfg))
+			if capture.Default != nil {
+				admin.GET("/debug/requests", server.RequestsHandler(capture.Default))
+			}
+			admin.POST("/-/refresh", server.RefreshHandler(ctx, col))
+		}
+		if l.Serves(config.RoutesMetrics) {
+			e.GET("/metrics", server.MetricsHandler(cfg, col, collect), server.BearerAuth(cfg.Auth.Metrics), scrapeLimit, scrapeConcurrency, middleware.Gzip())
+			e.GET("/metrics/:account", server.AccountMetricsHandler(cfg, map[string]*collector.Registry{cfg.Account: col}, collect), server.AccountAuth(cfg.Auth.Accounts, cfg.Auth.Metrics), scrapeLimit, scrapeConcurrency, middleware.Gzip())
+		}
+		return e
+	}
 
-		return c.String(http.StatusOK, "OK")
-	})
+	// Every listener is opened before systemd hears that the exporter is ready
+	servers := make([]*echo.Echo, 0, len(cfg.Listeners))
+	for _, l := range cfg.Listeners {
+		listener, err := server.Listen(l.Address)
+		if err != nil {
+			logger.Error("Failed to listen!", zap.String("address", l.Address), zap.Error(err))
+			os.Exit(1)
+		}
+		e := newServer(l)
+		e.Listener = listener
+		servers = append(servers, e)
+	}
 
-	e.Logger.Fatal(e.Start(":1323"))
+	if ok, err := systemd.Notify(systemd.Ready); err != nil {
+		logger.Warn("Failed to notify systemd", zap.Error(err))
+	} else if ok {
+		go systemd.Watchdog(ctx, col.Healthy, logger)
+	}
+	// The exporter exits as soon as any of the servers stops
+	stopped := make(chan error, len(servers))
+	for _, e := range servers {
+		go func(e *echo.Echo) {
+			stopped <- e.Start("")
+		}(e)
+	}
+	select {
+	case err := <-stopped:
+		servers[0].Logger.Fatal(err)
+	case <-ctx.Done():
+	}
+	logger.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, e := range servers {
+		if err := e.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("Failed to shut down the server gracefully", zap.Error(err))
+		}
+	}
 }
 
-// ZapLogger is an example of echo middleware that logs requests using logger "zap"
-func ZapLogger(log *zap.Logger) echo.MiddlewareFunc {
+/*
+ZapLogger is an example of echo middleware that logs requests using logger "zap". Of the successful requests only 1 in
+sample is logged, none with a sample of 0, so frequent scrapes don't drown the log. Every other response is logged.
+*/
+func ZapLogger(log *zap.Logger, sample int) echo.MiddlewareFunc {
+	successes := atomic.Uint64{}
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			start := time.Now()
@@ -78,15 +286,10 @@ func ZapLogger(log *zap.Logger) echo.MiddlewareFunc {
 			req := c.Request()
 			res := c.Response()
 
-			id := req.Header.Get(echo.HeaderXRequestID)
-			if id == "" {
-				id = res.Header().Get(echo.HeaderXRequestID)
-			}
-
 			fields := []zapcore.Field{
 				zap.Int("status", res.Status),
 				zap.String("latency", time.Since(start).String()),
-				zap.String("id", id),
+				zap.String("request_id", res.Header().Get(echo.HeaderXRequestID)),
 				zap.String("method", req.Method),
 				zap.String("uri", req.RequestURI),
 				zap.String("host", req.Host),
@@ -101,7 +304,7 @@ func ZapLogger(log *zap.Logger) echo.MiddlewareFunc {
 				log.Warn("Client error", fields...)
 			case n >= 300:
 				log.Info("Redirection", fields...)
-			default:
+			case sample > 0 && (successes.Add(1)-1)%uint64(sample) == 0:
 				log.Info("Success", fields...)
 			}
 
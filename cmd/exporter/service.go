@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	serviceName        = "binance_exporter"
+	serviceDisplayName = "Binance Prometheus Exporter"
+	serviceDescription = "Exports the balances and account metrics of a binance account to prometheus"
+)
+
+var errServiceUnsupported = errors.New("services are only supported on Windows, use systemd or a container elsewhere")
+
+/*
+serviceCommand installs the exporter as a Windows service, uninstalls it or runs it under the service control manager,
+which starts the installed service with the run command. demo is passed on to the installed service.
+*/
+func serviceCommand(command string, demo bool) error {
+	switch command {
+	case "install":
+		return installService(demo)
+	case "uninstall":
+		return uninstallService()
+	case "run":
+		return runService(demo)
+	}
+	return fmt.Errorf("unknown command %q, expected install, uninstall or run", command)
+}
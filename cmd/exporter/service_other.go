@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+func installService(bool) error {
+	return errServiceUnsupported
+}
+
+func uninstallService() error {
+	return errServiceUnsupported
+}
+
+func runService(bool) error {
+	return errServiceUnsupported
+}
@@ -0,0 +1,151 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// serviceRestartDelay is how long the service control manager waits before restarting a crashed exporter
+const serviceRestartDelay = 10 * time.Second
+
+/*
+installService registers the running executable as an automatically started service. The service has no shell to
+inherit variables from, so the EXPORTER_* and B_* variables of the installing shell are stored as the environment of
+the service, which is readable by administrators only. Crashes are restarted after serviceRestartDelay.
+*/
+func installService(demo bool) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is installed already, uninstall it first", serviceName)
+	}
+	args := []string{"run"}
+	if demo {
+		args = []string{"-demo", "run"}
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	restart := []mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: serviceRestartDelay}}
+	if err := s.SetRecoveryActions(restart, uint32((24 * time.Hour).Seconds())); err != nil {
+		return fmt.Errorf("failed to set the recovery actions: %w", err)
+	}
+	if err := storeEnvironment(); err != nil {
+		return fmt.Errorf("failed to store the environment of the service: %w", err)
+	}
+	fmt.Printf("Installed service %s, start it with: sc start %s\n", serviceName, serviceName)
+	return nil
+}
+
+// storeEnvironment stores the configuration variables of the current process as the environment of the service
+func storeEnvironment() error {
+	env := make([]string, 0)
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "EXPORTER_") || strings.HasPrefix(kv, "B_") {
+			env = append(env, kv)
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	sort.Strings(env)
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+serviceName, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	return k.SetStringsValue("Environment", env)
+}
+
+// uninstallService stops the service if it runs and removes it
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+	// A service that doesn't run can't be stopped, it is removed all the same
+	_, _ = s.Control(svc.Stop)
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	fmt.Printf("Uninstalled service %s\n", serviceName)
+	return nil
+}
+
+// runService runs the exporter under the service control manager until it is stopped
+func runService(demo bool) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+	if !isService {
+		return fmt.Errorf("the run command is meant for the service control manager, run the exporter without it in a console")
+	}
+	return svc.Run(serviceName, &service{demo: demo})
+}
+
+type service struct {
+	demo bool
+}
+
+// Execute runs the exporter until the service control manager asks it to stop or the system shuts down
+func (s *service) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx, s.demo)
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case <-done:
+			return false, 1
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				status <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending, WaitHint: uint32((shutdownTimeout + time.Second).Milliseconds())}
+				cancel()
+				<-done
+				return false, 0
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/exchange"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	errMaintenance = errors.New("binance API is currently under maintenance")
+
+	apiUp = prometheus.NewGaugeVec("binance_api_up", "1 once the binance API was reachable and online, 0 while a serve-degraded exporter waits for it")
+)
+
+func init() {
+	prometheus.Default.MustRegister(apiUp)
+	apiUp.Set(0)
+}
+
+// checkOnline fails unless the exchange is reachable and not under maintenance
+func checkOnline(ctx context.Context, provider exchange.Provider) error {
+	status, err := provider.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if status != exchange.Online {
+		return errMaintenance
+	}
+	apiUp.Set(1)
+	return nil
+}
+
+// waitOnline checks the exchange every interval of clk until it is online, false if ctx is done first
+func waitOnline(ctx context.Context, provider exchange.Provider, clk clock.Clock, interval time.Duration, l *zap.Logger) bool {
+	ticker := clk.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := checkOnline(ctx, provider)
+		if err == nil {
+			l.Info("Binance API is online, starting to collect")
+			return true
+		}
+		l.Warn("Binance API is not available, serving degraded until it is", zap.Duration("retry_interval", interval), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C():
+		}
+	}
+}
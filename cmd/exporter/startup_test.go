@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/exchange"
+	"go.uber.org/zap"
+)
+
+// maintenance is an exchange under maintenance for its first checks
+type maintenance struct {
+	checks  atomic.Int32
+	offline int32
+}
+
+func (m *maintenance) Name() string {
+	return "test"
+}
+
+func (m *maintenance) Status(context.Context) (exchange.Status, error) {
+	if m.checks.Add(1) <= m.offline {
+		return exchange.Maintenance, nil
+	}
+	return exchange.Online, nil
+}
+
+func (m *maintenance) Balances(context.Context) ([]balance.Balance, error) {
+	return nil, nil
+}
+
+func (m *maintenance) Price(context.Context, string, string) (float64, error) {
+	return 0, nil
+}
+
+// waitFor polls cond, the checks run on another goroutine
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWaitOnlineRetriesEveryInterval(t *testing.T) {
+	provider := &maintenance{offline: 2}
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	interval := 30 * time.Second
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- waitOnline(context.Background(), provider, fake, interval, zap.NewNop())
+	}()
+	waitFor(t, "the first check", func() bool { return provider.checks.Load() == 1 && fake.Waiters() == 1 })
+
+	fake.Advance(interval - time.Second)
+	time.Sleep(20 * time.Millisecond)
+	if n := provider.checks.Load(); n != 1 {
+		t.Fatalf("checked %d times before the interval passed, want 1", n)
+	}
+	fake.Advance(time.Second)
+	waitFor(t, "the second check", func() bool { return provider.checks.Load() == 2 })
+	fake.Advance(interval)
+
+	select {
+	case online := <-done:
+		if !online {
+			t.Error("waitOnline gave up on an exchange that came online")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitOnline didn't return once the exchange was online")
+	}
+	if n := provider.checks.Load(); n != 3 {
+		t.Errorf("checked %d times, want 3", n)
+	}
+}
+
+func TestWaitOnlineStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if waitOnline(ctx, &maintenance{offline: 1}, clock.NewFake(time.Now()), time.Minute, zap.NewNop()) {
+		t.Error("waitOnline reported online for an exchange under maintenance")
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/exchange"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/mockserver"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/server"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/store"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+/*
+Runs a full collection against the mock server, scrapes /metrics and compares the exposition against the golden file,
+exiting with 1 on any difference. Values change with every run, so only the HELP and TYPE lines and the series names
+and labels are compared. -update rewrites the golden file after an intended change of the metrics.
+*/
+func main() {
+	fixtures := flag.String("fixtures", "internal/mockserver/testdata", "Directory holding the recorded responses")
+	golden := flag.String("golden", "cmd/integration/testdata/metrics.golden", "Golden exposition of /metrics")
+	update := flag.Bool("update", false, "Rewrite the golden file instead of comparing against it")
+	flag.Parse()
+
+	scraped, err := scrape(*fixtures)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to scrape the exporter: %s\n", err)
+		os.Exit(1)
+	}
+	if *update {
+		if err := os.WriteFile(*golden, []byte(strings.Join(scraped, "\n")+"\n"), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write the golden file: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %d lines to %s\n", len(scraped), *golden)
+		return
+	}
+
+	raw, err := os.ReadFile(*golden)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read the golden file, -update creates it: %s\n", err)
+		os.Exit(1)
+	}
+	missing, unexpected := diff(strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n"), scraped)
+	for _, line := range missing {
+		fmt.Println("- " + line)
+	}
+	for _, line := range unexpected {
+		fmt.Println("+ " + line)
+	}
+	if len(missing) > 0 || len(unexpected) > 0 {
+		fmt.Printf("/metrics differs from %s by %d missing and %d unexpected lines, run with -update if that is intended\n", *golden, len(missing), len(unexpected))
+		os.Exit(1)
+	}
+	fmt.Printf("/metrics matches %s\n", *golden)
+}
+
+// scrape collects once from the mock server with the default configuration and returns the normalized exposition
+func scrape(fixtures string) ([]string, error) {
+	// Only the defaults, so the configuration of the shell running the check doesn't change the output
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, "EXPORTER_") || strings.HasPrefix(name, "B_") {
+			_ = os.Unsetenv(name)
+		}
+	}
+	logger := zap.NewNop()
+	mock := mockserver.Start(fixtures, logger)
+	defer mock.Close()
+	for _, name := range []string{"B_API_URL", "B_FUTURES_API_URL", "B_PORTFOLIO_API_URL", "B_OPTIONS_API_URL"} {
+		_ = os.Setenv(name, mock.URL)
+	}
+	_ = os.Setenv("B_PUBLIC_KEY", "integrationpublickey")
+	_ = os.Setenv("B_PRIVATE_KEY", "integrationprivatekey")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	bc := binance.NewBinanceClient(cfg.UserAgent, logger)
+	store.Default = store.New()
+	col := collector.New(exchange.NewBinance(bc), bc, cfg, logger)
+	col.Collect(context.Background())
+
+	e := echo.New()
+	e.GET("/metrics", server.MetricsHandler(cfg, col, func() bool { return false }))
+	exporter := httptest.NewServer(e)
+	defer exporter.Close()
+	res, err := http.Get(exporter.URL + "/metrics")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("/metrics answered %s", res.Status)
+	}
+	return normalize(res.Body)
+}
+
+// normalize drops the values of the samples, a sample line ends with its value after the last space
+func normalize(r io.Reader) ([]string, error) {
+	res := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			if i := strings.LastIndexByte(line, ' '); i > 0 {
+				line = line[:i]
+			}
+		}
+		res = append(res, line)
+	}
+	return res, scanner.Err()
+}
+
+// diff returns the lines of want missing from got and the lines of got that are not in want
+func diff(want, got []string) (missing, unexpected []string) {
+	count := make(map[string]int, len(want))
+	for _, line := range want {
+		count[line]++
+	}
+	for _, line := range got {
+		if count[line] > 0 {
+			count[line]--
+		} else {
+			unexpected = append(unexpected, line)
+		}
+	}
+	for _, line := range want {
+		if count[line] > 0 {
+			count[line]--
+			missing = append(missing, line)
+		}
+	}
+	return missing, unexpected
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/mockserver"
+	"go.uber.org/zap"
+)
+
+// Serves recorded binance responses so the exporter can run without real keys, point it here with B_API_URL
+func main() {
+	addr := flag.String("addr", ":8090", "Address to listen on")
+	dir := flag.String("fixtures", "internal/mockserver/testdata", "Directory holding the recorded responses")
+	flag.Parse()
+
+	logger, _ := zap.NewDevelopment()
+	defer logger.Sync()
+
+	logger.Info("Serving binance fixtures", zap.String("addr", *addr), zap.String("fixtures", *dir))
+	if err := http.ListenAndServe(*addr, mockserver.New(*dir, logger)); err != nil {
+		logger.Fatal("Mock server stopped", zap.Error(err))
+	}
+}
@@ -0,0 +1,280 @@
+package alerting
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+type (
+	// Options describe the exporter the rules are generated for
+	Options struct {
+		Intervals      map[string]time.Duration // Poll interval of every enabled collector
+		Wallets        []string                 // Enabled wallet collectors
+		Naming         prometheus.Naming
+		StateLabel     bool    // Balances are exported as binance_asset_balance{wallet,asset,state}
+		MarginLevel    float64 // Margin level below which margin accounts alert
+		StaleIntervals int     // Poll intervals without a successful run before a collector is stale
+		Failures       int     // Failed runs of a collector in a row before it alerts
+		Depeg          float64 // Deviation of a held stablecoin from 1.00 above which it alerts
+		MaxLeverage    int     // Leverage configured for a futures symbol above which it alerts
+	}
+
+	// Rule is a prometheus alerting rule
+	Rule struct {
+		Alert       string
+		Expr        string
+		For         time.Duration
+		Labels      map[string]string
+		Annotations map[string]string
+	}
+)
+
+// group is the name of the rule group all generated rules are in
+const group = "binance_exporter"
+
+/*
+Build generates the alerting rules for the enabled collectors: a stale data rule per collector that fires after
+StaleIntervals of its poll intervals without a successful run, a rule for collectors failing Failures runs in a row, an
+API down rule once no collector succeeds anymore, a margin level rule, a futures leverage rule, a stablecoin depeg rule,
+a rule for announced maintenance, rules for changes to the withdrawal whitelist and for new withdrawal addresses, a rule
+for events of the security rules and a rule per wallet with a pending withdrawal.
+*/
+func Build(o Options) []Rule {
+	rules := make([]Rule, 0)
+	names := make([]string, 0, len(o.Intervals))
+	for name := range o.Intervals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	shortest := time.Duration(0)
+	for _, name := range names {
+		interval := o.Intervals[name]
+		if shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+		rules = append(rules, Rule{
+			Alert: "BinanceCollectorStale",
+			Expr:  fmt.Sprintf(`time() - binance_collector_last_success_timestamp_seconds{collector=%q} > %s`, name, seconds(o.stale(interval))),
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     fmt.Sprintf("The %s collector has not succeeded for %d poll intervals", name, o.StaleIntervals),
+				"description": "Its metrics show data from {{ $value | humanizeDuration }} ago.",
+			},
+		})
+	}
+	if shortest > 0 {
+		rules = append(rules, Rule{
+			Alert: "BinanceCollectorFailing",
+			Expr:  "binance_collector_consecutive_failures >= " + strconv.Itoa(o.Failures),
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "The {{ $labels.collector }} collector failed {{ $value }} runs in a row",
+				"description": "Unlike a single failed run this is not a blip, the debug log has the errors.",
+			},
+		})
+		rules = append(rules, Rule{
+			Alert: "BinanceAPIDown",
+			Expr:  fmt.Sprintf("time() - max(binance_collector_last_success_timestamp_seconds) > %s", seconds(o.stale(shortest))),
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "No collector reached the binance API successfully",
+				"description": "The last successful collection was {{ $value | humanizeDuration }} ago, the API error counter has the error codes.",
+			},
+		})
+	}
+
+	_, cross := o.Intervals["margin"]
+	_, isolated := o.Intervals["isolated_margin"]
+	if cross || isolated {
+		rules = append(rules, Rule{
+			Alert: "BinanceMarginLevelLow",
+			Expr:  "binance_margin_level < " + strconv.FormatFloat(o.MarginLevel, 'f', -1, 64),
+			For:   5 * time.Minute,
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "Margin level of the {{ $labels.account }} margin account {{ $labels.symbol }} is {{ $value }}",
+				"description": "Binance liquidates at a margin level of 1.1, repay debt or add collateral.",
+			},
+		})
+	}
+
+	if _, ok := o.Intervals["futures"]; ok {
+		rules = append(rules, Rule{
+			Alert: "BinanceFuturesLeverageHigh",
+			Expr:  "binance_futures_symbol_leverage > " + strconv.Itoa(o.MaxLeverage),
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "{{ $labels.symbol }} futures are configured for {{ $value }}x leverage",
+				"description": "New positions of the symbol open at this leverage, lower it unless it was intended.",
+			},
+		})
+	}
+
+	if _, ok := o.Intervals["stablecoin_peg"]; ok {
+		rules = append(rules, Rule{
+			Alert: "BinanceStablecoinDepeg",
+			Expr:  "abs(binance_stablecoin_peg_deviation) > " + strconv.FormatFloat(o.Depeg, 'f', -1, 64),
+			For:   5 * time.Minute,
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "The held stablecoin {{ $labels.asset }} is {{ $value }} off its peg",
+				"description": "Its market price deviates from 1.00 by more than the threshold, check whether to move out of it.",
+			},
+		})
+	}
+
+	if _, ok := o.Intervals["maintenance"]; ok {
+		rules = append(rules, Rule{
+			Alert: "BinanceMaintenanceUpcoming",
+			Expr:  "binance_maintenance_next_timestamp_seconds - time() < 3600",
+			Labels: map[string]string{
+				"severity": "info",
+			},
+			Annotations: map[string]string{
+				"summary":     "Binance starts a scheduled system maintenance in {{ $value | humanizeDuration }}",
+				"description": "Silence the alerts of the affected collectors and pause bots for the maintenance window.",
+			},
+		})
+	}
+
+	if _, ok := o.Intervals["withdraw_addresses"]; ok {
+		rules = append(rules, Rule{
+			Alert: "BinanceWithdrawWhitelistChanged",
+			Expr:  "changes(binance_withdraw_whitelist_addresses[1h]) > 0",
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "The withdrawal whitelist of the account changed",
+				"description": "{{ $value }} changes to the whitelisted withdrawal addresses in the last hour, check that they were intended.",
+			},
+		})
+		// A coin or network without addresses an hour ago has no series to compare against, unless catches those
+		rules = append(rules, Rule{
+			Alert: "BinanceWithdrawAddressAdded",
+			Expr: "sum without (whitelisted) (binance_withdraw_addresses) > sum without (whitelisted) (binance_withdraw_addresses offset 1h)" +
+				" or (sum without (whitelisted) (binance_withdraw_addresses) unless sum without (whitelisted) (binance_withdraw_addresses offset 1h))",
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "A withdrawal address for {{ $labels.coin }} on {{ $labels.network }} was added",
+				"description": "The withdrawal address book has {{ $value }} addresses for {{ $labels.coin }} on {{ $labels.network }}, more than an hour ago, check that they were added on purpose.",
+			},
+		})
+	}
+
+	if _, ok := o.Intervals["security"]; ok {
+		rules = append(rules, Rule{
+			Alert: "BinanceSecurityEvent",
+			Expr:  "increase(binance_security_events_total[1h]) > 0",
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "The {{ $labels.rule }} security rule flagged the account",
+				"description": "{{ $value }} events of the {{ $labels.rule }} rule in the last hour, /api/v1/events lists what happened.",
+			},
+		})
+	}
+
+	for _, wallet := range o.Wallets {
+		expr := fmt.Sprintf("binance_%s_asset_withdrawing > 0", wallet)
+		if o.StateLabel {
+			expr = fmt.Sprintf(`binance_asset_balance{wallet=%q,state="withdrawing"} > 0`, wallet)
+		}
+		rules = append(rules, Rule{
+			Alert: "BinanceWithdrawalDetected",
+			Expr:  expr,
+			Labels: map[string]string{
+				"severity": "warning",
+				"wallet":   wallet,
+			},
+			Annotations: map[string]string{
+				"summary":     "{{ $value }} {{ $labels.asset }} is being withdrawn from the " + wallet + " wallet",
+				"description": "Check that the withdrawal was intended.",
+			},
+		})
+	}
+
+	for i := range rules {
+		rules[i].Expr = o.Naming.Query(rules[i].Expr)
+	}
+	return rules
+}
+
+// stale returns the time without a successful run after which a collector polled every interval is stale
+func (o Options) stale(interval time.Duration) time.Duration {
+	return time.Duration(o.StaleIntervals) * interval
+}
+
+/*
+Write renders the rules as a prometheus rule file. The format is simple enough to not need a YAML library, every
+string is written double quoted, which YAML reads with the same escapes as Go.
+*/
+func Write(w io.Writer, rules []Rule) error {
+	b := &errWriter{w: w}
+	b.printf("groups:\n")
+	b.printf("  - name: %s\n", group)
+	b.printf("    rules:\n")
+	for _, r := range rules {
+		b.printf("      - alert: %s\n", r.Alert)
+		b.printf("        expr: %q\n", r.Expr)
+		if r.For > 0 {
+			b.printf("        for: %s\n", seconds(r.For)+"s")
+		}
+		for _, m := range []struct {
+			name   string
+			values map[string]string
+		}{{"labels", r.Labels}, {"annotations", r.Annotations}} {
+			if len(m.values) == 0 {
+				continue
+			}
+			b.printf("        %s:\n", m.name)
+			keys := make([]string, 0, len(m.values))
+			for k := range m.values {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				b.printf("          %s: %q\n", k, m.values[k])
+			}
+		}
+	}
+	return b.err
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
+
+// errWriter keeps the first error of a sequence of writes
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
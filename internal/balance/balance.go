@@ -0,0 +1,97 @@
+/*
+Package balance is the model of the holdings of an account the collectors and sinks share. Amounts keep the decimal
+strings binance sends, so a JSON or CSV sink renders them exactly as binance reported them, and parsing them into
+numbers happens here instead of in every consumer.
+*/
+package balance
+
+import (
+	"strconv"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+type (
+	// Amount is a decimal amount as the exchange formats it, empty if the exchange left it out
+	Amount string
+
+	// Wallet is the name of a wallet of the account, it is the wallet label of every asset metric
+	Wallet string
+
+	// Balance of one asset in one wallet
+	Balance struct {
+		Wallet      Wallet `json:"wallet"`
+		Asset       string `json:"asset"` // Asset code like BTC
+		Free        Amount `json:"free"`
+		Locked      Amount `json:"locked"` // Locked in open orders
+		Freeze      Amount `json:"freeze"`
+		Withdrawing Amount `json:"withdrawing"`
+		Ipoable     Amount `json:"ipoable"`
+		ValueBTC    Amount `json:"btcValuation"` // Empty if the exchange doesn't value the asset
+	}
+)
+
+const (
+	Spot            Wallet = "spot"
+	Funding         Wallet = "funding"
+	CrossMargin     Wallet = "cross_margin"
+	IsolatedMargin  Wallet = "isolated_margin"
+	FuturesUSDM     Wallet = "futures_usdm" // USDⓈ-M futures
+	FuturesCOINM    Wallet = "futures_coinm"
+	EarnFlexible    Wallet = "earn_flexible"
+	EarnLocked      Wallet = "earn_locked"
+	Options         Wallet = "options"
+	PortfolioMargin Wallet = "portfolio_margin" // Sum of the wallets of a Portfolio Margin account
+)
+
+// Wallets are all wallets an asset metric can be labelled with
+var Wallets = []Wallet{Spot, Funding, CrossMargin, IsolatedMargin, FuturesUSDM, FuturesCOINM, EarnFlexible, EarnLocked, Options, PortfolioMargin}
+
+// Label returns the wallet label of a sample of the wallet
+func (w Wallet) Label() prometheus.Label {
+	return prometheus.L("wallet", string(w))
+}
+
+// Parse returns the amount as a number, false if it is empty or not a number
+func (a Amount) Parse() (float64, bool) {
+	v, err := strconv.ParseFloat(string(a), 64)
+	return v, err == nil
+}
+
+// Float returns the amount as a number, 0 if it is empty or not a number
+func (a Amount) Float() float64 {
+	v, _ := a.Parse()
+	return v
+}
+
+func (a Amount) String() string {
+	return string(a)
+}
+
+// Sum returns the sum of the amounts, the ones that are no numbers count as 0
+func Sum(amounts ...Amount) float64 {
+	sum := 0.0
+	for _, a := range amounts {
+		sum += a.Float()
+	}
+	return sum
+}
+
+// FromBinance maps the assets of a binance wallet endpoint to balances of the wallet
+func FromBinance(wallet Wallet, assets []binance.Asset) []Balance {
+	res := make([]Balance, 0, len(assets))
+	for _, a := range assets {
+		res = append(res, Balance{
+			Wallet:      wallet,
+			Asset:       a.Asset,
+			Free:        Amount(a.Free),
+			Locked:      Amount(a.Locked),
+			Freeze:      Amount(a.Freeze),
+			Withdrawing: Amount(a.Withdrawing),
+			Ipoable:     Amount(a.Ipoable),
+			ValueBTC:    Amount(a.BtcValuation),
+		})
+	}
+	return res
+}
@@ -0,0 +1,75 @@
+package binance
+
+import (
+	"context"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+type (
+	// AccountStatus is "Normal" unless the account is restricted
+	AccountStatus struct {
+		Data string `json:"data"`
+	}
+
+	// APITradingStatus tells whether the key is banned from trading through the API and which indicators triggered it
+	APITradingStatus struct {
+		Data struct {
+			IsLocked           bool                          `json:"isLocked"`
+			PlannedRecoverTime int64                         `json:"plannedRecoverTime"` // Unix milliseconds, 0 while not locked
+			TriggerCondition   map[string]float64            `json:"triggerCondition"`
+			Indicators         map[string][]TradingIndicator `json:"indicators"` // By symbol
+			UpdateTime         int64                         `json:"updateTime"`
+		} `json:"data"`
+	}
+	// TradingIndicator is one of the order rate rules binance bans API trading on, e.g. UFR (unfilled ratio)
+	TradingIndicator struct {
+		Indicator string  `json:"i"`
+		Count     int     `json:"c"` // Orders the indicator was computed over
+		Value     float64 `json:"v"`
+		Trigger   float64 `json:"t"` // Value at which trading gets banned
+	}
+
+	// APIRestrictions are the permissions of the API key, 2FA and the anti-phishing code are not exposed to API keys
+	APIRestrictions struct {
+		IPRestrict                     bool  `json:"ipRestrict"`
+		CreateTime                     int64 `json:"createTime"` // Unix milliseconds
+		EnableReading                  bool  `json:"enableReading"`
+		EnableWithdrawals              bool  `json:"enableWithdrawals"`
+		EnableInternalTransfer         bool  `json:"enableInternalTransfer"`
+		PermitsUniversalTransfer       bool  `json:"permitsUniversalTransfer"`
+		EnableMargin                   bool  `json:"enableMargin"`
+		EnableFutures                  bool  `json:"enableFutures"`
+		EnableVanillaOptions           bool  `json:"enableVanillaOptions"`
+		EnableSpotAndMarginTrading     bool  `json:"enableSpotAndMarginTrading"`
+		EnablePortfolioMarginTrading   bool  `json:"enablePortfolioMarginTrading"`
+		TradingAuthorityExpirationTime int64 `json:"tradingAuthorityExpirationTime"` // Unix milliseconds, 0 without trading permission
+	}
+)
+
+func (c *Client) GetAccountStatus(ctx context.Context) (AccountStatus, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetAccountStatus")
+	defer span.End()
+
+	status := AccountStatus{}
+	err := c.getSigned(ctx, "sapi/v1/account/status", nil, &status)
+	return status, err
+}
+
+func (c *Client) GetAPITradingStatus(ctx context.Context) (APITradingStatus, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetAPITradingStatus")
+	defer span.End()
+
+	status := APITradingStatus{}
+	err := c.getSigned(ctx, "sapi/v1/account/apiTradingStatus", nil, &status)
+	return status, err
+}
+
+func (c *Client) GetAPIRestrictions(ctx context.Context) (APIRestrictions, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetAPIRestrictions")
+	defer span.End()
+
+	restrictions := APIRestrictions{}
+	err := c.getSigned(ctx, "sapi/v1/account/apiRestrictions", nil, &restrictions)
+	return restrictions, err
+}
@@ -20,38 +20,91 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Entrio/subenv"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/capture"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/mockserver"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/reporting"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
+var _ BinanceAPI = (*Client)(nil)
+
+const (
+	maxAttempts  = 3                      // Attempts per request for retryable errors
+	retryBackoff = 500 * time.Millisecond // Multiplied by the attempt number
+)
+
+const (
+	futuresEndpoint   = "https://fapi.binance.com"
+	portfolioEndpoint = "https://papi.binance.com"
+	optionsEndpoint   = "https://eapi.binance.com"
+
+	spotStreamEndpoint    = "wss://stream.binance.com:9443"
+	futuresStreamEndpoint = "wss://fstream.binance.com"
+)
+
 var endpoints = [...]string{"https://api.binance.com", "https://api-gcp.binance.com", "https://api1.binance.com", "https://api2.binance.com", "https://api3.binance.com", "https://api4.binance.com"}
 
 type (
 	Client struct {
-		httpclient http.Client
-		logger     *zap.Logger
-		security   security
-		funding    Data
-		spot       Data
+		httpclient   http.Client
+		baseURL      string
+		futuresURL   string // USDⓈ-M futures live on their own host
+		portfolioURL string // So does Portfolio Margin
+		optionsURL   string // And options
+		spotWS       string // Market streams of spot symbols
+		futuresWS    string // And of USDⓈ-M futures
+		userAgent    string
+		logger       *zap.Logger
+		clock        clock.Clock // Times the retry backoff, requests are signed with the wall clock binance checks
+		security     security
+		funding      *Data
+		spot         *Data
+		weight       atomic.Int64 // Used weight of the last spot response, -1 until there was one
 	}
 	security struct {
 		PublicKey  string `json:"-"`
 		PrivateKey string `json:"-"`
 	}
+	/*
+		Data holds the last collection of a wallet. Every collection swaps in a new snapshot as a whole, so readers never
+		wait for the poller and never see a half updated wallet.
+	*/
 	Data struct {
-		Assets []Asset
-		lock   sync.RWMutex
+		snapshot atomic.Pointer[walletSnapshot]
+		name     string     // Collector name used in logs and metrics
+		lock     sync.Mutex // Guards disabled
+		disabled string     // Reason the collector was disabled, empty while it is active
+		failures atomic.Int64
+	}
+	// walletSnapshot is never modified once it is stored, so its assets can be handed out without copying them
+	walletSnapshot struct {
+		assets []Asset
 	}
 )
 
-func NewBinanceClient(l *zap.Logger) *Client {
+func newData(name string) *Data {
+	d := &Data{name: name}
+	d.snapshot.Store(&walletSnapshot{assets: make([]Asset, 0)})
+	return d
+}
+
+func NewBinanceClient(userAgent string, l *zap.Logger) *Client {
 	// Fetch private and public keys from the environment
 	privKey := subenv.Env("B_PRIVATE_KEY", "")
 	pubkey := subenv.Env("B_PUBLIC_KEY", "")
@@ -66,40 +119,195 @@ func NewBinanceClient(l *zap.Logger) *Client {
 		os.Exit(1)
 	}
 
-	return &Client{
-		httpclient: http.Client{},
-		logger:     l,
+	// Allow pointing the client at a mock server and recording the responses it gets
+	baseURL := strings.TrimSuffix(subenv.Env("B_API_URL", endpoints[1]), "/")
+	futuresURL := strings.TrimSuffix(subenv.Env("B_FUTURES_API_URL", futuresEndpoint), "/")
+	portfolioURL := strings.TrimSuffix(subenv.Env("B_PORTFOLIO_API_URL", portfolioEndpoint), "/")
+	optionsURL := strings.TrimSuffix(subenv.Env("B_OPTIONS_API_URL", optionsEndpoint), "/")
+	spotWS := strings.TrimSuffix(subenv.Env("B_STREAM_URL", spotStreamEndpoint), "/")
+	futuresWS := strings.TrimSuffix(subenv.Env("B_FUTURES_STREAM_URL", futuresStreamEndpoint), "/")
+	transport := http.DefaultTransport
+	if dir := subenv.Env("B_RECORD_DIR", ""); len(dir) > 0 {
+		l.Info("Recording binance responses", zap.String("dir", dir))
+		transport = mockserver.NewRecorder(dir, transport, l)
+	}
+	if capture.Default != nil {
+		transport = capture.Default.Wrap(transport)
+	}
+	httpclient := http.Client{Transport: tracing.Transport(transport)}
+
+	c := &Client{
+		httpclient:   httpclient,
+		baseURL:      baseURL,
+		futuresURL:   futuresURL,
+		portfolioURL: portfolioURL,
+		optionsURL:   optionsURL,
+		spotWS:       spotWS,
+		futuresWS:    futuresWS,
+		userAgent:    userAgent,
+		logger:       l,
+		clock:        clock.Real,
 		security: security{
 			PublicKey:  pubkey,
 			PrivateKey: privKey,
 		},
-		funding: Data{
-			Assets: make([]Asset, 0),
-		},
-		spot: Data{
-			Assets: make([]Asset, 0),
-		},
+		funding: newData("funding"),
+		spot:    newData("spot"),
 	}
+	c.weight.Store(-1)
+	return c
 }
 
+// UseClock replaces the wall clock the retry backoff waits on
+func (c *Client) UseClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// GetSpotAssets returns the assets of the last spot collection, the slice is shared and must not be modified
 func (c *Client) GetSpotAssets() []Asset {
-	// Make a copy of an asset array
-	c.spot.lock.RLock()
-	defer c.spot.lock.RUnlock()
-	var res []Asset
-	res = append(res, c.spot.Assets...)
-	return res
+	return c.spot.assets()
 }
 
+// GetFundingAssets returns the assets of the last funding collection, the slice is shared and must not be modified
 func (c *Client) GetFundingAssets() []Asset {
-	// Make a copy of an asset array
-	c.funding.lock.RLock()
-	defer c.funding.lock.RUnlock()
-	var res []Asset
-	res = append(res, c.funding.Assets...)
+	return c.funding.assets()
+}
+
+func (d *Data) assets() []Asset {
+	return d.snapshot.Load().assets
+}
+
+// size is the number of assets of the last collection, the next one most likely holds as many
+func (d *Data) size() int {
+	return len(d.assets())
+}
+
+// store swaps in the assets of a successful collection and ends the failure streak
+func (d *Data) store(assets []Asset) {
+	d.snapshot.Store(&walletSnapshot{assets: assets})
+	d.failures.Store(0)
+}
+
+/*
+decodeAssets streams the asset array of a wallet response element by element, so accounts holding hundreds of assets
+don't buffer the whole response before decoding it. The slice is allocated for sizeHint assets up front.
+*/
+func decodeAssets(r io.Reader, sizeHint int) ([]Asset, error) {
+	dec := json.NewDecoder(r)
+	if t, err := dec.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('[') {
+		return nil, fmt.Errorf("expected an array of assets, got %v", t)
+	}
+	assets := make([]Asset, 0, sizeHint)
+	for dec.More() {
+		assets = append(assets, Asset{})
+		if err := dec.Decode(&assets[len(assets)-1]); err != nil {
+			return nil, err
+		}
+	}
+	_, err := dec.Token()
+	return assets, err
+}
+
+/*
+DisabledCollectors returns the collectors that were switched off because the API key is not allowed to use them,
+mapped to the reason.
+*/
+func (c *Client) DisabledCollectors() map[string]string {
+	res := make(map[string]string)
+	for _, d := range []*Data{c.spot, c.funding} {
+		d.lock.Lock()
+		if len(d.disabled) > 0 {
+			res[d.name] = d.disabled
+		}
+		d.lock.Unlock()
+	}
 	return res
 }
 
+func (d *Data) isDisabled() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return len(d.disabled) > 0
+}
+
+/*
+disable switches the collector off if the error means the key will never be allowed to call its endpoint.
+This way a missing permission is reported once instead of on every poll cycle.
+*/
+func (c *Client) disable(d *Data, apiErr *APIError) bool {
+	reason := apiErr.DisableReason()
+	if len(reason) == 0 {
+		return false
+	}
+	d.lock.Lock()
+	d.disabled = reason
+	d.lock.Unlock()
+	c.logger.Warn("API key is not permitted to use this collector, disabling it.", zap.String("collector", d.name), zap.String("reason", reason), zap.Error(apiErr))
+	return true
+}
+
+/*
+collectionFailed counts the failure streak of the collector, which gets reported once it reaches the threshold.
+Signature errors are reported right away, they mean a broken secret or clock rather than a flaky network.
+*/
+func (c *Client) collectionFailed(d *Data, endpoint string, status int, err error) {
+	failures := int(d.failures.Add(1))
+
+	tags := map[string]string{"endpoint": endpoint}
+	if status > 0 {
+		tags["status_code"] = strconv.Itoa(status)
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		tags["binance_code"] = strconv.Itoa(apiErr.Code)
+		if apiErr.Code == ErrCodeInvalidSignature {
+			tags["collector"] = d.name
+			reporting.CaptureError(err, tags)
+			return
+		}
+	}
+	reporting.CollectionFailed(d.name, failures, err, tags)
+}
+
+/*
+decodeAPIError reads the binance error body of a failed response and counts it. Responses without a body get the
+http status as message.
+*/
+func decodeAPIError(res *http.Response) *APIError {
+	apiErr := &APIError{Status: res.StatusCode}
+	if err := json.NewDecoder(res.Body).Decode(apiErr); err != nil || len(apiErr.Message) == 0 {
+		apiErr.Message = res.Status
+	}
+	apiErrors.Inc(apiErr.codeLabel())
+	return apiErr
+}
+
+/*
+errorFields returns the log fields for err, binance errors get their code and remediation hint attached
+*/
+func errorFields(err error) []zap.Field {
+	fields := []zap.Field{zap.Error(err)}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		fields = append(fields, zap.Int("status_code", apiErr.Status), zap.Int("binance_code", apiErr.Code))
+		if r := apiErr.Remediation(); len(r) > 0 {
+			fields = append(fields, zap.String("remediation", r))
+		}
+	}
+	return fields
+}
+
+// statusOf returns the http status code carried by err, 0 if the request never got a response
+func statusOf(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status
+	}
+	return 0
+}
+
 /*
 *
 generateSignature uses Client's private key to generate a sha256 hash of provided string.
@@ -135,125 +343,232 @@ func (c *Client) signrequest(uri string, signed bool) string {
 
 	signature := c.security.generateSignature(newUri)
 	signedUri := fmt.Sprintf("%s?%s&signature=%s", root, newUri, signature)
-	c.logger.Debug("Generated HMAC sha1 signature for url", zap.String("sha256", signature), zap.String("uri", newUri))
+	c.logger.Debug("Generated HMAC sha1 signature for url", zap.String("signature", signature), zap.String("uri", newUri))
 	return signedUri
 }
 
-func (c *Client) GetSystemStatus() (SystemStatus, error) {
-	c.logger.Debug("GetSystemStatus()")
-	req, cancel, err := c.buildGetRequest("sapi/v1/system/status")
-	c.logger.Debug("Making status request", zap.String("URL", fmt.Sprintf("%s%s", req.Host, req.URL.Path)))
-	if err != nil {
-		return Maintenance, err
-	}
-	defer cancel()
+func (c *Client) GetSystemStatus(ctx context.Context) (SystemStatus, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetSystemStatus")
+	defer span.End()
+	log := tracing.Logger(ctx, c.logger)
 
-	res, err := c.httpclient.Do(req)
+	log.Debug("GetSystemStatus()")
+	res, cancel, err := c.do(ctx, func() (*http.Request, func(), error) {
+		return c.buildGetRequest(ctx, "sapi/v1/system/status")
+	})
 	if err != nil {
-		c.logger.Error("Failed to make request.", zap.Error(err))
+		log.Error("Failed to make request.", errorFields(err)...)
 		return Maintenance, err
 	}
+	defer cancel()
 	defer func() {
 		_ = res.Body.Close() // Hate those unhandled errors warning
 	}()
-	c.logger.Debug("Got server status response", zap.Int("status_code", res.StatusCode))
 	status := &APIStatus{}
 	err = json.NewDecoder(res.Body).Decode(status)
 	if err != nil {
-		c.logger.Error("Failed to decode body.", zap.Error(err))
+		log.Error("Failed to decode body.", zap.Error(err))
 		return Maintenance, err
 	}
-	c.logger.Info("System status", zap.String("status", fmt.Sprintf("%s", status.Status)))
+	log.Info("System status", zap.String("status", fmt.Sprintf("%s", status.Status)))
 	return status.Status, nil
 }
 
-func (c *Client) GetFundingWallet() {
-	c.logger.Debug("GetFundingWallet()")
-	req, cancel, err := c.buildPostRequest("sapi/v1/asset/get-funding-asset")
-	c.logger.Debug("Making funding wallet data request", zap.String("URL", req.URL.String()))
+// GetFundingWallet fetches the funding wallet, its assets are returned by GetFundingAssets afterwards
+func (c *Client) GetFundingWallet(ctx context.Context) error {
+	return c.fetchWallet(ctx, "sapi/v1/asset/get-funding-asset", c.funding)
+}
+
+// GetSpotWallet fetches the spot wallet, its assets are returned by GetSpotAssets afterwards
+func (c *Client) GetSpotWallet(ctx context.Context) error {
+	return c.fetchWallet(ctx, "sapi/v3/asset/getUserAsset", c.spot)
+}
+
+/*
+fetchWallet stores the assets the wallet endpoint returns in target. Failures are logged and counted with the name of
+target, unless they disable it for good.
+*/
+func (c *Client) fetchWallet(ctx context.Context, endpoint string, target *Data) error {
+	if target.isDisabled() {
+		return nil
+	}
+	ctx, span := tracing.Start(ctx, "binance.fetchWallet", attribute.String("wallet", target.name))
+	defer span.End()
+	log := tracing.Logger(ctx, c.logger).With(zap.String("wallet", target.name), zap.String("endpoint", endpoint))
+
+	log.Debug("Fetching wallet")
+	res, cancel, err := c.do(ctx, func() (*http.Request, func(), error) {
+		return c.buildPostRequest(ctx, endpoint+"?needBtcValuation=true")
+	})
 	if err != nil {
-		c.logger.Warn("Failed to form funding wallet request.", zap.Error(err))
-		return
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && c.disable(target, apiErr) {
+			return err
+		}
+		log.Warn("Failed to get wallet data.", errorFields(err)...)
+		c.collectionFailed(target, endpoint, statusOf(err), err)
+		return err
 	}
 	defer cancel()
+	defer res.Body.Close()
 
-	res, err := c.httpclient.Do(req)
+	assets, err := decodeAssets(res.Body, target.size())
 	if err != nil {
-		c.logger.Warn("Failed to get funding wallet data.", zap.Error(err))
-		return
+		log.Error("Failed to decode body.", zap.Error(err))
+		c.collectionFailed(target, endpoint, res.StatusCode, err)
+		return err
 	}
+	target.store(assets)
+	return nil
+}
 
-	defer res.Body.Close()
+/*
+do sends the request built by build and retries transient failures with a linear backoff. The request is rebuilt for
+every attempt so signed requests get a fresh timestamp, which is what fixes ErrInvalidTimestamp after a clock hiccup.
+On success the caller has to close the body and call the returned cancel func. Any non 200 response is returned as
+an *APIError.
+*/
+func (c *Client) do(ctx context.Context, build func() (*http.Request, func(), error)) (*http.Response, func(), error) {
+	var lastErr error
+	log := tracing.Logger(ctx, c.logger)
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		req, cancel, err := build()
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Debug("Making request", zap.String("URL", req.URL.String()), zap.Int("attempt", attempt))
+		if attempt == 1 {
+			recordEndpoint(ctx, strings.TrimPrefix(req.URL.Path, "/"))
+		}
+
+		start := time.Now()
+		res, err := c.httpclient.Do(req)
+		observeDuration(req, start)
+		if err == nil {
+			c.recordWeight(res)
+		}
+		switch {
+		case err != nil:
+			cancel()
+			if ctx.Err() != nil {
+				return nil, nil, err
+			}
+			lastErr = err
+		case res.StatusCode == http.StatusOK:
+			log.Debug("Got server response", zap.Int("status_code", res.StatusCode))
+			return res, cancel, nil
+		default:
+			apiErr := decodeAPIError(res)
+			_ = res.Body.Close()
+			cancel()
+			if !apiErr.Retryable() {
+				return nil, nil, apiErr
+			}
+			lastErr = apiErr
+		}
+
+		if attempt == maxAttempts {
+			break
+		}
+		log.Debug("Retrying request", zap.Int("attempt", attempt), zap.Error(lastErr))
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-c.clock.After(retryBackoff * time.Duration(attempt)):
+		}
+	}
+	return nil, nil, lastErr
+}
 
-	c.logger.Debug("Got server status response", zap.Int("status_code", res.StatusCode))
+// get requests an unsigned endpoint and decodes the response into target
+func (c *Client) get(ctx context.Context, endpoint string, query url.Values, target interface{}) error {
+	uri := withQuery(endpoint, query)
+	return c.fetch(ctx, endpoint, func() (*http.Request, func(), error) {
+		return c.buildGetRequest(ctx, uri)
+	}, target)
+}
 
-	if res.StatusCode != 200 {
-		c.logger.Warn("Got an invalid status code from API, returning")
-		return
-	}
-	var assets []Asset
-	err = json.NewDecoder(res.Body).Decode(&assets)
-	if err != nil {
-		c.logger.Error("Failed to decode body.", zap.Error(err))
-		return
-	}
-	c.funding.lock.Lock()
-	defer c.funding.lock.Unlock()
-	c.funding.Assets = assets
+// getSigned requests a signed USER_DATA endpoint and decodes the response into target
+func (c *Client) getSigned(ctx context.Context, endpoint string, query url.Values, target interface{}) error {
+	uri := withQuery(endpoint, query)
+	return c.fetch(ctx, endpoint, func() (*http.Request, func(), error) {
+		return c.buildSignedGetRequest(ctx, uri)
+	}, target)
 }
 
-func (c *Client) GetUserAssets() {
-	c.logger.Debug("GetFundingWallet()")
-	req, cancel, err := c.buildPostRequest("sapi/v3/asset/getUserAsset")
-	c.logger.Debug("Making funding wallet data request", zap.String("URL", req.URL.String()))
+// fetch sends the request built by build and decodes the response into target, failures are logged with their remediation
+func (c *Client) fetch(ctx context.Context, endpoint string, build func() (*http.Request, func(), error), target interface{}) error {
+	log := tracing.Logger(ctx, c.logger)
+	res, cancel, err := c.do(ctx, build)
 	if err != nil {
-		c.logger.Warn("Failed to form funding wallet request.", zap.Error(err))
-		return
+		log.Warn("Request failed.", append(errorFields(err), zap.String("endpoint", endpoint))...)
+		return err
 	}
 	defer cancel()
-
-	res, err := c.httpclient.Do(req)
-	if err != nil {
-		c.logger.Warn("Failed to get funding wallet data.", zap.Error(err))
-		return
-	}
-
 	defer res.Body.Close()
 
-	c.logger.Debug("Got server status response", zap.Int("status_code", res.StatusCode))
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		log.Error("Failed to decode body.", zap.String("endpoint", endpoint), zap.Error(err))
+		return err
+	}
+	return nil
+}
 
-	if res.StatusCode != 200 {
-		c.logger.Warn("Got an invalid status code from API, returning")
-		return
+func withQuery(endpoint string, query url.Values) string {
+	if len(query) == 0 {
+		return endpoint
 	}
-	var assets []Asset
-	err = json.NewDecoder(res.Body).Decode(&assets)
-	if err != nil {
-		c.logger.Error("Failed to decode body.", zap.Error(err))
-		return
+	return endpoint + "?" + query.Encode()
+}
+
+func (c *Client) buildGetRequest(ctx context.Context, url string) (*http.Request, func(), error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*3)
+	r, e := http.NewRequestWithContext(ctx, http.MethodGet, c.buildURL(url), nil)
+	if e != nil {
+		return nil, cancel, e
 	}
-	c.spot.lock.Lock()
-	defer c.spot.lock.Unlock()
-	c.spot.Assets = assets
+	c.setHeaders(r)
+	return r, cancel, nil
 }
 
-func (c *Client) buildGetRequest(url string) (*http.Request, func(), error) {
-	ctx := context.Background()
+func (c *Client) buildPostRequest(ctx context.Context, url string) (*http.Request, func(), error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*3)
-	r, e := http.NewRequestWithContext(ctx, http.MethodGet, buildURL(url), nil)
-	r.Header.Set("X-MBX-APIKEY", c.security.PublicKey)
-	return r, cancel, e
+	signedUrl := c.signrequest(url, true)
+	r, e := http.NewRequestWithContext(ctx, http.MethodPost, c.buildURL(signedUrl), nil)
+	if e != nil {
+		return nil, cancel, e
+	}
+	c.setHeaders(r)
+	return r, cancel, nil
 }
 
-func (c *Client) buildPostRequest(url string) (*http.Request, func(), error) {
-	ctx := context.Background()
+// buildSignedGetRequest is buildPostRequest for the signed endpoints binance only serves on GET
+func (c *Client) buildSignedGetRequest(ctx context.Context, url string) (*http.Request, func(), error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*3)
 	signedUrl := c.signrequest(url, true)
-	r, e := http.NewRequestWithContext(ctx, http.MethodPost, buildURL(signedUrl), nil)
+	r, e := http.NewRequestWithContext(ctx, http.MethodGet, c.buildURL(signedUrl), nil)
+	if e != nil {
+		return nil, cancel, e
+	}
+	c.setHeaders(r)
+	return r, cancel, nil
+}
+
+func (c *Client) setHeaders(r *http.Request) {
 	r.Header.Set("X-MBX-APIKEY", c.security.PublicKey)
-	return r, cancel, e
+	r.Header.Set("User-Agent", c.userAgent)
 }
 
-func buildURL(url string) string {
-	return fmt.Sprintf("%s/%s", endpoints[1], url)
+// buildURL prefixes the path with the host serving it, futures (fapi/... and futures/data/...), Portfolio Margin
+// (papi/...) and options (eapi/...) have their own
+func (c *Client) buildURL(url string) string {
+	switch {
+	case strings.HasPrefix(url, "fapi/"), strings.HasPrefix(url, "futures/data/"):
+		return fmt.Sprintf("%s/%s", c.futuresURL, url)
+	case strings.HasPrefix(url, "papi/"):
+		return fmt.Sprintf("%s/%s", c.portfolioURL, url)
+	case strings.HasPrefix(url, "eapi/"):
+		return fmt.Sprintf("%s/%s", c.optionsURL, url)
+	}
+	return fmt.Sprintf("%s/%s", c.baseURL, url)
 }
@@ -0,0 +1,196 @@
+package binance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newTestClient points a client at srv and records what it logs
+func newTestClient(t *testing.T, srv *httptest.Server) (*Client, *observer.ObservedLogs) {
+	t.Helper()
+	t.Setenv("B_PRIVATE_KEY", "private")
+	t.Setenv("B_PUBLIC_KEY", "public")
+	t.Setenv("B_API_URL", srv.URL)
+	t.Setenv("B_RECORD_DIR", "")
+	core, logs := observer.New(zapcore.DebugLevel)
+	return NewBinanceClient("test", zap.New(core)), logs
+}
+
+func TestFetchWallet(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		fetch    func(c *Client, ctx context.Context) error
+		target   func(c *Client) *Data
+		other    func(c *Client) *Data
+	}{
+		{
+			name:     "spot",
+			endpoint: "sapi/v3/asset/getUserAsset",
+			fetch:    (*Client).GetSpotWallet,
+			target:   func(c *Client) *Data { return c.spot },
+			other:    func(c *Client) *Data { return c.funding },
+		},
+		{
+			name:     "funding",
+			endpoint: "sapi/v1/asset/get-funding-asset",
+			fetch:    (*Client).GetFundingWallet,
+			target:   func(c *Client) *Data { return c.funding },
+			other:    func(c *Client) *Data { return c.spot },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/"+tt.endpoint {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if r.URL.Query().Get("needBtcValuation") != "true" || len(r.URL.Query().Get("signature")) == 0 {
+					t.Errorf("request is not signed with needBtcValuation: %s", r.URL.RawQuery)
+				}
+				_, _ = w.Write([]byte(`[{"asset":"BTC","free":"1.5","locked":"0","btcValuation":"1.5"},{"asset":"ETH","free":"10","locked":"2","btcValuation":"0.6"}]`))
+			}))
+			defer srv.Close()
+			c, logs := newTestClient(t, srv)
+
+			if err := tt.fetch(c, context.Background()); err != nil {
+				t.Fatalf("fetch failed: %v", err)
+			}
+			assets := tt.target(c).assets()
+			if len(assets) != 2 || assets[0].Asset != "BTC" || assets[1].Asset != "ETH" || assets[1].Locked != "2" {
+				t.Errorf("got %+v stored in %s", assets, tt.name)
+			}
+			if other := tt.other(c); len(other.assets()) != 0 {
+				t.Errorf("%s wallet got %+v", other.name, other.assets())
+			}
+			fetching := logs.FilterMessage("Fetching wallet").All()
+			if len(fetching) != 1 {
+				t.Fatalf("logged %d fetches, want 1", len(fetching))
+			}
+			if fields := fetching[0].ContextMap(); fields["endpoint"] != tt.endpoint || fields["wallet"] != tt.name {
+				t.Errorf("logged %v", fields)
+			}
+		})
+
+		t.Run(tt.name+" failure", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+			}))
+			defer srv.Close()
+			c, logs := newTestClient(t, srv)
+			before := []Asset{{Asset: "BNB", Free: "1"}}
+			tt.target(c).store(before)
+
+			err := tt.fetch(c, context.Background())
+			apiErr, ok := err.(*APIError)
+			if !ok || apiErr.Code != ErrCodeBadSymbol || apiErr.Status != http.StatusBadRequest {
+				t.Fatalf("got error %v, want the binance error", err)
+			}
+			if assets := tt.target(c).assets(); len(assets) != 1 || assets[0].Asset != "BNB" {
+				t.Errorf("failed fetch replaced the assets with %+v", assets)
+			}
+			if failures := tt.target(c).failures.Load(); failures != 1 {
+				t.Errorf("counted %d failures, want 1", failures)
+			}
+			failed := logs.FilterMessage("Failed to get wallet data.").All()
+			if len(failed) != 1 {
+				t.Fatalf("logged %d failures, want 1", len(failed))
+			}
+			if fields := failed[0].ContextMap(); fields["endpoint"] != tt.endpoint || fields["binance_code"] != int64(ErrCodeBadSymbol) {
+				t.Errorf("logged %v", fields)
+			}
+		})
+	}
+}
+
+// TestRetryBackoff checks that a retryable error is retried once the backoff passed on the clock of the client
+func TestRetryBackoff(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"code":-1008,"msg":"Server is currently overloaded with other requests."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"asset":"BTC","free":"1","locked":"0","btcValuation":"1"}]`))
+	}))
+	defer srv.Close()
+	c, _ := newTestClient(t, srv)
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c.UseClock(fake)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.GetSpotWallet(context.Background())
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for fake.Waiters() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the client never started waiting for the retry")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	fake.Advance(retryBackoff - time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("retried before the backoff passed: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("sent %d requests before the backoff passed, want 1", n)
+	}
+
+	fake.Advance(time.Millisecond)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("retry failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the client didn't retry once the backoff passed")
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("sent %d requests, want 2", n)
+	}
+	if assets := c.GetSpotAssets(); len(assets) != 1 || assets[0].Asset != "BTC" {
+		t.Errorf("got %+v after the retry", assets)
+	}
+}
+
+// BenchmarkDecodeAssets decodes the wallet response of an account holding a few hundred assets
+func BenchmarkDecodeAssets(b *testing.B) {
+	var body bytes.Buffer
+	body.WriteByte('[')
+	for i := 0; i < 300; i++ {
+		if i > 0 {
+			body.WriteByte(',')
+		}
+		fmt.Fprintf(&body, `{"asset":"ASSET%d","free":"%d.5","locked":"0","freeze":"0","withdrawing":"0","ipoable":"0","btcValuation":"0.00%d"}`, i, i, i)
+	}
+	body.WriteByte(']')
+	raw := body.Bytes()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(raw)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		assets, err := decodeAssets(bytes.NewReader(raw), 300)
+		if err != nil || len(assets) != 300 {
+			b.Fatalf("decoded %d assets: %v", len(assets), err)
+		}
+	}
+}
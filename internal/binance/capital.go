@@ -0,0 +1,118 @@
+package binance
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+// WithdrawQuota is the rolling 24h withdrawal limit of the account and how much of it was used
+type WithdrawQuota struct {
+	WdQuota     string `json:"wdQuota"`
+	UsedWdQuota string `json:"usedWdQuota"`
+}
+
+func (c *Client) GetWithdrawQuota(ctx context.Context) (WithdrawQuota, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetWithdrawQuota")
+	defer span.End()
+
+	quota := WithdrawQuota{}
+	err := c.getSigned(ctx, "sapi/v1/capital/withdraw/quota", nil, &quota)
+	return quota, err
+}
+
+// Coin is the metadata and balance of an asset binance lists, the balances are the spot wallet ones
+type Coin struct {
+	Coin         string `json:"coin"`
+	Name         string `json:"name"` // e.g. FC Barcelona Fan Token
+	IsLegalMoney bool   `json:"isLegalMoney"`
+	Trading      bool   `json:"trading"`
+	Free         string `json:"free"`
+	Locked       string `json:"locked"`
+	Freeze       string `json:"freeze"`
+	Withdrawing  string `json:"withdrawing"`
+	Ipoing       string `json:"ipoing"` // Committed to a launchpad subscription
+	Ipoable      string `json:"ipoable"`
+	Storage      string `json:"storage"`
+}
+
+// GetCoins returns every asset binance lists, a few thousand coins
+func (c *Client) GetCoins(ctx context.Context) ([]Coin, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetCoins")
+	defer span.End()
+
+	var coins []Coin
+	err := c.getSigned(ctx, "sapi/v1/capital/config/getall", nil, &coins)
+	return coins, err
+}
+
+// WithdrawAddress is an address saved in the withdrawal address book, WhiteStatus is set once it is whitelisted
+type WithdrawAddress struct {
+	Address     string `json:"address"`
+	AddressTag  string `json:"addressTag"`
+	Coin        string `json:"coin"`
+	Name        string `json:"name"`
+	Network     string `json:"network"`
+	Origin      string `json:"origin"`
+	OriginType  string `json:"originType"`
+	WhiteStatus bool   `json:"whiteStatus"`
+}
+
+func (c *Client) GetWithdrawAddresses(ctx context.Context) ([]WithdrawAddress, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetWithdrawAddresses")
+	defer span.End()
+
+	var addresses []WithdrawAddress
+	err := c.getSigned(ctx, "sapi/v1/capital/withdraw/address/list", nil, &addresses)
+	return addresses, err
+}
+
+// Withdrawal is a withdrawal of the account, ApplyTime is when it was requested in UTC
+type Withdrawal struct {
+	ID             string `json:"id"`
+	Amount         string `json:"amount"`
+	TransactionFee string `json:"transactionFee"`
+	Coin           string `json:"coin"`
+	Status         int    `json:"status"` // 0 email sent, 2 awaiting approval, 3 rejected, 4 processing, 6 completed
+	Address        string `json:"address"`
+	Network        string `json:"network"`
+	TransferType   int    `json:"transferType"` // 0 external, 1 internal to another binance account
+	ApplyTime      string `json:"applyTime"`    // Like 2019-10-12 11:12:02
+}
+
+const (
+	// withdrawalPageSize is the largest page of the withdrawal history
+	withdrawalPageSize = 1000
+	// withdrawalWindow is the longest time range the withdrawal history accepts
+	withdrawalWindow = 90 * 24 * time.Hour
+)
+
+// GetWithdrawals returns the withdrawals requested between start and end, which are at most 90 days apart
+func (c *Client) GetWithdrawals(ctx context.Context, start, end time.Time) ([]Withdrawal, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetWithdrawals")
+	defer span.End()
+
+	if end.Sub(start) > withdrawalWindow {
+		start = end.Add(-withdrawalWindow)
+	}
+	withdrawals := make([]Withdrawal, 0)
+	for offset := 0; ; offset += withdrawalPageSize {
+		var page []Withdrawal
+		query := url.Values{
+			"startTime": {strconv.FormatInt(start.UnixMilli(), 10)},
+			"endTime":   {strconv.FormatInt(end.UnixMilli(), 10)},
+			"offset":    {strconv.Itoa(offset)},
+			"limit":     {strconv.Itoa(withdrawalPageSize)},
+		}
+		if err := c.getSigned(ctx, "sapi/v1/capital/withdraw/history", query, &page); err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, page...)
+		if len(page) < withdrawalPageSize {
+			return withdrawals, nil
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package binance
 
+import (
+	"context"
+	"time"
+)
+
 // SystemStatus represents binance  API status. Either online or under maintenance
 type SystemStatus uint
 
@@ -20,6 +25,73 @@ func (ss SystemStatus) String() string {
 
 /** Main Structure definitions **/
 type (
+	/*
+		BinanceAPI is the set of client calls the exporter depends on. Client is the live implementation,
+		anything else (fakes, cached or fan-out clients) can be injected in its place.
+	*/
+	BinanceAPI interface {
+		GetSystemStatus(ctx context.Context) (SystemStatus, error)
+		GetFundingWallet(ctx context.Context) error
+		GetSpotWallet(ctx context.Context) error
+		GetSpotAssets() []Asset
+		GetFundingAssets() []Asset
+		DisabledCollectors() map[string]string
+		// UsedWeight returns the request weight used in the current minute, false while it is unknown
+		UsedWeight() (int, bool)
+
+		// Market data, these endpoints are public and return their result directly
+		GetAvgPrice(ctx context.Context, symbol string) (AvgPrice, error)
+		GetBookTickers(ctx context.Context, symbols []string) ([]BookTicker, error)
+		GetDepth(ctx context.Context, symbol string, limit int) (Depth, error)
+		GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error)
+		GetExchangeInfo(ctx context.Context, symbols []string) (ExchangeInfo, error)
+
+		// Market streams, they run until the context ends or the connection drops and the caller reconnects
+		StreamLiquidations(ctx context.Context, symbols []string, handle func(Liquidation)) error
+		StreamBookTickers(ctx context.Context, symbols []string, handle func(BookTicker)) error
+
+		// Trade history
+		GetMyTrades(ctx context.Context, symbol string, fromID int64, limit int) ([]Trade, error)
+
+		// Margin and futures accounts
+		GetCrossMarginAccount(ctx context.Context) (CrossMarginAccount, error)
+		GetIsolatedMarginAccount(ctx context.Context) (IsolatedMarginAccount, error)
+		GetMarginInterest(ctx context.Context, start, end time.Time) ([]MarginInterest, error)
+		GetPositionRisk(ctx context.Context) ([]PositionRisk, error)
+		GetFuturesAccount(ctx context.Context) (FuturesAccount, error)
+		GetADLQuantiles(ctx context.Context) ([]ADLQuantile, error)
+		GetFuturesSymbolConfigs(ctx context.Context) ([]SymbolConfig, error)
+		GetInsuranceFund(ctx context.Context, symbol string) (InsuranceFund, error)
+		GetLongShortRatio(ctx context.Context, symbol, period string) (LongShortRatio, error)
+		GetFuturesIncome(ctx context.Context, incomeType string, start, end time.Time) ([]FuturesIncome, error)
+		GetPortfolioAccount(ctx context.Context) (PortfolioAccount, error)
+		GetPortfolioBalances(ctx context.Context) ([]PortfolioBalance, error)
+		GetOptionsAccount(ctx context.Context) (OptionsAccount, error)
+		GetOptionsPositions(ctx context.Context) ([]OptionsPosition, error)
+
+		// Account and capital
+		GetWithdrawQuota(ctx context.Context) (WithdrawQuota, error)
+		GetCoins(ctx context.Context) ([]Coin, error)
+		GetWithdrawAddresses(ctx context.Context) ([]WithdrawAddress, error)
+		GetWithdrawals(ctx context.Context, start, end time.Time) ([]Withdrawal, error)
+		GetAccountStatus(ctx context.Context) (AccountStatus, error)
+		GetAPITradingStatus(ctx context.Context) (APITradingStatus, error)
+		GetAPIRestrictions(ctx context.Context) (APIRestrictions, error)
+		GetPayTransactions(ctx context.Context, start, end time.Time) ([]PayTransaction, error)
+		GetC2COrders(ctx context.Context, tradeType string, start, end time.Time) ([]C2COrder, error)
+		GetSpotRebates(ctx context.Context, start, end time.Time) ([]SpotRebate, error)
+		GetUniversalTransfers(ctx context.Context, transferType string, start, end time.Time) ([]UniversalTransfer, error)
+
+		// Earn products
+		GetFlexibleProducts(ctx context.Context, asset string) (FlexibleProducts, error)
+		GetFlexiblePositions(ctx context.Context) ([]FlexiblePosition, error)
+		GetLockedPositions(ctx context.Context) ([]LockedPosition, error)
+		GetFlexibleSubscriptions(ctx context.Context, start, end time.Time) ([]FlexibleSubscription, error)
+		GetFlexibleRewards(ctx context.Context, rewardType string, start, end time.Time) ([]FlexibleReward, error)
+		GetLockedRewards(ctx context.Context, start, end time.Time) ([]LockedReward, error)
+		GetDualInvestments(ctx context.Context) ([]DualInvestment, error)
+	}
+
 	/*
 		APIStatus is used to determine the status of the binance api
 	*/
@@ -0,0 +1,134 @@
+package binance
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var _ BinanceAPI = (*DemoClient)(nil)
+
+type (
+	/*
+		DemoClient serves synthetic but plausible wallet data without ever contacting binance, so the exporter and
+		dashboards can be evaluated before real credentials are wired in. Prices random walk on every refresh.
+	*/
+	DemoClient struct {
+		logger  *zap.Logger
+		rand    *rand.Rand
+		prices  map[string]float64 // Price of each demo asset in BTC
+		lock    sync.Mutex         // Guards rand and prices
+		funding *Data
+		spot    *Data
+	}
+	demoHolding struct {
+		asset  string
+		free   float64
+		locked float64
+	}
+)
+
+var (
+	demoPrices = map[string]float64{
+		"BTC":  1,
+		"ETH":  0.0532,
+		"BNB":  0.0089,
+		"SOL":  0.0021,
+		"USDT": 0.0000296,
+		"USDC": 0.0000296,
+		"BAR":  0.0000189,
+	}
+	demoSpot = []demoHolding{
+		{asset: "BTC", free: 0.4213, locked: 0.05},
+		{asset: "ETH", free: 3.105},
+		{asset: "BNB", free: 12.51, locked: 1.2},
+		{asset: "SOL", free: 40.7},
+		{asset: "USDT", free: 2150.33, locked: 300},
+		{asset: "BAR", free: 21.4}, // Airdropped fan tokens
+	}
+	demoFunding = []demoHolding{
+		{asset: "USDT", free: 500},
+		{asset: "USDC", free: 1200.5},
+		{asset: "BNB", free: 0.75},
+	}
+)
+
+func NewDemoClient(l *zap.Logger) *DemoClient {
+	prices := make(map[string]float64, len(demoPrices))
+	for asset, price := range demoPrices {
+		prices[asset] = price
+	}
+	return &DemoClient{
+		logger:  l,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		prices:  prices,
+		funding: newData("funding"),
+		spot:    newData("spot"),
+	}
+}
+
+func (d *DemoClient) GetSystemStatus(context.Context) (SystemStatus, error) {
+	d.logger.Info("Demo mode, not contacting binance")
+	return Online, nil
+}
+
+func (d *DemoClient) GetFundingWallet(context.Context) error {
+	d.store(d.funding, demoFunding)
+	return nil
+}
+
+func (d *DemoClient) GetSpotWallet(context.Context) error {
+	d.walkPrices()
+	d.store(d.spot, demoSpot)
+	return nil
+}
+
+func (d *DemoClient) GetSpotAssets() []Asset {
+	return d.spot.assets()
+}
+
+func (d *DemoClient) GetFundingAssets() []Asset {
+	return d.funding.assets()
+}
+
+func (d *DemoClient) DisabledCollectors() map[string]string {
+	return map[string]string{}
+}
+
+// walkPrices moves every non BTC, non stablecoin price by up to half a percent
+func (d *DemoClient) walkPrices() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for asset, price := range d.prices {
+		if asset == "BTC" || asset == "USDT" || asset == "USDC" {
+			continue
+		}
+		d.prices[asset] = price * (1 + (d.rand.Float64()-0.5)/100)
+	}
+}
+
+func (d *DemoClient) store(target *Data, holdings []demoHolding) {
+	d.lock.Lock()
+	assets := make([]Asset, 0, len(holdings))
+	for _, h := range holdings {
+		assets = append(assets, Asset{
+			Asset:        h.asset,
+			Free:         formatDemo(h.free),
+			Locked:       formatDemo(h.locked),
+			Freeze:       "0",
+			Withdrawing:  "0",
+			Ipoable:      "0",
+			BtcValuation: formatDemo((h.free + h.locked) * d.prices[h.asset]),
+		})
+	}
+	d.lock.Unlock()
+	target.store(assets)
+}
+
+func formatDemo(v float64) string {
+	return strconv.FormatFloat(v, 'f', 8, 64)
+}
@@ -0,0 +1,110 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+func (d *DemoClient) GetWithdrawQuota(context.Context) (WithdrawQuota, error) {
+	return WithdrawQuota{WdQuota: "8000000", UsedWdQuota: "12500"}, nil
+}
+
+// GetCoins lists the demo assets with their spot balances, BAR is a fan token
+func (d *DemoClient) GetCoins(context.Context) ([]Coin, error) {
+	coins := []Coin{
+		{Coin: "BTC", Name: "Bitcoin", Trading: true},
+		{Coin: "ETH", Name: "Ethereum", Trading: true},
+		{Coin: "BNB", Name: "BNB", Trading: true},
+		{Coin: "SOL", Name: "Solana", Trading: true},
+		{Coin: "USDT", Name: "TetherUS", Trading: true},
+		{Coin: "USDC", Name: "USD Coin", Trading: true},
+		{Coin: "BAR", Name: "FC Barcelona Fan Token", Trading: true},
+		{Coin: "EUR", Name: "Euro", IsLegalMoney: true, Trading: true},
+	}
+	for i := range coins {
+		c := &coins[i]
+		c.Free, c.Locked, c.Freeze, c.Withdrawing, c.Ipoing, c.Ipoable, c.Storage = "0", "0", "0", "0", "0", "0", "0"
+		for _, h := range demoSpot {
+			if h.asset == c.Coin {
+				c.Free, c.Locked = formatDemo(h.free), formatDemo(h.locked)
+			}
+		}
+	}
+	return coins, nil
+}
+
+// GetWithdrawAddresses has a whitelisted BTC address and a USDT one that was saved but never whitelisted
+func (d *DemoClient) GetWithdrawAddresses(context.Context) ([]WithdrawAddress, error) {
+	return []WithdrawAddress{
+		{Address: "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh", Coin: "BTC", Name: "Cold storage", Network: "BTC", Origin: "bla", OriginType: "others", WhiteStatus: true},
+		{Address: "0x8894E0a0c962CB723c1976a4421c95949bE2D4E3", Coin: "USDT", Name: "Exchange", Network: "ETH", Origin: "bla", OriginType: "others"},
+	}, nil
+}
+
+// GetWithdrawals withdraws BTC to the cold storage every week and USDT to the exchange address every other day
+func (d *DemoClient) GetWithdrawals(_ context.Context, start, end time.Time) ([]Withdrawal, error) {
+	withdrawals := make([]Withdrawal, 0)
+	for at := end.Truncate(24 * time.Hour); !at.Before(start); at = at.Add(-24 * time.Hour) {
+		day, applied := at.Unix()/86400, at.Add(9*time.Hour)
+		if applied.After(end) || applied.Before(start) {
+			continue
+		}
+		w := Withdrawal{ID: strconv.FormatInt(day, 16), Status: 6, ApplyTime: applied.UTC().Format("2006-01-02 15:04:05")}
+		switch {
+		case day%7 == 0:
+			w.Coin, w.Network, w.Amount, w.TransactionFee, w.Address = "BTC", "BTC", "0.05", "0.0000045", "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh"
+		case day%2 == 0:
+			w.Coin, w.Network, w.Amount, w.TransactionFee, w.Address = "USDT", "ETH", "250", "4.5", "0x8894E0a0c962CB723c1976a4421c95949bE2D4E3"
+		default:
+			continue
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	return withdrawals, nil
+}
+
+func (d *DemoClient) GetAccountStatus(context.Context) (AccountStatus, error) {
+	return AccountStatus{Data: "Normal"}, nil
+}
+
+func (d *DemoClient) GetAPITradingStatus(context.Context) (APITradingStatus, error) {
+	status := APITradingStatus{}
+	status.Data.TriggerCondition = map[string]float64{"GCR": 150, "IFER": 150, "UFR": 300}
+	status.Data.Indicators = map[string][]TradingIndicator{
+		"BTCUSDT": {{Indicator: "UFR", Count: 20, Value: 0.05, Trigger: 0.995}},
+	}
+	return status, nil
+}
+
+// GetAPIRestrictions is a read only key restricted to a few IPs, created a year ago
+func (d *DemoClient) GetAPIRestrictions(context.Context) (APIRestrictions, error) {
+	return APIRestrictions{
+		IPRestrict:    true,
+		CreateTime:    time.Now().AddDate(-1, 0, 0).Truncate(24 * time.Hour).UnixMilli(),
+		EnableReading: true,
+	}, nil
+}
+
+// GetUniversalTransfers moves some USDT from spot to funding at the start of every day and back every third day
+func (d *DemoClient) GetUniversalTransfers(_ context.Context, transferType string, start, end time.Time) ([]UniversalTransfer, error) {
+	transfers := make([]UniversalTransfer, 0)
+	for at := end.Truncate(24 * time.Hour); !at.Before(start); at = at.Add(-24 * time.Hour) {
+		day := at.Unix() / 86400
+		switch {
+		case transferType == "MAIN_FUNDING":
+		case transferType == "FUNDING_MAIN" && day%3 == 0:
+		default:
+			continue
+		}
+		transfers = append(transfers, UniversalTransfer{
+			Asset:     "USDT",
+			Amount:    "100",
+			Type:      transferType,
+			Status:    "CONFIRMED",
+			TranID:    day,
+			Timestamp: at.UnixMilli(),
+		})
+	}
+	return transfers, nil
+}
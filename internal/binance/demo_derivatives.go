@@ -0,0 +1,223 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// GetCrossMarginAccount borrows a third of the demo BTC holdings against the rest
+func (d *DemoClient) GetCrossMarginAccount(context.Context) (CrossMarginAccount, error) {
+	assets, liabilities := 0.3, 0.1
+	return CrossMarginAccount{
+		MarginLevel:         formatDemo(assets / liabilities),
+		TotalAssetOfBtc:     formatDemo(assets),
+		TotalLiabilityOfBtc: formatDemo(liabilities),
+		TotalNetAssetOfBtc:  formatDemo(assets - liabilities),
+		TradeEnabled:        true,
+	}, nil
+}
+
+func (d *DemoClient) GetIsolatedMarginAccount(context.Context) (IsolatedMarginAccount, error) {
+	price, err := d.symbolPrice("ETHUSDT")
+	if err != nil {
+		return IsolatedMarginAccount{}, err
+	}
+	return IsolatedMarginAccount{
+		Assets: []IsolatedMarginPair{{
+			Symbol:         "ETHUSDT",
+			MarginLevel:    "2.40000000",
+			MarginRatio:    "5",
+			IndexPrice:     formatDemo(price),
+			LiquidatePrice: formatDemo(price * 0.55),
+			LiquidateRate:  "45.83333333",
+			Enabled:        true,
+		}},
+		TotalNetAssetOfBtc: "0.02500000",
+	}, nil
+}
+
+// GetPositionRisk holds a 5x long BTCUSDT position entered slightly below the current price
+func (d *DemoClient) GetPositionRisk(context.Context) ([]PositionRisk, error) {
+	price, err := d.symbolPrice("BTCUSDT")
+	if err != nil {
+		return nil, err
+	}
+	entry := price * 0.98
+	return []PositionRisk{{
+		Symbol:           "BTCUSDT",
+		PositionSide:     "BOTH",
+		PositionAmt:      "0.050",
+		EntryPrice:       formatDemo(entry),
+		MarkPrice:        formatDemo(price),
+		LiquidationPrice: formatDemo(entry * 0.81),
+		UnRealizedProfit: formatDemo(0.05 * (price - entry)),
+		Leverage:         "5",
+		MarginType:       "cross",
+	}}, nil
+}
+
+// GetFuturesAccount backs the demo position with 2000 USDT of cross margin
+func (d *DemoClient) GetFuturesAccount(ctx context.Context) (FuturesAccount, error) {
+	positions, err := d.GetPositionRisk(ctx)
+	if err != nil {
+		return FuturesAccount{}, err
+	}
+	p := positions[0]
+	notional := parseDemo(p.PositionAmt) * parseDemo(p.MarkPrice)
+	maint := notional * 0.004
+	return FuturesAccount{
+		TotalWalletBalance: "2000.00000000",
+		TotalMarginBalance: formatDemo(2000 + parseDemo(p.UnRealizedProfit)),
+		TotalMaintMargin:   formatDemo(maint),
+		Positions: []FuturesPosition{{
+			Symbol:        p.Symbol,
+			PositionSide:  p.PositionSide,
+			PositionAmt:   p.PositionAmt,
+			InitialMargin: formatDemo(notional / 5),
+			MaintMargin:   formatDemo(maint),
+		}},
+	}, nil
+}
+
+func (d *DemoClient) GetADLQuantiles(context.Context) ([]ADLQuantile, error) {
+	return []ADLQuantile{{Symbol: "BTCUSDT", AdlQuantile: map[string]int{"LONG": 0, "SHORT": 0, "BOTH": 2}}}, nil
+}
+
+// GetFuturesSymbolConfigs configures the demo positions and leaves a forgotten 50x on a symbol without a position
+func (d *DemoClient) GetFuturesSymbolConfigs(context.Context) ([]SymbolConfig, error) {
+	return []SymbolConfig{
+		{Symbol: "BTCUSDT", MarginType: "CROSSED", Leverage: 5, MaxNotionalValue: "80000000"},
+		{Symbol: "DOGEUSDT", MarginType: "CROSSED", Leverage: 50, MaxNotionalValue: "50000"},
+		{Symbol: "SOLUSDT", MarginType: "CROSSED", Leverage: 20, MaxNotionalValue: "1000000"},
+	}, nil
+}
+
+// GetInsuranceFund holds a fund in the millions of USDT for every symbol
+func (d *DemoClient) GetInsuranceFund(_ context.Context, symbol string) (InsuranceFund, error) {
+	if _, err := d.symbolPrice(symbol); err != nil {
+		return InsuranceFund{}, err
+	}
+	now := time.Now().UnixMilli()
+	return InsuranceFund{
+		Symbols: []string{symbol},
+		Assets: []InsuranceFundAsset{
+			{Asset: "USDT", MarginBalance: "31283640.41637008", UpdateTime: now},
+			{Asset: "USDC", MarginBalance: "1092783.10022216", UpdateTime: now},
+		},
+	}, nil
+}
+
+// GetLongShortRatio leans the accounts slightly long, wandering a bit between calls
+func (d *DemoClient) GetLongShortRatio(_ context.Context, symbol, _ string) (LongShortRatio, error) {
+	if _, err := d.symbolPrice(symbol); err != nil {
+		return LongShortRatio{}, err
+	}
+	d.lock.Lock()
+	long := 0.55 + (d.rand.Float64()-0.5)/20
+	d.lock.Unlock()
+	return LongShortRatio{
+		Symbol:         symbol,
+		LongShortRatio: formatDemo(long / (1 - long)),
+		LongAccount:    formatDemo(long),
+		ShortAccount:   formatDemo(1 - long),
+		Timestamp:      time.Now().Truncate(5 * time.Minute).UnixMilli(),
+	}, nil
+}
+
+func parseDemo(v string) float64 {
+	f, _ := strconv.ParseFloat(v, 64)
+	return f
+}
+
+// GetFuturesIncome pays a USDT referral kickback and settles the funding of the demo positions every eight hours, other
+// income types stay empty
+func (d *DemoClient) GetFuturesIncome(_ context.Context, incomeType string, start, end time.Time) ([]FuturesIncome, error) {
+	incomes := make([]FuturesIncome, 0)
+	for at := start.Truncate(8 * time.Hour).Add(8 * time.Hour); !at.After(end); at = at.Add(8 * time.Hour) {
+		switch incomeType {
+		case "REFERRAL_KICKBACK":
+			incomes = append(incomes, FuturesIncome{IncomeType: incomeType, Income: "0.41250000", Asset: "USDT", Time: at.UnixMilli(), TranID: at.Unix()})
+		case "FUNDING_FEE":
+			// The long pays the funding, the short receives it
+			incomes = append(incomes,
+				FuturesIncome{Symbol: "BTCUSDT", IncomeType: incomeType, Income: "-1.20310000", Asset: "USDT", Time: at.UnixMilli(), TranID: 2 * at.Unix()},
+				FuturesIncome{Symbol: "ETHUSDT", IncomeType: incomeType, Income: "0.35120000", Asset: "USDT", Time: at.UnixMilli(), TranID: 2*at.Unix() + 1},
+			)
+		}
+	}
+	return incomes, nil
+}
+
+// GetMarginInterest charges hourly interest on a small USDT loan of the cross margin account
+func (d *DemoClient) GetMarginInterest(_ context.Context, start, end time.Time) ([]MarginInterest, error) {
+	interests := make([]MarginInterest, 0)
+	for at := start.Truncate(time.Hour).Add(time.Hour); !at.After(end); at = at.Add(time.Hour) {
+		interests = append(interests, MarginInterest{TxID: at.Unix(), Asset: "USDT", Interest: "0.00410000", InterestRate: "0.00000410", Type: "PERIODIC", Time: at.UnixMilli()})
+	}
+	return interests, nil
+}
+
+// GetPortfolioAccount holds the demo BTC and USDT as Portfolio Margin collateral with a small UM futures position
+func (d *DemoClient) GetPortfolioAccount(context.Context) (PortfolioAccount, error) {
+	price, err := d.symbolPrice("BTCUSDT")
+	if err != nil {
+		return PortfolioAccount{}, err
+	}
+	equity := 0.2*price + 5000
+	return PortfolioAccount{
+		UniMMR:                   "5.41200000",
+		AccountEquity:            formatDemo(equity * 0.95),
+		ActualEquity:             formatDemo(equity),
+		AccountInitialMargin:     formatDemo(equity * 0.09),
+		AccountMaintMargin:       formatDemo(equity * 0.95 / 5.412),
+		AccountStatus:            "NORMAL",
+		VirtualMaxWithdrawAmount: formatDemo(equity * 0.8),
+		TotalAvailableBalance:    formatDemo(equity * 0.86),
+	}, nil
+}
+
+func (d *DemoClient) GetPortfolioBalances(context.Context) ([]PortfolioBalance, error) {
+	return []PortfolioBalance{
+		{Asset: "BTC", TotalWalletBalance: "0.2", CrossMarginAsset: "0.2", CrossMarginBorrowed: "0", CrossMarginFree: "0.2", CrossMarginInterest: "0",
+			CrossMarginLocked: "0", UMWalletBalance: "0", UMUnrealizedPNL: "0", CMWalletBalance: "0", CMUnrealizedPNL: "0", NegativeBalance: "0"},
+		{Asset: "USDT", TotalWalletBalance: "5000", CrossMarginAsset: "3500", CrossMarginBorrowed: "250", CrossMarginFree: "3500", CrossMarginInterest: "0.41",
+			CrossMarginLocked: "0", UMWalletBalance: "1500", UMUnrealizedPNL: "37.5", CMWalletBalance: "0", CMUnrealizedPNL: "0", NegativeBalance: "0"},
+	}, nil
+}
+
+// GetOptionsAccount holds 2000 USDT of options margin next to the demo positions
+func (d *DemoClient) GetOptionsAccount(ctx context.Context) (OptionsAccount, error) {
+	positions, err := d.GetOptionsPositions(ctx)
+	if err != nil {
+		return OptionsAccount{}, err
+	}
+	value, pnl := 0.0, 0.0
+	for _, p := range positions {
+		value += parseDemo(p.MarkValue)
+		pnl += parseDemo(p.UnrealizedPNL)
+	}
+	return OptionsAccount{
+		Assets: []OptionsAsset{{Asset: "USDT", MarginBalance: "2000", Equity: formatDemo(2000 + value), Available: "1850", Locked: "150",
+			UnrealizedPNL: formatDemo(pnl)}},
+		RiskLevel: "NORMAL",
+	}, nil
+}
+
+// GetOptionsPositions is a long BTC call expiring on the next friday, its mark price drifts with the BTC price
+func (d *DemoClient) GetOptionsPositions(context.Context) ([]OptionsPosition, error) {
+	price, err := d.symbolPrice("BTCUSDT")
+	if err != nil {
+		return nil, err
+	}
+	strike := float64(int(price/5000)+1) * 5000
+	expiry := time.Now().UTC().Truncate(24 * time.Hour)
+	expiry = expiry.AddDate(0, 0, (int(time.Friday)-int(expiry.Weekday())+7)%7+1).Add(-16 * time.Hour)
+	symbol := "BTC-" + expiry.Format("060102") + "-" + strconv.Itoa(int(strike)) + "-C"
+	mark, entry := price*0.012, 650.0
+	return []OptionsPosition{{
+		Symbol: symbol, Side: "LONG", OptionSide: "CALL", Quantity: "0.5", EntryPrice: formatDemo(entry), MarkPrice: formatDemo(mark),
+		MarkValue: formatDemo(0.5 * mark), UnrealizedPNL: formatDemo(0.5 * (mark - entry)), StrikePrice: formatDemo(strike),
+		ExpiryDate: expiry.UnixMilli(), QuoteAsset: "USDT",
+	}}, nil
+}
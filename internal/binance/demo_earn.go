@@ -0,0 +1,108 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// demoAPRs are the flexible rates of the demo products
+var demoAPRs = map[string]float64{"USDT": 0.0612, "USDC": 0.0587, "BNB": 0.0145, "ETH": 0.0231, "BTC": 0.0042}
+
+// GetFlexibleProducts offers one product per demo asset with its rate jittered by up to a tenth
+func (d *DemoClient) GetFlexibleProducts(_ context.Context, asset string) (FlexibleProducts, error) {
+	apr, ok := demoAPRs[asset]
+	if !ok {
+		return FlexibleProducts{Rows: []FlexibleProduct{}}, nil
+	}
+	d.lock.Lock()
+	apr *= 1 + (d.rand.Float64()-0.5)/5
+	d.lock.Unlock()
+	return FlexibleProducts{
+		Rows: []FlexibleProduct{{
+			Asset:                      asset,
+			ProductID:                  asset + "001",
+			LatestAnnualPercentageRate: formatDemo(apr),
+			CanPurchase:                true,
+		}},
+		Total: 1,
+	}, nil
+}
+
+// GetFlexiblePositions holds some USDT and BNB, the latter standing in for a BNB Vault subscription
+func (d *DemoClient) GetFlexiblePositions(context.Context) ([]FlexiblePosition, error) {
+	return []FlexiblePosition{
+		{Asset: "USDT", ProductID: "USDT001", TotalAmount: "1520.00000000", LatestAnnualPercentageRate: formatDemo(demoAPRs["USDT"]), CumulativeTotalRewards: "14.83000000", CanRedeem: true, AutoSubscribe: true},
+		{Asset: "BNB", ProductID: "BNB001", TotalAmount: "4.20000000", LatestAnnualPercentageRate: formatDemo(demoAPRs["BNB"]), CumulativeTotalRewards: "0.03170000", CanRedeem: true},
+	}, nil
+}
+
+// GetFlexibleSubscriptions auto-subscribes some idle USDT at the start of every day and subscribes BNB by hand weekly
+func (d *DemoClient) GetFlexibleSubscriptions(_ context.Context, start, end time.Time) ([]FlexibleSubscription, error) {
+	subscriptions := make([]FlexibleSubscription, 0)
+	for at := end.Truncate(24 * time.Hour); !at.Before(start); at = at.Add(-24 * time.Hour) {
+		day := at.Unix() / 86400
+		subscriptions = append(subscriptions, FlexibleSubscription{
+			Asset: "USDT", Amount: "40", Time: at.UnixMilli(), PurchaseID: 2 * day, ProductID: "USDT001",
+			Type: "AUTO", AmtFromSpot: "30", AmtFromFunding: "10", Status: "SUCCESS",
+		})
+		if day%7 == 0 {
+			subscriptions = append(subscriptions, FlexibleSubscription{
+				Asset: "BNB", Amount: "0.5", Time: at.UnixMilli(), PurchaseID: 2*day + 1, ProductID: "BNB001",
+				Type: "NORMAL", AmtFromSpot: "0.5", AmtFromFunding: "0", Status: "SUCCESS",
+			})
+		}
+	}
+	return subscriptions, nil
+}
+
+// GetFlexibleRewards pays the realtime APR of the demo USDT position every day and a BNB bonus on top of it
+func (d *DemoClient) GetFlexibleRewards(_ context.Context, rewardType string, start, end time.Time) ([]FlexibleReward, error) {
+	rewards := make([]FlexibleReward, 0)
+	for at := end.Truncate(24 * time.Hour); !at.Before(start); at = at.Add(-24 * time.Hour) {
+		switch rewardType {
+		case "REALTIME":
+			rewards = append(rewards, FlexibleReward{Asset: "USDT", Rewards: "0.38410000", ProjectID: "USDT001", Type: "REALTIME", Time: at.UnixMilli()})
+		case "BONUS":
+			rewards = append(rewards, FlexibleReward{Asset: "BNB", Rewards: "0.00012000", ProjectID: "BNB001", Type: "BONUS", Time: at.UnixMilli()})
+		}
+	}
+	return rewards, nil
+}
+
+// GetLockedRewards pays the demo ETH lock its daily reward
+func (d *DemoClient) GetLockedRewards(_ context.Context, start, end time.Time) ([]LockedReward, error) {
+	rewards := make([]LockedReward, 0)
+	for at := end.Truncate(24 * time.Hour); !at.Before(start); at = at.Add(-24 * time.Hour) {
+		rewards = append(rewards, LockedReward{PositionID: 7001, Asset: "ETH", Amount: "0.00041000", LockPeriod: "120", Type: "Locked Rewards", Time: at.UnixMilli()})
+	}
+	return rewards, nil
+}
+
+// GetLockedPositions locks some ETH for 120 days, maturing a month from now
+func (d *DemoClient) GetLockedPositions(context.Context) ([]LockedPosition, error) {
+	redeem := time.Now().Add(30 * 24 * time.Hour).UnixMilli()
+	return []LockedPosition{
+		{PositionID: 1, ProjectID: "Eth*120", Asset: "ETH", Amount: "1.50000000", RewardAsset: "ETH", RewardAmt: "0.00912000", APY: "0.0310", Duration: "120", RewardsEndDate: strconv.FormatInt(redeem, 10), Status: "HOLDING"},
+	}, nil
+}
+
+// GetDualInvestments sells BNB high a few percent above its price, settling in three days
+func (d *DemoClient) GetDualInvestments(context.Context) ([]DualInvestment, error) {
+	price, err := d.symbolPrice("BNBUSDT")
+	if err != nil {
+		return nil, err
+	}
+	return []DualInvestment{{
+		ID:                 "1",
+		InvestCoin:         "BNB",
+		ExercisedCoin:      "USDT",
+		SubscriptionAmount: "2.00000000",
+		StrikePrice:        formatDemo(price * 1.05),
+		Duration:           "7",
+		SettleDate:         time.Now().Add(3 * 24 * time.Hour).Truncate(time.Hour).UnixMilli(),
+		PurchaseStatus:     "PURCHASE_SUCCESS",
+		APR:                "0.4510",
+		OptionType:         "CALL",
+	}}, nil
+}
@@ -0,0 +1,165 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// demoQuotes are the quote assets demo symbols like ETHUSDT are split by
+var demoQuotes = []string{"USDT", "USDC", "BTC", "ETH", "BNB"}
+
+func (d *DemoClient) GetAvgPrice(_ context.Context, symbol string) (AvgPrice, error) {
+	price, err := d.symbolPrice(symbol)
+	if err != nil {
+		return AvgPrice{}, err
+	}
+	return AvgPrice{Mins: 5, Price: formatDemo(price)}, nil
+}
+
+// GetBookTickers quotes every symbol with a spread of 1 to 5 basis points around its price
+func (d *DemoClient) GetBookTickers(_ context.Context, symbols []string) ([]BookTicker, error) {
+	tickers := make([]BookTicker, 0, len(symbols))
+	for _, symbol := range symbols {
+		price, err := d.symbolPrice(symbol)
+		if err != nil {
+			return nil, err
+		}
+		d.lock.Lock()
+		half := price * (1 + 4*d.rand.Float64()) / 20000
+		bidQty, askQty := 10*d.rand.Float64(), 10*d.rand.Float64()
+		d.lock.Unlock()
+		tickers = append(tickers, BookTicker{
+			Symbol:   symbol,
+			BidPrice: formatDemo(price - half),
+			BidQty:   formatDemo(bidQty),
+			AskPrice: formatDemo(price + half),
+			AskQty:   formatDemo(askQty),
+		})
+	}
+	return tickers, nil
+}
+
+// GetDepth builds a book with levels one basis point apart and random quantities around the price of the symbol
+func (d *DemoClient) GetDepth(_ context.Context, symbol string, limit int) (Depth, error) {
+	price, err := d.symbolPrice(symbol)
+	if err != nil {
+		return Depth{}, err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	depth := Depth{LastUpdateID: time.Now().UnixMilli()}
+	for i := 1; i <= limit; i++ {
+		offset := price * float64(i) / 10000
+		depth.Bids = append(depth.Bids, [2]string{formatDemo(price - offset), formatDemo(5 * d.rand.Float64())})
+		depth.Asks = append(depth.Asks, [2]string{formatDemo(price + offset), formatDemo(5 * d.rand.Float64())})
+	}
+	return depth, nil
+}
+
+// demoIntervals are the kline intervals the demo client can generate
+var demoIntervals = map[string]time.Duration{"1h": time.Hour, "1d": 24 * time.Hour}
+
+// GetKlines random walks backwards from the current price of the symbol, a percent per candle at most
+func (d *DemoClient) GetKlines(_ context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	price, err := d.symbolPrice(symbol)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := demoIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("demo klines only support 1h and 1d, not %s", interval)
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	klines := make([]Kline, limit)
+	open := time.Now().Truncate(step)
+	for i := limit - 1; i >= 0; i-- {
+		prev := price * (1 + (d.rand.Float64()-0.5)/50)
+		klines[i] = Kline{
+			OpenTime:  open.UnixMilli(),
+			Open:      formatDemo(prev),
+			High:      formatDemo(math.Max(prev, price) * 1.002),
+			Low:       formatDemo(math.Min(prev, price) * 0.998),
+			Close:     formatDemo(price),
+			Volume:    formatDemo(1000 * d.rand.Float64()),
+			CloseTime: open.Add(step).UnixMilli() - 1,
+		}
+		price = prev
+		open = open.Add(-step)
+	}
+	return klines, nil
+}
+
+// GetExchangeInfo reports every demo symbol as trading with the same rules
+func (d *DemoClient) GetExchangeInfo(_ context.Context, symbols []string) (ExchangeInfo, error) {
+	info := ExchangeInfo{}
+	if len(symbols) == 0 {
+		symbols = d.symbols()
+	}
+	for _, symbol := range symbols {
+		if _, err := d.symbolPrice(symbol); err != nil {
+			return ExchangeInfo{}, err
+		}
+		base, quote := splitDemoSymbol(symbol)
+		info.Symbols = append(info.Symbols, SymbolInfo{
+			Symbol:     symbol,
+			Status:     "TRADING",
+			BaseAsset:  base,
+			QuoteAsset: quote,
+			Filters: []SymbolFilter{
+				{FilterType: "PRICE_FILTER", TickSize: "0.01000000"},
+				{FilterType: "LOT_SIZE", MinQty: "0.00010000", StepSize: "0.00010000"},
+			},
+		})
+	}
+	return info, nil
+}
+
+// demoListings are the only quote assets demo assets like fan tokens are listed against, not every demo quote
+var demoListings = map[string]string{"BAR": "BNB"}
+
+// symbols returns every pair of a demo asset with a demo quote binance would list, sorted
+func (d *DemoClient) symbols() []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	res := make([]string, 0)
+	for base := range d.prices {
+		for _, quote := range demoQuotes {
+			if listed, ok := demoListings[base]; base != quote && (!ok || listed == quote) {
+				res = append(res, base+quote)
+			}
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// splitDemoSymbol returns the base and quote asset of a demo symbol
+func splitDemoSymbol(symbol string) (string, string) {
+	for _, quote := range demoQuotes {
+		if base, ok := strings.CutSuffix(symbol, quote); ok && len(base) > 0 {
+			return base, quote
+		}
+	}
+	return symbol, ""
+}
+
+// symbolPrice derives the price of a symbol like ETHUSDT from the BTC prices of its base and quote asset
+func (d *DemoClient) symbolPrice(symbol string) (float64, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, quote := range demoQuotes {
+		base, ok := strings.CutSuffix(symbol, quote)
+		if !ok {
+			continue
+		}
+		if basePrice, ok := d.prices[base]; ok {
+			return basePrice / d.prices[quote], nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s is not a demo symbol", ErrBadSymbol, symbol)
+}
@@ -0,0 +1,67 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// GetPayTransactions receives a USDT payment at the start of every hour and refunds every fifth one
+func (d *DemoClient) GetPayTransactions(_ context.Context, start, end time.Time) ([]PayTransaction, error) {
+	transactions := make([]PayTransaction, 0)
+	for at := end.Truncate(time.Hour); !at.Before(start); at = at.Add(-time.Hour) {
+		hour := at.Unix() / 3600
+		transactions = append(transactions, PayTransaction{
+			OrderType:       "PAY",
+			TransactionID:   "P_" + strconv.FormatInt(hour, 10),
+			TransactionTime: at.UnixMilli(),
+			Amount:          strconv.FormatInt(10+hour%40, 10),
+			Currency:        "USDT",
+		})
+		if hour%5 == 0 {
+			transactions = append(transactions, PayTransaction{
+				OrderType:       "PAY_REFUND",
+				TransactionID:   "R_" + strconv.FormatInt(hour, 10),
+				TransactionTime: at.UnixMilli(),
+				Amount:          "-" + strconv.FormatInt(10+hour%40, 10),
+				Currency:        "USDT",
+			})
+		}
+	}
+	return transactions, nil
+}
+
+// GetC2COrders sells some USDT for EUR every six hours, the latest order is still waiting for the buyer
+func (d *DemoClient) GetC2COrders(_ context.Context, tradeType string, start, end time.Time) ([]C2COrder, error) {
+	orders := make([]C2COrder, 0)
+	if tradeType != "SELL" {
+		return orders, nil
+	}
+	latest := end.Truncate(6 * time.Hour)
+	for at := latest; !at.Before(start); at = at.Add(-6 * time.Hour) {
+		status := "COMPLETED"
+		if at.Equal(latest) {
+			status = "TRADING"
+		}
+		orders = append(orders, C2COrder{
+			OrderNumber: "C_" + strconv.FormatInt(at.Unix(), 10),
+			TradeType:   tradeType,
+			Asset:       "USDT",
+			Fiat:        "EUR",
+			Amount:      "250.00000000",
+			TotalPrice:  "231.75000000",
+			OrderStatus: status,
+			CreateTime:  at.UnixMilli(),
+		})
+	}
+	return orders, nil
+}
+
+// GetSpotRebates pays a small BNB commission rebate at the start of every day
+func (d *DemoClient) GetSpotRebates(_ context.Context, start, end time.Time) ([]SpotRebate, error) {
+	rebates := make([]SpotRebate, 0)
+	for at := end.Truncate(24 * time.Hour); !at.Before(start); at = at.Add(-24 * time.Hour) {
+		rebates = append(rebates, SpotRebate{Asset: "BNB", Type: 1, Amount: "0.00214000", UpdateTime: at.UnixMilli()})
+	}
+	return rebates, nil
+}
@@ -0,0 +1,64 @@
+package binance
+
+import (
+	"context"
+	"time"
+)
+
+// StreamLiquidations liquidates a small position of one of the symbols every few seconds, longs more often than shorts
+func (d *DemoClient) StreamLiquidations(ctx context.Context, symbols []string, handle func(Liquidation)) error {
+	for _, symbol := range symbols {
+		if _, err := d.symbolPrice(symbol); err != nil {
+			return err
+		}
+	}
+	for {
+		d.lock.Lock()
+		wait := time.Duration(1+d.rand.Intn(4)) * time.Second
+		symbol := symbols[d.rand.Intn(len(symbols))]
+		side := "SELL"
+		if d.rand.Float64() < 0.3 {
+			side = "BUY"
+		}
+		notional := 500 + d.rand.Float64()*20000
+		d.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		price, err := d.symbolPrice(symbol)
+		if err != nil {
+			return err
+		}
+		handle(Liquidation{
+			Symbol:       symbol,
+			Side:         side,
+			Quantity:     formatDemo(notional / price),
+			AveragePrice: formatDemo(price),
+			Status:       "FILLED",
+			Time:         time.Now().UnixMilli(),
+		})
+	}
+}
+
+// StreamBookTickers sends the demo book tickers of all symbols five times a second
+func (d *DemoClient) StreamBookTickers(ctx context.Context, symbols []string, handle func(BookTicker)) error {
+	tick := time.NewTicker(200 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		tickers, err := d.GetBookTickers(ctx, symbols)
+		if err != nil {
+			return err
+		}
+		for _, t := range tickers {
+			handle(t)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package binance
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+)
+
+// demoTradeHistory is how far back the demo trade history goes, it trades once an hour
+const demoTradeHistory = 14 * 24 * time.Hour
+
+/*
+GetMyTrades trades about 1000 of the quote asset at the start of every hour, buying twice for every sale. Trade ids are
+hours since the epoch, so every id stays the same trade across calls. Prices swing a few percent around the current
+price.
+*/
+func (d *DemoClient) GetMyTrades(_ context.Context, symbol string, fromID int64, limit int) ([]Trade, error) {
+	price, err := d.symbolPrice(symbol)
+	if err != nil {
+		return nil, err
+	}
+	quantity := 1000 / price
+	quote := ""
+	for _, q := range demoQuotes {
+		if _, ok := strings.CutSuffix(symbol, q); ok {
+			quote = q
+			break
+		}
+	}
+	first, last := time.Now().Add(-demoTradeHistory).Unix()/3600, time.Now().Unix()/3600
+	if fromID > first {
+		first = fromID
+	}
+	trades := make([]Trade, 0)
+	for id := first; id <= last && len(trades) < limit; id++ {
+		p := price * (1 + math.Sin(float64(id)/7)/20)
+		trades = append(trades, Trade{
+			Symbol:          symbol,
+			ID:              id,
+			OrderID:         id,
+			Price:           formatDemo(p),
+			Qty:             formatDemo(quantity),
+			QuoteQty:        formatDemo(p * quantity),
+			Commission:      formatDemo(p * quantity / 1000),
+			CommissionAsset: quote,
+			Time:            id * 3600 * 1000,
+			IsBuyer:         id%3 != 0,
+		})
+	}
+	return trades, nil
+}
@@ -0,0 +1,271 @@
+package binance
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type (
+	// FlexibleProducts is one page of Simple Earn flexible products
+	FlexibleProducts struct {
+		Rows  []FlexibleProduct `json:"rows"`
+		Total int               `json:"total"`
+	}
+	FlexibleProduct struct {
+		Asset                      string             `json:"asset"`
+		ProductID                  string             `json:"productId"`
+		LatestAnnualPercentageRate string             `json:"latestAnnualPercentageRate"` // 0.05 is 5%
+		TierAnnualPercentageRate   map[string]float64 `json:"tierAnnualPercentageRate"`   // Bonus rate by holding tier, e.g. 0-5BTC
+		CanPurchase                bool               `json:"canPurchase"`
+		IsSoldOut                  bool               `json:"isSoldOut"`
+	}
+)
+
+// GetFlexibleProducts returns the Simple Earn flexible products of the asset
+func (c *Client) GetFlexibleProducts(ctx context.Context, asset string) (FlexibleProducts, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetFlexibleProducts")
+	defer span.End()
+
+	products := FlexibleProducts{}
+	err := c.getSigned(ctx, "sapi/v1/simple-earn/flexible/list", url.Values{"asset": {asset}, "size": {"100"}}, &products)
+	return products, err
+}
+
+type (
+	// FlexiblePosition is a Simple Earn flexible subscription, BNB Vault holdings are flexible BNB since the merge
+	FlexiblePosition struct {
+		Asset                      string `json:"asset"`
+		ProductID                  string `json:"productId"`
+		TotalAmount                string `json:"totalAmount"`
+		LatestAnnualPercentageRate string `json:"latestAnnualPercentageRate"`
+		CumulativeTotalRewards     string `json:"cumulativeTotalRewards"`
+		CanRedeem                  bool   `json:"canRedeem"`
+		AutoSubscribe              bool   `json:"autoSubscribe"` // Idle spot and funding balances of the asset get swept into the product
+	}
+	// LockedPosition is a Simple Earn locked subscription, Launchpool stakes are drawn from these and flexible ones
+	LockedPosition struct {
+		PositionID     int64  `json:"positionId"`
+		ProjectID      string `json:"projectId"`
+		Asset          string `json:"asset"`
+		Amount         string `json:"amount"`
+		RewardAsset    string `json:"rewardAsset"`
+		RewardAmt      string `json:"rewardAmt"` // Rewards accrued so far
+		APY            string `json:"APY"`
+		Duration       string `json:"duration"`       // Days
+		RewardsEndDate string `json:"rewardsEndDate"` // Unix milliseconds the position matures at
+		Status         string `json:"status"`
+	}
+)
+
+// earnPageSize is the largest page the Simple Earn position endpoints return
+const earnPageSize = 100
+
+// GetFlexiblePositions returns all Simple Earn flexible positions of the account
+func (c *Client) GetFlexiblePositions(ctx context.Context) ([]FlexiblePosition, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetFlexiblePositions")
+	defer span.End()
+
+	positions := make([]FlexiblePosition, 0)
+	for page := 1; ; page++ {
+		res := struct {
+			Rows  []FlexiblePosition `json:"rows"`
+			Total int                `json:"total"`
+		}{}
+		if err := c.getSigned(ctx, "sapi/v1/simple-earn/flexible/position", earnPage(page), &res); err != nil {
+			return nil, err
+		}
+		positions = append(positions, res.Rows...)
+		if len(res.Rows) < earnPageSize || len(positions) >= res.Total {
+			return positions, nil
+		}
+	}
+}
+
+// GetLockedPositions returns all Simple Earn locked positions of the account
+func (c *Client) GetLockedPositions(ctx context.Context) ([]LockedPosition, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetLockedPositions")
+	defer span.End()
+
+	positions := make([]LockedPosition, 0)
+	for page := 1; ; page++ {
+		res := struct {
+			Rows  []LockedPosition `json:"rows"`
+			Total int              `json:"total"`
+		}{}
+		if err := c.getSigned(ctx, "sapi/v1/simple-earn/locked/position", earnPage(page), &res); err != nil {
+			return nil, err
+		}
+		positions = append(positions, res.Rows...)
+		if len(res.Rows) < earnPageSize || len(positions) >= res.Total {
+			return positions, nil
+		}
+	}
+}
+
+// FlexibleSubscription is a subscription to a Simple Earn flexible product, made by hand or by auto-subscribe
+type FlexibleSubscription struct {
+	Asset          string `json:"asset"`
+	Amount         string `json:"amount"`
+	Time           int64  `json:"time"` // Unix milliseconds
+	PurchaseID     int64  `json:"purchaseId"`
+	ProductID      string `json:"productId"`
+	Type           string `json:"type"`           // AUTO or NORMAL
+	AmtFromSpot    string `json:"amtFromSpot"`    // Part of the amount taken from the spot wallet
+	AmtFromFunding string `json:"amtFromFunding"` // Part of the amount taken from the funding wallet
+	Status         string `json:"status"`
+}
+
+// GetFlexibleSubscriptions returns the Simple Earn flexible subscriptions made between start and end
+func (c *Client) GetFlexibleSubscriptions(ctx context.Context, start, end time.Time) ([]FlexibleSubscription, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetFlexibleSubscriptions")
+	defer span.End()
+
+	subscriptions := make([]FlexibleSubscription, 0)
+	for page := 1; ; page++ {
+		res := struct {
+			Rows  []FlexibleSubscription `json:"rows"`
+			Total int                    `json:"total"`
+		}{}
+		query := earnPage(page)
+		query.Set("startTime", strconv.FormatInt(start.UnixMilli(), 10))
+		query.Set("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+		if err := c.getSigned(ctx, "sapi/v1/simple-earn/flexible/history/subscriptionRecord", query, &res); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, res.Rows...)
+		if len(res.Rows) < earnPageSize || len(subscriptions) >= res.Total {
+			return subscriptions, nil
+		}
+	}
+}
+
+type (
+	// FlexibleReward is a reward paid for a Simple Earn flexible position
+	FlexibleReward struct {
+		Asset     string `json:"asset"`
+		Rewards   string `json:"rewards"`
+		ProjectID string `json:"projectId"`
+		Type      string `json:"type"` // BONUS for the tiered APR, REALTIME for the base APR
+		Time      int64  `json:"time"` // Unix milliseconds
+	}
+	// LockedReward is a reward paid for a Simple Earn locked position
+	LockedReward struct {
+		PositionID int64  `json:"positionId"`
+		Asset      string `json:"asset"`
+		Amount     string `json:"amount"`
+		LockPeriod string `json:"lockPeriod"`
+		Type       string `json:"type"`
+		Time       int64  `json:"time"` // Unix milliseconds
+	}
+)
+
+// earnHistoryWindow is the longest time range the Simple Earn histories can be queried for at once
+const earnHistoryWindow = 30 * 24 * time.Hour
+
+// GetFlexibleRewards returns the Simple Earn flexible rewards of the type, BONUS or REALTIME, paid between start and end
+func (c *Client) GetFlexibleRewards(ctx context.Context, rewardType string, start, end time.Time) ([]FlexibleReward, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetFlexibleRewards", attribute.String("type", rewardType))
+	defer span.End()
+
+	rewards := make([]FlexibleReward, 0)
+	for from := start; from.Before(end); from = from.Add(earnHistoryWindow) {
+		until := from.Add(earnHistoryWindow)
+		if until.After(end) {
+			until = end
+		}
+		for page := 1; ; page++ {
+			res := struct {
+				Rows  []FlexibleReward `json:"rows"`
+				Total int              `json:"total"`
+			}{}
+			query := earnPage(page)
+			query.Set("type", rewardType)
+			query.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+			query.Set("endTime", strconv.FormatInt(until.UnixMilli(), 10))
+			if err := c.getSigned(ctx, "sapi/v1/simple-earn/flexible/history/rewardsRecord", query, &res); err != nil {
+				return nil, err
+			}
+			rewards = append(rewards, res.Rows...)
+			if len(res.Rows) < earnPageSize || page*earnPageSize >= res.Total {
+				break
+			}
+		}
+	}
+	return rewards, nil
+}
+
+// GetLockedRewards returns the Simple Earn locked rewards paid between start and end
+func (c *Client) GetLockedRewards(ctx context.Context, start, end time.Time) ([]LockedReward, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetLockedRewards")
+	defer span.End()
+
+	rewards := make([]LockedReward, 0)
+	for from := start; from.Before(end); from = from.Add(earnHistoryWindow) {
+		until := from.Add(earnHistoryWindow)
+		if until.After(end) {
+			until = end
+		}
+		for page := 1; ; page++ {
+			res := struct {
+				Rows  []LockedReward `json:"rows"`
+				Total int            `json:"total"`
+			}{}
+			query := earnPage(page)
+			query.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+			query.Set("endTime", strconv.FormatInt(until.UnixMilli(), 10))
+			if err := c.getSigned(ctx, "sapi/v1/simple-earn/locked/history/rewardsRecord", query, &res); err != nil {
+				return nil, err
+			}
+			rewards = append(rewards, res.Rows...)
+			if len(res.Rows) < earnPageSize || page*earnPageSize >= res.Total {
+				break
+			}
+		}
+	}
+	return rewards, nil
+}
+
+func earnPage(page int) url.Values {
+	return url.Values{"current": {strconv.Itoa(page)}, "size": {strconv.Itoa(earnPageSize)}}
+}
+
+// DualInvestment is a subscribed Dual Investment product, settled in ExercisedCoin if the strike is hit
+type DualInvestment struct {
+	ID                 string `json:"id"`
+	InvestCoin         string `json:"investCoin"`
+	ExercisedCoin      string `json:"exercisedCoin"`
+	SubscriptionAmount string `json:"subscriptionAmount"`
+	StrikePrice        string `json:"strikePrice"`
+	Duration           string `json:"duration"`   // Days
+	SettleDate         int64  `json:"settleDate"` // Unix milliseconds
+	PurchaseStatus     string `json:"purchaseStatus"`
+	APR                string `json:"apr"`
+	OptionType         string `json:"optionType"` // CALL sells the invested coin above the strike, PUT buys below it
+}
+
+// GetDualInvestments returns the Dual Investment positions waiting for settlement
+func (c *Client) GetDualInvestments(ctx context.Context) ([]DualInvestment, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetDualInvestments")
+	defer span.End()
+
+	positions := make([]DualInvestment, 0)
+	for page := 1; ; page++ {
+		res := struct {
+			Total int              `json:"total"`
+			List  []DualInvestment `json:"list"`
+		}{}
+		query := url.Values{"status": {"PURCHASE_SUCCESS"}, "pageSize": {strconv.Itoa(earnPageSize)}, "pageIndex": {strconv.Itoa(page)}}
+		if err := c.getSigned(ctx, "sapi/v1/dci/product/positions", query, &res); err != nil {
+			return nil, err
+		}
+		positions = append(positions, res.List...)
+		if len(res.List) < earnPageSize || len(positions) >= res.Total {
+			return positions, nil
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package binance
+
+import "context"
+
+type endpointRecorderKey struct{}
+
+// WithEndpointRecorder makes every request sent with the returned context report the endpoint it requests to record
+func WithEndpointRecorder(ctx context.Context, record func(endpoint string)) context.Context {
+	return context.WithValue(ctx, endpointRecorderKey{}, record)
+}
+
+func recordEndpoint(ctx context.Context, endpoint string) {
+	if record, ok := ctx.Value(endpointRecorderKey{}).(func(string)); ok {
+		record(endpoint)
+	}
+}
@@ -0,0 +1,127 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+/**
+Binance error code reference. (https://binance-docs.github.io/apidocs/spot/en/#error-codes)
+
+Every code the exporter knows how to react to maps to a sentinel error so callers can use errors.Is on an APIError,
+plus a remediation hint for the logs and whether a retry has any chance of succeeding.
+*/
+
+const (
+	ErrCodeUnknown          = -1000  // An unknown error occurred while processing the request
+	ErrCodeDisconnected     = -1001  // Internal error; unable to process your request
+	ErrCodeUnauthorized     = -1002  // You are not authorized to execute this request
+	ErrCodeTooManyRequests  = -1003  // Too much request weight used
+	ErrCodeUnexpectedResp   = -1006  // An unexpected response was received from the message bus
+	ErrCodeTimeout          = -1007  // Timeout waiting for response from backend server
+	ErrCodeServerBusy       = -1008  // Server is currently overloaded with other requests
+	ErrCodeInvalidTimestamp = -1021  // Timestamp for this request is outside of the recvWindow
+	ErrCodeInvalidSignature = -1022  // Signature for this request is not valid
+	ErrCodeBadSymbol        = -1121  // Invalid symbol
+	ErrCodeBadAPIKeyFormat  = -2014  // API-key format invalid
+	ErrCodeRejectedMbxKey   = -2015  // Invalid API-key, IP, or permissions for action
+	ErrCodeNoMarginAccount  = -3003  // Margin account does not exist
+	ErrCodeNoIsolatedMargin = -11001 // Isolated margin account does not exist
+)
+
+var (
+	ErrUnknown          = errors.New("unknown binance error")
+	ErrDisconnected     = errors.New("binance internal error")
+	ErrUnauthorized     = errors.New("not authorized")
+	ErrTooManyRequests  = errors.New("rate limited")
+	ErrUnexpectedResp   = errors.New("unexpected response from binance backend")
+	ErrTimeout          = errors.New("binance backend timeout")
+	ErrServerBusy       = errors.New("binance server busy")
+	ErrInvalidTimestamp = errors.New("timestamp outside of recvWindow")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrBadSymbol        = errors.New("invalid symbol")
+	ErrBadAPIKeyFormat  = errors.New("invalid api key format")
+	ErrRejectedMbxKey   = errors.New("invalid api key, ip or permissions")
+	ErrNoMarginAccount  = errors.New("margin account does not exist")
+	ErrNoIsolatedMargin = errors.New("isolated margin account does not exist")
+)
+
+type errorInfo struct {
+	err         error
+	remediation string
+	retryable   bool   // A later attempt of the same request may succeed
+	disable     string // Non empty if the key will never be allowed to call the endpoint, used as reason label
+}
+
+var catalogue = map[int]errorInfo{
+	ErrCodeUnknown:          {err: ErrUnknown, retryable: true, remediation: "Transient binance error, retried automatically."},
+	ErrCodeDisconnected:     {err: ErrDisconnected, retryable: true, remediation: "Transient binance error, retried automatically."},
+	ErrCodeUnauthorized:     {err: ErrUnauthorized, disable: "unauthorized", remediation: "Enable the permission required by this endpoint on the API key."},
+	ErrCodeTooManyRequests:  {err: ErrTooManyRequests, remediation: "Raise EXPORTER_POLL_INTERVAL or check for other consumers of the same key or IP."},
+	ErrCodeUnexpectedResp:   {err: ErrUnexpectedResp, retryable: true, remediation: "Transient binance error, retried automatically."},
+	ErrCodeTimeout:          {err: ErrTimeout, retryable: true, remediation: "Transient binance error, retried automatically."},
+	ErrCodeServerBusy:       {err: ErrServerBusy, retryable: true, remediation: "Binance is overloaded, retried automatically."},
+	ErrCodeInvalidTimestamp: {err: ErrInvalidTimestamp, retryable: true, remediation: "Local clock is off, synchronize it with NTP."},
+	ErrCodeInvalidSignature: {err: ErrInvalidSignature, remediation: "Check that B_PRIVATE_KEY is the secret belonging to B_PUBLIC_KEY."},
+	ErrCodeBadSymbol:        {err: ErrBadSymbol, remediation: "Check EXPORTER_WATCHLIST for symbols binance doesn't list."},
+	ErrCodeBadAPIKeyFormat:  {err: ErrBadAPIKeyFormat, remediation: "Check B_PUBLIC_KEY for typos or surrounding whitespace."},
+	ErrCodeNoMarginAccount:  {err: ErrNoMarginAccount, disable: "no_margin_account", remediation: "Open a margin account or ignore, the margin collector stays off."},
+	ErrCodeNoIsolatedMargin: {err: ErrNoIsolatedMargin, disable: "no_isolated_margin_account", remediation: "Open an isolated margin pair or ignore, isolated pairs are skipped."},
+	ErrCodeRejectedMbxKey:   {err: ErrRejectedMbxKey, disable: "invalid_key_ip_or_permissions", remediation: "Check the key permissions and IP whitelist in the binance API management page."},
+}
+
+var apiErrors = prometheus.NewCounterVec("binance_api_errors_total", "Errors returned by the binance API by error code", "code")
+
+func init() {
+	prometheus.Default.MustRegister(apiErrors)
+}
+
+/*
+APIError is the error body returned by binance for any non 2xx response
+*/
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+	Status  int    `json:"-"` // HTTP status code of the response
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("binance api error %d: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the sentinel error for known codes, so errors.Is(err, ErrInvalidTimestamp) works on an APIError
+func (e *APIError) Unwrap() error {
+	return catalogue[e.Code].err
+}
+
+// Remediation returns a human readable hint on how to fix the error, empty for unknown codes
+func (e *APIError) Remediation() string {
+	return catalogue[e.Code].remediation
+}
+
+// Retryable reports whether repeating the request may succeed. Unknown codes are retried only on 5xx responses.
+func (e *APIError) Retryable() bool {
+	if info, ok := catalogue[e.Code]; ok {
+		return info.retryable
+	}
+	return e.Status >= 500
+}
+
+/*
+DisableReason returns a short reason if the error means that the key will never be allowed to call the endpoint,
+in which case there is no point in retrying. Empty string is returned for any other error.
+*/
+func (e *APIError) DisableReason() string {
+	return catalogue[e.Code].disable
+}
+
+// codeLabel is the code label of binance_api_errors_total, responses without a binance code use the http status
+func (e *APIError) codeLabel() string {
+	if e.Code == 0 {
+		return "http_" + strconv.Itoa(e.Status)
+	}
+	return strconv.Itoa(e.Code)
+}
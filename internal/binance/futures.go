@@ -0,0 +1,140 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type (
+	// PositionRisk is an USDⓈ-M futures position, symbols without a position are returned with a zero PositionAmt
+	PositionRisk struct {
+		Symbol           string `json:"symbol"`
+		PositionSide     string `json:"positionSide"` // BOTH in one-way mode, LONG or SHORT in hedge mode
+		PositionAmt      string `json:"positionAmt"`  // Negative for short positions
+		EntryPrice       string `json:"entryPrice"`
+		MarkPrice        string `json:"markPrice"`
+		LiquidationPrice string `json:"liquidationPrice"`
+		UnRealizedProfit string `json:"unRealizedProfit"`
+		Leverage         string `json:"leverage"`
+		MarginType       string `json:"marginType"`
+		IsolatedWallet   string `json:"isolatedWallet"` // Margin of an isolated position
+	}
+
+	// FuturesAccount is the USDⓈ-M futures account, amounts are in USDT
+	FuturesAccount struct {
+		TotalWalletBalance string            `json:"totalWalletBalance"`
+		TotalMarginBalance string            `json:"totalMarginBalance"` // Wallet balance plus unrealized profit
+		TotalMaintMargin   string            `json:"totalMaintMargin"`
+		Positions          []FuturesPosition `json:"positions"`
+	}
+	FuturesPosition struct {
+		Symbol        string `json:"symbol"`
+		PositionSide  string `json:"positionSide"`
+		PositionAmt   string `json:"positionAmt"`
+		InitialMargin string `json:"initialMargin"`
+		MaintMargin   string `json:"maintMargin"`
+		Isolated      bool   `json:"isolated"`
+	}
+
+	// ADLQuantile is the auto-deleveraging queue position of a symbol by position side, from 0 to 4 where 4 goes first
+	ADLQuantile struct {
+		Symbol      string         `json:"symbol"`
+		AdlQuantile map[string]int `json:"adlQuantile"` // BOTH in one-way mode, LONG and SHORT in hedge mode
+	}
+
+	// SymbolConfig is the configured leverage and margin mode of a futures symbol, set with or without a position
+	SymbolConfig struct {
+		Symbol           string `json:"symbol"`
+		MarginType       string `json:"marginType"` // CROSSED or ISOLATED
+		IsAutoAddMargin  bool   `json:"isAutoAddMargin"`
+		Leverage         int    `json:"leverage"`
+		MaxNotionalValue string `json:"maxNotionalValue"` // Largest position the leverage allows, in USDT
+	}
+
+	// InsuranceFund is the balance of the insurance fund covering the liquidations of a group of futures symbols
+	InsuranceFund struct {
+		Symbols []string             `json:"symbols"`
+		Assets  []InsuranceFundAsset `json:"assets"`
+	}
+	InsuranceFundAsset struct {
+		Asset         string `json:"asset"`
+		MarginBalance string `json:"marginBalance"`
+		UpdateTime    int64  `json:"updateTime"`
+	}
+
+	// LongShortRatio is the share of accounts long and short the futures symbol over a period
+	LongShortRatio struct {
+		Symbol         string `json:"symbol"`
+		LongShortRatio string `json:"longShortRatio"`
+		LongAccount    string `json:"longAccount"` // 0.6 is 60% of the accounts
+		ShortAccount   string `json:"shortAccount"`
+		Timestamp      int64  `json:"timestamp"`
+	}
+)
+
+func (c *Client) GetPositionRisk(ctx context.Context) ([]PositionRisk, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetPositionRisk")
+	defer span.End()
+
+	var positions []PositionRisk
+	err := c.getSigned(ctx, "fapi/v2/positionRisk", nil, &positions)
+	return positions, err
+}
+
+func (c *Client) GetFuturesAccount(ctx context.Context) (FuturesAccount, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetFuturesAccount")
+	defer span.End()
+
+	account := FuturesAccount{}
+	err := c.getSigned(ctx, "fapi/v2/account", nil, &account)
+	return account, err
+}
+
+// GetFuturesSymbolConfigs returns the leverage and margin mode configured for every USDⓈ-M futures symbol
+func (c *Client) GetFuturesSymbolConfigs(ctx context.Context) ([]SymbolConfig, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetFuturesSymbolConfigs")
+	defer span.End()
+
+	var configs []SymbolConfig
+	err := c.getSigned(ctx, "fapi/v1/symbolConfig", nil, &configs)
+	return configs, err
+}
+
+// GetInsuranceFund returns the insurance fund covering the liquidations of the futures symbol
+func (c *Client) GetInsuranceFund(ctx context.Context, symbol string) (InsuranceFund, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetInsuranceFund", attribute.String("symbol", symbol))
+	defer span.End()
+
+	fund := InsuranceFund{}
+	err := c.get(ctx, "fapi/v1/insuranceBalance", url.Values{"symbol": {symbol}}, &fund)
+	return fund, err
+}
+
+// GetLongShortRatio returns the latest long/short account ratio of the futures symbol over the period, e.g. 5m or 1h
+func (c *Client) GetLongShortRatio(ctx context.Context, symbol, period string) (LongShortRatio, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetLongShortRatio", attribute.String("symbol", symbol))
+	defer span.End()
+
+	var ratios []LongShortRatio
+	err := c.get(ctx, "futures/data/globalLongShortAccountRatio", url.Values{"symbol": {symbol}, "period": {period}, "limit": {"1"}}, &ratios)
+	if err != nil {
+		return LongShortRatio{}, err
+	}
+	if len(ratios) == 0 {
+		return LongShortRatio{}, fmt.Errorf("no long/short ratio of %s", symbol)
+	}
+	return ratios[len(ratios)-1], nil
+}
+
+func (c *Client) GetADLQuantiles(ctx context.Context) ([]ADLQuantile, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetADLQuantiles")
+	defer span.End()
+
+	var quantiles []ADLQuantile
+	err := c.getSigned(ctx, "fapi/v1/adlQuantile", nil, &quantiles)
+	return quantiles, err
+}
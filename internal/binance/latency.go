@@ -0,0 +1,30 @@
+package binance
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var requestDuration = prometheus.NewHistogramVec("binance_api_request_duration_seconds", "Duration of the requests to the binance API by endpoint, retries count as requests of their own",
+	prometheus.LatencyBuckets, "endpoint")
+
+func init() {
+	prometheus.Default.MustRegister(requestDuration)
+}
+
+/*
+observeDuration records how long the request took. Requests of a sampled trace become the exemplar of their bucket,
+so a slow bucket links to the trace of a request that landed in it.
+*/
+func observeDuration(req *http.Request, start time.Time) {
+	endpoint := strings.TrimPrefix(req.URL.Path, "/")
+	var exemplar []prometheus.Label
+	if sc := trace.SpanContextFromContext(req.Context()); sc.IsSampled() {
+		exemplar = []prometheus.Label{prometheus.L("trace_id", sc.TraceID().String()), prometheus.L("span_id", sc.SpanID().String())}
+	}
+	requestDuration.ObserveWithExemplar(time.Since(start).Seconds(), exemplar, endpoint)
+}
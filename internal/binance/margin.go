@@ -0,0 +1,106 @@
+package binance
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+type (
+	// CrossMarginAccount is the summary of the cross margin account, values are in BTC
+	CrossMarginAccount struct {
+		MarginLevel         string `json:"marginLevel"` // Total assets over total liabilities, liquidation starts at 1.1
+		TotalAssetOfBtc     string `json:"totalAssetOfBtc"`
+		TotalLiabilityOfBtc string `json:"totalLiabilityOfBtc"`
+		TotalNetAssetOfBtc  string `json:"totalNetAssetOfBtc"`
+		TradeEnabled        bool   `json:"tradeEnabled"`
+	}
+
+	// IsolatedMarginAccount holds every isolated margin pair of the account
+	IsolatedMarginAccount struct {
+		Assets             []IsolatedMarginPair `json:"assets"`
+		TotalNetAssetOfBtc string               `json:"totalNetAssetOfBtc"`
+	}
+	IsolatedMarginPair struct {
+		Symbol         string `json:"symbol"`
+		MarginLevel    string `json:"marginLevel"`
+		MarginRatio    string `json:"marginRatio"`
+		IndexPrice     string `json:"indexPrice"`
+		LiquidatePrice string `json:"liquidatePrice"` // 0 while the pair has no debt
+		LiquidateRate  string `json:"liquidateRate"`
+		Enabled        bool   `json:"enabled"`
+	}
+
+	// MarginInterest is interest charged on a margin loan, IsolatedSymbol is empty for the cross margin account
+	MarginInterest struct {
+		TxID           int64  `json:"txId"`
+		Asset          string `json:"asset"`
+		Interest       string `json:"interest"`
+		InterestRate   string `json:"interestRate"`
+		Type           string `json:"type"` // ON_BORROW, PERIODIC, PERIODIC_CONVERTED or ON_BORROW_CONVERTED
+		IsolatedSymbol string `json:"isolatedSymbol"`
+		Time           int64  `json:"interestAccuredTime"` // Unix milliseconds
+	}
+)
+
+const (
+	// interestWindow is the longest time range the margin interest history can be queried for at once
+	interestWindow = 30 * 24 * time.Hour
+	// interestPageSize is the largest page of the margin interest history
+	interestPageSize = 100
+)
+
+func (c *Client) GetCrossMarginAccount(ctx context.Context) (CrossMarginAccount, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetCrossMarginAccount")
+	defer span.End()
+
+	account := CrossMarginAccount{}
+	err := c.getSigned(ctx, "sapi/v1/margin/account", nil, &account)
+	return account, err
+}
+
+func (c *Client) GetIsolatedMarginAccount(ctx context.Context) (IsolatedMarginAccount, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetIsolatedMarginAccount")
+	defer span.End()
+
+	account := IsolatedMarginAccount{}
+	err := c.getSigned(ctx, "sapi/v1/margin/isolated/account", nil, &account)
+	return account, err
+}
+
+// GetMarginInterest returns the interest charged on the margin loans of the account between start and end
+func (c *Client) GetMarginInterest(ctx context.Context, start, end time.Time) ([]MarginInterest, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetMarginInterest")
+	defer span.End()
+
+	interests := make([]MarginInterest, 0)
+	for from := start; from.Before(end); from = from.Add(interestWindow) {
+		until := from.Add(interestWindow)
+		if until.After(end) {
+			until = end
+		}
+		for page := 1; ; page++ {
+			res := struct {
+				Rows  []MarginInterest `json:"rows"`
+				Total int              `json:"total"`
+			}{}
+			query := url.Values{
+				"startTime": {strconv.FormatInt(from.UnixMilli(), 10)},
+				"endTime":   {strconv.FormatInt(until.UnixMilli(), 10)},
+				"current":   {strconv.Itoa(page)},
+				"size":      {strconv.Itoa(interestPageSize)},
+			}
+			if err := c.getSigned(ctx, "sapi/v1/margin/interestHistory", query, &res); err != nil {
+				return nil, err
+			}
+			interests = append(interests, res.Rows...)
+			if len(res.Rows) < interestPageSize || page*interestPageSize >= res.Total {
+				break
+			}
+		}
+	}
+	return interests, nil
+}
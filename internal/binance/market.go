@@ -0,0 +1,152 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+type (
+	// AvgPrice is the average price of a symbol over the last Mins minutes
+	AvgPrice struct {
+		Mins  int    `json:"mins"`
+		Price string `json:"price"`
+	}
+
+	// BookTicker is the best bid and ask of a symbol
+	BookTicker struct {
+		Symbol   string `json:"symbol"`
+		BidPrice string `json:"bidPrice"`
+		BidQty   string `json:"bidQty"`
+		AskPrice string `json:"askPrice"`
+		AskQty   string `json:"askQty"`
+	}
+
+	// Depth is an order book snapshot, every level is a [price, quantity] pair with the best price first
+	Depth struct {
+		LastUpdateID int64       `json:"lastUpdateId"`
+		Bids         [][2]string `json:"bids"`
+		Asks         [][2]string `json:"asks"`
+	}
+
+	// Kline is one candle, binance sends it as a positional array
+	Kline struct {
+		OpenTime  int64
+		Open      string
+		High      string
+		Low       string
+		Close     string
+		Volume    string
+		CloseTime int64
+	}
+
+	// ExchangeInfo holds the trading rules of the requested symbols
+	ExchangeInfo struct {
+		Symbols []SymbolInfo `json:"symbols"`
+	}
+	SymbolInfo struct {
+		Symbol     string         `json:"symbol"`
+		Status     string         `json:"status"` // TRADING, BREAK, HALT, ...
+		BaseAsset  string         `json:"baseAsset"`
+		QuoteAsset string         `json:"quoteAsset"`
+		Filters    []SymbolFilter `json:"filters"`
+	}
+	// SymbolFilter is one trading rule, only the fields of the filter type are set
+	SymbolFilter struct {
+		FilterType string `json:"filterType"`
+		TickSize   string `json:"tickSize,omitempty"` // PRICE_FILTER
+		MinQty     string `json:"minQty,omitempty"`   // LOT_SIZE
+		StepSize   string `json:"stepSize,omitempty"` // LOT_SIZE
+	}
+)
+
+// Filter returns the filter of the given type, e.g. LOT_SIZE, and false if the symbol has none
+func (s SymbolInfo) Filter(filterType string) (SymbolFilter, bool) {
+	for _, f := range s.Filters {
+		if f.FilterType == filterType {
+			return f, true
+		}
+	}
+	return SymbolFilter{}, false
+}
+
+func (k *Kline) UnmarshalJSON(b []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	if len(fields) < 7 {
+		return fmt.Errorf("kline has %d fields, expected at least 7", len(fields))
+	}
+	for i, target := range []interface{}{&k.OpenTime, &k.Open, &k.High, &k.Low, &k.Close, &k.Volume, &k.CloseTime} {
+		if err := json.Unmarshal(fields[i], target); err != nil {
+			return fmt.Errorf("kline field %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) GetAvgPrice(ctx context.Context, symbol string) (AvgPrice, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetAvgPrice")
+	defer span.End()
+
+	price := AvgPrice{}
+	err := c.get(ctx, "api/v3/avgPrice", url.Values{"symbol": {symbol}}, &price)
+	return price, err
+}
+
+// GetBookTickers returns the best bid and ask of all symbols in a single request
+func (c *Client) GetBookTickers(ctx context.Context, symbols []string) ([]BookTicker, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetBookTickers")
+	defer span.End()
+
+	list, err := json.Marshal(symbols)
+	if err != nil {
+		return nil, err
+	}
+	var tickers []BookTicker
+	err = c.get(ctx, "api/v3/ticker/bookTicker", url.Values{"symbols": {string(list)}}, &tickers)
+	return tickers, err
+}
+
+// GetKlines returns the last limit candles of the symbol in the interval, e.g. 1h or 1d, oldest first
+func (c *Client) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetKlines")
+	defer span.End()
+
+	var klines []Kline
+	err := c.get(ctx, "api/v3/klines", url.Values{"symbol": {symbol}, "interval": {interval}, "limit": {strconv.Itoa(limit)}}, &klines)
+	return klines, err
+}
+
+// GetExchangeInfo returns the trading status and rules of the symbols
+func (c *Client) GetExchangeInfo(ctx context.Context, symbols []string) (ExchangeInfo, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetExchangeInfo")
+	defer span.End()
+
+	params := url.Values{}
+	if len(symbols) > 0 {
+		list, err := json.Marshal(symbols)
+		if err != nil {
+			return ExchangeInfo{}, err
+		}
+		params.Set("symbols", string(list))
+	}
+	info := ExchangeInfo{}
+	err := c.get(ctx, "api/v3/exchangeInfo", params, &info)
+	return info, err
+}
+
+// GetDepth returns the order book of the symbol, limited to the best limit levels of each side
+func (c *Client) GetDepth(ctx context.Context, symbol string, limit int) (Depth, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetDepth")
+	defer span.End()
+
+	depth := Depth{}
+	err := c.get(ctx, "api/v3/depth", url.Values{"symbol": {symbol}, "limit": {strconv.Itoa(limit)}}, &depth)
+	return depth, err
+}
@@ -0,0 +1,55 @@
+package binance
+
+import (
+	"context"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+type (
+	// OptionsAccount is the options margin account, options are margined and settled in USDT
+	OptionsAccount struct {
+		Assets    []OptionsAsset `json:"asset"`
+		RiskLevel string         `json:"riskLevel"` // NORMAL, MEDIUM, HIGH or REDUCE_ONLY
+	}
+	OptionsAsset struct {
+		Asset         string `json:"asset"`
+		MarginBalance string `json:"marginBalance"`
+		Equity        string `json:"equity"`    // Margin balance plus the mark value of the positions
+		Available     string `json:"available"` // Funds available for new orders
+		Locked        string `json:"locked"`
+		UnrealizedPNL string `json:"unrealizedPNL"`
+	}
+	// OptionsPosition is an open options position, Side is LONG or SHORT and OptionSide CALL or PUT
+	OptionsPosition struct {
+		Symbol        string `json:"symbol"`
+		Side          string `json:"side"`
+		OptionSide    string `json:"optionSide"`
+		Quantity      string `json:"quantity"`
+		EntryPrice    string `json:"entryPrice"`
+		MarkPrice     string `json:"markPrice"`
+		MarkValue     string `json:"markValue"`
+		UnrealizedPNL string `json:"unrealizedPNL"`
+		StrikePrice   string `json:"strikePrice"`
+		ExpiryDate    int64  `json:"expiryDate"` // Unix milliseconds
+		QuoteAsset    string `json:"quoteAsset"`
+	}
+)
+
+func (c *Client) GetOptionsAccount(ctx context.Context) (OptionsAccount, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetOptionsAccount")
+	defer span.End()
+
+	account := OptionsAccount{}
+	err := c.getSigned(ctx, "eapi/v1/marginAccount", nil, &account)
+	return account, err
+}
+
+func (c *Client) GetOptionsPositions(ctx context.Context) ([]OptionsPosition, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetOptionsPositions")
+	defer span.End()
+
+	var positions []OptionsPosition
+	err := c.getSigned(ctx, "eapi/v1/position", nil, &positions)
+	return positions, err
+}
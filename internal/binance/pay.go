@@ -0,0 +1,108 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+type (
+	// PayTransaction is a Binance Pay transfer, Amount is negative for outgoing payments
+	PayTransaction struct {
+		OrderType       string `json:"orderType"` // PAY, PAY_REFUND, C2C, CRYPTO_BOX, ...
+		TransactionID   string `json:"transactionId"`
+		TransactionTime int64  `json:"transactionTime"` // Unix milliseconds
+		Amount          string `json:"amount"`
+		Currency        string `json:"currency"`
+	}
+	// C2COrder is a P2P order of the account, its status changes until it completes or gets cancelled
+	C2COrder struct {
+		OrderNumber string `json:"orderNumber"`
+		TradeType   string `json:"tradeType"` // BUY or SELL
+		Asset       string `json:"asset"`
+		Fiat        string `json:"fiat"`
+		Amount      string `json:"amount"`     // In Asset
+		TotalPrice  string `json:"totalPrice"` // In Fiat
+		OrderStatus string `json:"orderStatus"`
+		CreateTime  int64  `json:"createTime"` // Unix milliseconds
+	}
+	// payResponse wraps the data of the Pay and C2C endpoints, which report failures in the body
+	payResponse[T any] struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Data    []T    `json:"data"`
+		Total   int    `json:"total"`
+		Success bool   `json:"success"`
+	}
+)
+
+// payPageSize is the largest page the Pay and C2C history endpoints return
+const payPageSize = 100
+
+func (r payResponse[T]) err() error {
+	if r.Success {
+		return nil
+	}
+	return fmt.Errorf("binance pay error %s: %s", r.Code, r.Message)
+}
+
+// GetPayTransactions returns the Binance Pay transactions between start and end
+func (c *Client) GetPayTransactions(ctx context.Context, start, end time.Time) ([]PayTransaction, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetPayTransactions")
+	defer span.End()
+
+	transactions := make([]PayTransaction, 0)
+	until := end.UnixMilli()
+	for {
+		res := payResponse[PayTransaction]{}
+		query := url.Values{
+			"startTime": {strconv.FormatInt(start.UnixMilli(), 10)},
+			"endTime":   {strconv.FormatInt(until, 10)},
+			"limit":     {strconv.Itoa(payPageSize)},
+		}
+		if err := c.getSigned(ctx, "sapi/v1/pay/transactions", query, &res); err != nil {
+			return nil, err
+		}
+		if err := res.err(); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, res.Data...)
+		if len(res.Data) < payPageSize {
+			return transactions, nil
+		}
+		// Newest come first, the next page ends right before the oldest transaction of this one
+		until = res.Data[len(res.Data)-1].TransactionTime - 1
+	}
+}
+
+// GetC2COrders returns the P2P orders of the trade type (BUY or SELL) created between start and end
+func (c *Client) GetC2COrders(ctx context.Context, tradeType string, start, end time.Time) ([]C2COrder, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetC2COrders")
+	defer span.End()
+
+	orders := make([]C2COrder, 0)
+	for page := 1; ; page++ {
+		res := payResponse[C2COrder]{}
+		query := url.Values{
+			"tradeType":      {tradeType},
+			"startTimestamp": {strconv.FormatInt(start.UnixMilli(), 10)},
+			"endTimestamp":   {strconv.FormatInt(end.UnixMilli(), 10)},
+			"page":           {strconv.Itoa(page)},
+			"rows":           {strconv.Itoa(payPageSize)},
+		}
+		if err := c.getSigned(ctx, "sapi/v1/c2c/orderMatch/listUserOrderHistory", query, &res); err != nil {
+			return nil, err
+		}
+		if err := res.err(); err != nil {
+			return nil, err
+		}
+		orders = append(orders, res.Data...)
+		if len(res.Data) < payPageSize || len(orders) >= res.Total {
+			return orders, nil
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package binance
+
+import (
+	"context"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+type (
+	// PortfolioAccount is the Portfolio Margin account, amounts are in USD
+	PortfolioAccount struct {
+		UniMMR                   string `json:"uniMMR"`        // Unified maintenance margin ratio, liquidation starts at 1.05
+		AccountEquity            string `json:"accountEquity"` // Equity with the collateral rates applied
+		ActualEquity             string `json:"actualEquity"`  // Equity without the collateral rates
+		AccountInitialMargin     string `json:"accountInitialMargin"`
+		AccountMaintMargin       string `json:"accountMaintMargin"`
+		AccountStatus            string `json:"accountStatus"` // NORMAL, MARGIN_CALL, SUPPLY_MARGIN, REDUCE_ONLY, ACTIVE_LIQUIDATION, FORCE_LIQUIDATION or BANKRUPTED
+		VirtualMaxWithdrawAmount string `json:"virtualMaxWithdrawAmount"`
+		TotalAvailableBalance    string `json:"totalAvailableBalance"`
+	}
+	// PortfolioBalance is an asset of the Portfolio Margin account across its cross margin, UM and CM futures wallets
+	PortfolioBalance struct {
+		Asset               string `json:"asset"`
+		TotalWalletBalance  string `json:"totalWalletBalance"`
+		CrossMarginAsset    string `json:"crossMarginAsset"`
+		CrossMarginBorrowed string `json:"crossMarginBorrowed"`
+		CrossMarginFree     string `json:"crossMarginFree"`
+		CrossMarginInterest string `json:"crossMarginInterest"`
+		CrossMarginLocked   string `json:"crossMarginLocked"`
+		UMWalletBalance     string `json:"umWalletBalance"`
+		UMUnrealizedPNL     string `json:"umUnrealizedPNL"`
+		CMWalletBalance     string `json:"cmWalletBalance"`
+		CMUnrealizedPNL     string `json:"cmUnrealizedPNL"`
+		NegativeBalance     string `json:"negativeBalance"`
+	}
+)
+
+func (c *Client) GetPortfolioAccount(ctx context.Context) (PortfolioAccount, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetPortfolioAccount")
+	defer span.End()
+
+	account := PortfolioAccount{}
+	err := c.getSigned(ctx, "papi/v1/account", nil, &account)
+	return account, err
+}
+
+func (c *Client) GetPortfolioBalances(ctx context.Context) ([]PortfolioBalance, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetPortfolioBalances")
+	defer span.End()
+
+	var balances []PortfolioBalance
+	err := c.getSigned(ctx, "papi/v1/balance", nil, &balances)
+	return balances, err
+}
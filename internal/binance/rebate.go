@@ -0,0 +1,98 @@
+package binance
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+type (
+	// SpotRebate is a spot commission rebate or referral kickback paid to the account
+	SpotRebate struct {
+		Asset      string `json:"asset"`
+		Type       int    `json:"type"` // 1 is a commission rebate, 2 a referral kickback
+		Amount     string `json:"amount"`
+		UpdateTime int64  `json:"updateTime"` // Unix milliseconds
+	}
+	// FuturesIncome is a record of the USDⓈ-M futures income history
+	FuturesIncome struct {
+		Symbol     string `json:"symbol"`
+		IncomeType string `json:"incomeType"` // REFERRAL_KICKBACK, COMMISSION_REBATE, FUNDING_FEE, ...
+		Income     string `json:"income"`
+		Asset      string `json:"asset"`
+		Time       int64  `json:"time"` // Unix milliseconds
+		TranID     int64  `json:"tranId"`
+	}
+)
+
+const (
+	// rebateWindow is the longest time range the spot rebate history can be queried for at once
+	rebateWindow = 30 * 24 * time.Hour
+	// incomePageSize is the largest page of the futures income history
+	incomePageSize = 1000
+)
+
+// GetSpotRebates returns the spot rebates paid between start and end
+func (c *Client) GetSpotRebates(ctx context.Context, start, end time.Time) ([]SpotRebate, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetSpotRebates")
+	defer span.End()
+
+	rebates := make([]SpotRebate, 0)
+	for from := start; from.Before(end); from = from.Add(rebateWindow) {
+		until := from.Add(rebateWindow)
+		if until.After(end) {
+			until = end
+		}
+		for page := 1; ; page++ {
+			res := struct {
+				Data struct {
+					Page         int          `json:"page"`
+					TotalPageNum int          `json:"totalPageNum"`
+					Data         []SpotRebate `json:"data"`
+				} `json:"data"`
+			}{}
+			query := url.Values{
+				"startTime": {strconv.FormatInt(from.UnixMilli(), 10)},
+				"endTime":   {strconv.FormatInt(until.UnixMilli(), 10)},
+				"page":      {strconv.Itoa(page)},
+			}
+			if err := c.getSigned(ctx, "sapi/v1/rebate/taxQuery", query, &res); err != nil {
+				return nil, err
+			}
+			rebates = append(rebates, res.Data.Data...)
+			if page >= res.Data.TotalPageNum {
+				break
+			}
+		}
+	}
+	return rebates, nil
+}
+
+// GetFuturesIncome returns the futures income of the type between start and end, oldest first
+func (c *Client) GetFuturesIncome(ctx context.Context, incomeType string, start, end time.Time) ([]FuturesIncome, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetFuturesIncome")
+	defer span.End()
+
+	incomes := make([]FuturesIncome, 0)
+	from := start.UnixMilli()
+	for {
+		var page []FuturesIncome
+		query := url.Values{
+			"incomeType": {incomeType},
+			"startTime":  {strconv.FormatInt(from, 10)},
+			"endTime":    {strconv.FormatInt(end.UnixMilli(), 10)},
+			"limit":      {strconv.Itoa(incomePageSize)},
+		}
+		if err := c.getSigned(ctx, "fapi/v1/income", query, &page); err != nil {
+			return nil, err
+		}
+		incomes = append(incomes, page...)
+		if len(page) < incomePageSize {
+			return incomes, nil
+		}
+		from = page[len(page)-1].Time + 1
+	}
+}
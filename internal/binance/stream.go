@@ -0,0 +1,123 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/net/websocket"
+)
+
+// streamDialTimeout is the time connecting to a market stream may take
+const streamDialTimeout = 10 * time.Second
+
+type (
+	// Liquidation is a forced order of a futures position, binance streams the largest one per symbol and second
+	Liquidation struct {
+		Symbol       string `json:"s"`
+		Side         string `json:"S"` // SELL liquidates a long position, BUY a short one
+		Quantity     string `json:"q"`
+		AveragePrice string `json:"ap"`
+		Status       string `json:"X"`
+		Time         int64  `json:"T"`
+	}
+
+	// streamMessage is the envelope of a combined stream, data is the event of the named stream
+	streamMessage struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}
+
+	// bookTickerEvent is a book ticker update with the short field names of the streams
+	bookTickerEvent struct {
+		Symbol   string `json:"s"`
+		BidPrice string `json:"b"`
+		BidQty   string `json:"B"`
+		AskPrice string `json:"a"`
+		AskQty   string `json:"A"`
+	}
+)
+
+// StreamLiquidations calls handle with every liquidation of the futures symbols until the context ends or the
+// connection drops, binance drops every connection after 24 hours
+func (c *Client) StreamLiquidations(ctx context.Context, symbols []string, handle func(Liquidation)) error {
+	ctx, span := tracing.Start(ctx, "binance.StreamLiquidations", attribute.StringSlice("symbols", symbols))
+	defer span.End()
+
+	names := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		names = append(names, strings.ToLower(symbol)+"@forceOrder")
+	}
+	return c.stream(ctx, c.futuresWS, names, func(data json.RawMessage) error {
+		event := struct {
+			Order Liquidation `json:"o"`
+		}{}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return err
+		}
+		handle(event.Order)
+		return nil
+	})
+}
+
+// StreamBookTickers calls handle with every change of the best bid or ask of the spot symbols until the context ends
+// or the connection drops
+func (c *Client) StreamBookTickers(ctx context.Context, symbols []string, handle func(BookTicker)) error {
+	ctx, span := tracing.Start(ctx, "binance.StreamBookTickers", attribute.StringSlice("symbols", symbols))
+	defer span.End()
+
+	names := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		names = append(names, strings.ToLower(symbol)+"@bookTicker")
+	}
+	return c.stream(ctx, c.spotWS, names, func(data json.RawMessage) error {
+		event := bookTickerEvent{}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return err
+		}
+		handle(BookTicker{Symbol: event.Symbol, BidPrice: event.BidPrice, BidQty: event.BidQty, AskPrice: event.AskPrice, AskQty: event.AskQty})
+		return nil
+	})
+}
+
+// stream connects to the combined market streams of the host and calls handle with the data of every event until it
+// fails or the context ends
+func (c *Client) stream(ctx context.Context, host string, names []string, handle func(data json.RawMessage) error) error {
+	cfg, err := websocket.NewConfig(host+"/stream?streams="+strings.Join(names, "/"), "http://localhost/")
+	if err != nil {
+		return err
+	}
+	cfg.Header.Set("User-Agent", c.userAgent)
+	cfg.Dialer = &net.Dialer{Timeout: streamDialTimeout}
+	ws, err := websocket.DialConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the market stream: %w", err)
+	}
+	// Receiving blocks until the next event, closing the connection ends it once the context is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		ws.Close()
+	}()
+	for {
+		msg := streamMessage{}
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("market stream dropped: %w", err)
+		}
+		if err := handle(msg.Data); err != nil {
+			return fmt.Errorf("invalid event of the market stream %s: %w", msg.Stream, err)
+		}
+	}
+}
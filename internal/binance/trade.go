@@ -0,0 +1,38 @@
+package binance
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+// Trade is a spot trade of the account
+type Trade struct {
+	Symbol          string `json:"symbol"`
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"orderId"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"` // Unix milliseconds
+	IsBuyer         bool   `json:"isBuyer"`
+	IsMaker         bool   `json:"isMaker"`
+}
+
+// MaxTradesPerPage is the largest page of the trade history
+const MaxTradesPerPage = 1000
+
+// GetMyTrades returns up to limit trades of the symbol with an id of at least fromID, oldest first
+func (c *Client) GetMyTrades(ctx context.Context, symbol string, fromID int64, limit int) ([]Trade, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetMyTrades")
+	defer span.End()
+
+	var trades []Trade
+	query := url.Values{"symbol": {symbol}, "fromId": {strconv.FormatInt(fromID, 10)}, "limit": {strconv.Itoa(limit)}}
+	err := c.getSigned(ctx, "api/v3/myTrades", query, &trades)
+	return trades, err
+}
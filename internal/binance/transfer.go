@@ -0,0 +1,51 @@
+package binance
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+)
+
+// UniversalTransfer is a transfer between two wallets of the account
+type UniversalTransfer struct {
+	Asset     string `json:"asset"`
+	Amount    string `json:"amount"`
+	Type      string `json:"type"`   // Source and target wallet, like MAIN_FUNDING
+	Status    string `json:"status"` // CONFIRMED, FAILED or PENDING
+	TranID    int64  `json:"tranId"`
+	Timestamp int64  `json:"timestamp"` // Unix milliseconds
+}
+
+// transferPageSize is the largest page of the universal transfer history
+const transferPageSize = 100
+
+// GetUniversalTransfers returns the transfers of the type between start and end
+func (c *Client) GetUniversalTransfers(ctx context.Context, transferType string, start, end time.Time) ([]UniversalTransfer, error) {
+	ctx, span := tracing.Start(ctx, "binance.GetUniversalTransfers")
+	defer span.End()
+
+	transfers := make([]UniversalTransfer, 0)
+	for page := 1; ; page++ {
+		res := struct {
+			Total int                 `json:"total"`
+			Rows  []UniversalTransfer `json:"rows"`
+		}{}
+		query := url.Values{
+			"type":      {transferType},
+			"startTime": {strconv.FormatInt(start.UnixMilli(), 10)},
+			"endTime":   {strconv.FormatInt(end.UnixMilli(), 10)},
+			"current":   {strconv.Itoa(page)},
+			"size":      {strconv.Itoa(transferPageSize)},
+		}
+		if err := c.getSigned(ctx, "sapi/v1/asset/transfer", query, &res); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, res.Rows...)
+		if len(res.Rows) < transferPageSize || len(transfers) >= res.Total {
+			return transfers, nil
+		}
+	}
+}
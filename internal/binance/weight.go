@@ -0,0 +1,46 @@
+package binance
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+// usedWeightHeader carries the request weight the IP used in the current minute
+const usedWeightHeader = "X-Mbx-Used-Weight-1m"
+
+var usedWeight = prometheus.NewGaugeVec("binance_api_used_weight", "Request weight used in the current minute as reported by the spot API, including other consumers of the same IP")
+
+func init() {
+	prometheus.Default.MustRegister(usedWeight)
+}
+
+/*
+recordWeight keeps the used weight binance reported with the response. Futures, Portfolio Margin and options have their own
+limits and are left out, so the weight always refers to the spot API limit.
+*/
+func (c *Client) recordWeight(res *http.Response) {
+	path := strings.TrimPrefix(res.Request.URL.Path, "/")
+	if strings.HasPrefix(path, "fapi/") || strings.HasPrefix(path, "papi/") || strings.HasPrefix(path, "eapi/") {
+		return
+	}
+	weight, err := strconv.ParseInt(res.Header.Get(usedWeightHeader), 10, 64)
+	if err != nil {
+		return
+	}
+	c.weight.Store(weight)
+	usedWeight.Set(float64(weight))
+}
+
+// UsedWeight returns the request weight used in the current minute, false until binance reported it
+func (c *Client) UsedWeight() (int, bool) {
+	weight := c.weight.Load()
+	return int(weight), weight >= 0
+}
+
+// UsedWeight is unknown in demo mode, nothing is requested from binance
+func (d *DemoClient) UsedWeight() (int, bool) {
+	return 0, false
+}
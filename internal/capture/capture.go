@@ -0,0 +1,126 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/mockserver"
+)
+
+// redactedParams are dropped from the captured query strings
+var redactedParams = []string{"signature"}
+
+type (
+	/*
+		Buffer keeps the last outbound requests of every endpoint with their responses, so an empty metric can be traced
+		back to what binance answered without a packet capture. Response bodies are scrubbed like recorded fixtures and
+		truncated, query strings lose their signature and no request headers are kept.
+	*/
+	Buffer struct {
+		size      int // Exchanges kept per endpoint
+		bodyLimit int // Bytes of every response body kept
+		lock      sync.Mutex
+		endpoints map[string][]Exchange // By method and path, oldest first
+	}
+	// Exchange is one captured request and its response
+	Exchange struct {
+		Time      time.Time       `json:"time"`
+		Method    string          `json:"method"`
+		URL       string          `json:"url"`
+		Status    int             `json:"status,omitempty"` // 0 if no response arrived
+		Duration  float64         `json:"duration_seconds"`
+		Error     string          `json:"error,omitempty"`
+		Body      json.RawMessage `json:"body,omitempty"`
+		Truncated bool            `json:"truncated,omitempty"`
+	}
+)
+
+// Default captures the requests of the binance client, nil while EXPORTER_DEBUG_REQUESTS is 0
+var Default *Buffer
+
+// New creates a buffer of size exchanges per endpoint, keeping bodyLimit bytes of every response
+func New(size, bodyLimit int) *Buffer {
+	return &Buffer{size: size, bodyLimit: bodyLimit, endpoints: make(map[string][]Exchange)}
+}
+
+// Wrap returns a http.RoundTripper capturing every request it passes on to next
+func (b *Buffer) Wrap(next http.RoundTripper) http.RoundTripper {
+	return roundTripper{buffer: b, next: next}
+}
+
+// Exchanges returns a copy of the captured exchanges by endpoint
+func (b *Buffer) Exchanges() map[string][]Exchange {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	res := make(map[string][]Exchange, len(b.endpoints))
+	for endpoint, exchanges := range b.endpoints {
+		res[endpoint] = append([]Exchange(nil), exchanges...)
+	}
+	return res
+}
+
+func (b *Buffer) add(endpoint string, e Exchange) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	exchanges := append(b.endpoints[endpoint], e)
+	if len(exchanges) > b.size {
+		exchanges = exchanges[len(exchanges)-b.size:]
+	}
+	b.endpoints[endpoint] = exchanges
+}
+
+type roundTripper struct {
+	buffer *Buffer
+	next   http.RoundTripper
+}
+
+/*
+RoundTrip reads the whole response body while capturing, scrubbing needs the complete json, and hands a copy of it on
+to the caller.
+*/
+func (rt roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	e := Exchange{Time: time.Now().UTC(), Method: req.Method, URL: redactURL(req.URL)}
+	endpoint := req.Method + " " + req.URL.Path
+	res, err := rt.next.RoundTrip(req)
+	e.Duration = time.Since(e.Time).Seconds()
+	if err != nil {
+		e.Error = err.Error()
+		rt.buffer.add(endpoint, e)
+		return res, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	e.Status = res.StatusCode
+	if err != nil {
+		e.Error = err.Error()
+	}
+	e.Body, e.Truncated = rt.buffer.truncate(mockserver.Scrub(body))
+	rt.buffer.add(endpoint, e)
+	return res, nil
+}
+
+// truncate cuts the scrubbed body down to the body limit, a cut body is kept as a json string
+func (b *Buffer) truncate(body json.RawMessage) (json.RawMessage, bool) {
+	if len(body) <= b.bodyLimit {
+		return body, false
+	}
+	s, _ := json.Marshal(string(body[:b.bodyLimit]))
+	return s, true
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	query := c.Query()
+	for _, p := range redactedParams {
+		query.Del(p)
+	}
+	c.RawQuery = query.Encode()
+	return c.String()
+}
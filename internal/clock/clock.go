@@ -0,0 +1,168 @@
+/*
+Package clock abstracts the time for the poller, the request retries and the staleness checks, so their timing can be
+tested without waiting. Real is the wall clock, a Fake only moves when it is advanced.
+*/
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// Clock tells the time and waits like the functions of the time package
+	Clock interface {
+		Now() time.Time
+		Since(t time.Time) time.Duration
+		After(d time.Duration) <-chan time.Time
+		NewTicker(d time.Duration) Ticker
+	}
+
+	// Ticker delivers ticks on C like a time.Ticker
+	Ticker interface {
+		C() <-chan time.Time
+		Stop()
+	}
+
+	real struct{}
+
+	realTicker struct {
+		ticker *time.Ticker
+	}
+)
+
+// Real is the wall clock
+var Real Clock = real{}
+
+func (real) Now() time.Time {
+	return time.Now()
+}
+
+func (real) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (real) NewTicker(d time.Duration) Ticker {
+	return realTicker{ticker: time.NewTicker(d)}
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+type (
+	// Fake is a clock that stands still until Advance moves it, firing the timers and tickers that came due
+	Fake struct {
+		lock    sync.Mutex
+		now     time.Time
+		waiters []*waiter
+	}
+
+	// waiter is a pending After or a ticker, period is 0 for an After
+	waiter struct {
+		clock  *Fake
+		at     time.Time
+		period time.Duration
+		c      chan time.Time
+	}
+)
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.wait(d, 0).c
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for clock.Fake.NewTicker")
+	}
+	return f.wait(d, d)
+}
+
+func (f *Fake) wait(d, period time.Duration) *waiter {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	w := &waiter{clock: f, at: f.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	f.waiters = append(f.waiters, w)
+	f.fire()
+	return w
+}
+
+// Advance moves the clock forward by d, a ticker that came due several times delivers a single tick like a time.Ticker
+// whose receiver fell behind
+func (f *Fake) Advance(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.now = f.now.Add(d)
+	f.fire()
+}
+
+// Waiters returns the number of pending timers and tickers, so a test can wait for a goroutine to start waiting
+func (f *Fake) Waiters() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.waiters)
+}
+
+// fire delivers the due ticks in the order they came due and drops the fired timers
+func (f *Fake) fire() {
+	sort.SliceStable(f.waiters, func(i, j int) bool {
+		return f.waiters[i].at.Before(f.waiters[j].at)
+	})
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			pending = append(pending, w)
+			continue
+		}
+		select {
+		case w.c <- w.at:
+		default:
+		}
+		if w.period > 0 {
+			for !w.at.After(f.now) {
+				w.at = w.at.Add(w.period)
+			}
+			pending = append(pending, w)
+		}
+	}
+	f.waiters = pending
+}
+
+func (w *waiter) C() <-chan time.Time {
+	return w.c
+}
+
+func (w *waiter) Stop() {
+	f := w.clock
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			break
+		}
+	}
+}
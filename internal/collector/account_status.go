@@ -0,0 +1,149 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+accountStatus exports account restrictions and API trading bans, so punitive restrictions are noticed right away, and
+the permissions of the API key, so a security dashboard can check that every account uses IP restricted keys without
+withdrawal permission.
+*/
+type accountStatus struct {
+	permission
+	api          binance.BinanceAPI
+	lock         sync.Mutex
+	status       *binance.AccountStatus
+	trading      *binance.APITradingStatus
+	restrictions *binance.APIRestrictions
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &accountStatus{permission: permission{name: "account_status", logger: l}, api: api}
+	})
+}
+
+func (a *accountStatus) Name() string {
+	return a.name
+}
+
+func (a *accountStatus) Enabled() bool {
+	return a.permitted()
+}
+
+// Weight of the account status, API trading status and API restriction endpoints
+func (a *accountStatus) Weight() int {
+	return 3
+}
+
+func (a *accountStatus) Collect(ctx context.Context) error {
+	status, err := a.api.GetAccountStatus(ctx)
+	if err != nil {
+		return a.check(err)
+	}
+	trading, err := a.api.GetAPITradingStatus(ctx)
+	if err != nil {
+		return a.check(err)
+	}
+	restrictions, err := a.api.GetAPIRestrictions(ctx)
+	if err != nil {
+		return a.check(err)
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.status = &status
+	a.trading = &trading
+	a.restrictions = &restrictions
+	return nil
+}
+
+func (a *accountStatus) Gather() []prometheus.Family {
+	status := prometheus.NewGauge("binance_account_status", "Status of the account, 1 for the current one, anything but Normal is a restriction")
+	normal := prometheus.NewGauge("binance_account_normal", "1 while the account status is Normal, 0 while it is restricted")
+	locked := prometheus.NewGauge("binance_api_trading_locked", "1 while the API key is banned from trading")
+	recoverAt := prometheus.NewGauge("binance_api_trading_recover_timestamp_seconds", "Unix time the API trading ban is planned to be lifted")
+	value := prometheus.NewGauge("binance_api_trading_indicator_value", "Current value of the API trading rule indicator of the symbol")
+	trigger := prometheus.NewGauge("binance_api_trading_indicator_trigger", "Value of the indicator at which API trading of the symbol gets banned")
+	count := prometheus.NewGauge("binance_api_trading_indicator_orders", "Orders the indicator of the symbol was computed over")
+	permissions := prometheus.NewGauge("binance_api_key_permission", "1 while the API key has the permission, 0 while it doesn't")
+	ipRestricted := prometheus.NewGauge("binance_api_key_ip_restricted", "1 while the API key only accepts requests from trusted IPs")
+	created := prometheus.NewGauge("binance_api_key_created_timestamp_seconds", "Unix time the API key was created, for rotation policies")
+	tradingExpiry := prometheus.NewGauge("binance_api_key_trading_expiry_timestamp_seconds", "Unix time the trading permission of the API key expires")
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.status == nil || a.trading == nil || a.restrictions == nil {
+		return nil
+	}
+	status.Add(1, prometheus.L("status", a.status.Data))
+	isNormal := 0.0
+	if a.status.Data == "Normal" {
+		isNormal = 1
+	}
+	normal.Add(isNormal)
+
+	t := a.trading.Data
+	isLocked := 0.0
+	if t.IsLocked {
+		isLocked = 1
+	}
+	locked.Add(isLocked)
+	if t.PlannedRecoverTime > 0 {
+		recoverAt.Add(float64(t.PlannedRecoverTime) / 1000)
+	}
+	symbols := make([]string, 0, len(t.Indicators))
+	for symbol := range t.Indicators {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		for _, i := range t.Indicators[symbol] {
+			l := []prometheus.Label{prometheus.L("symbol", symbol), prometheus.L("indicator", i.Indicator)}
+			value.Add(i.Value, l...)
+			trigger.Add(i.Trigger, l...)
+			count.Add(float64(i.Count), l...)
+		}
+	}
+
+	r := a.restrictions
+	for _, p := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"reading", r.EnableReading},
+		{"withdrawals", r.EnableWithdrawals},
+		{"internal_transfer", r.EnableInternalTransfer},
+		{"universal_transfer", r.PermitsUniversalTransfer},
+		{"margin", r.EnableMargin},
+		{"futures", r.EnableFutures},
+		{"options", r.EnableVanillaOptions},
+		{"spot_and_margin_trading", r.EnableSpotAndMarginTrading},
+		{"portfolio_margin_trading", r.EnablePortfolioMarginTrading},
+	} {
+		enabled := 0.0
+		if p.enabled {
+			enabled = 1
+		}
+		permissions.Add(enabled, prometheus.L("permission", p.name))
+	}
+	isRestricted := 0.0
+	if r.IPRestrict {
+		isRestricted = 1
+	}
+	ipRestricted.Add(isRestricted)
+	if r.CreateTime > 0 {
+		created.Add(float64(r.CreateTime) / 1000)
+	}
+	if r.TradingAuthorityExpirationTime > 0 {
+		tradingExpiry.Add(float64(r.TradingAuthorityExpirationTime) / 1000)
+	}
+	return []prometheus.Family{*status, *normal, *locked, *recoverAt, *value, *trigger, *count, *permissions, *ipRestricted, *created, *tradingExpiry}
+}
@@ -0,0 +1,270 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/store"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	// accrualBucket holds the totals and the history of the accruals collector in the store
+	accrualBucket = "accruals"
+	accrualKey    = "state"
+)
+
+// flexibleRewardTypes are the Simple Earn flexible rewards, the tiered bonus APR pays out apart from the base APR
+var flexibleRewardTypes = []string{"REALTIME", "BONUS"}
+
+type (
+	/*
+		accruals counts what binance only lists as event histories, Simple Earn rewards, margin interest and futures
+		funding fees, into counters. Totals and the counted part of the history are kept in the store, so with
+		EXPORTER_STORE_PATH the counters continue where they were after a restart instead of starting over from the
+		lookback. Accounts without margin or futures only count the rest.
+	*/
+	accruals struct {
+		permission
+		margin  permission // Switched off on their own, earn rewards are counted either way
+		futures permission
+		api     binance.BinanceAPI
+		store   *store.Store
+		enabled bool
+		lock    sync.Mutex
+		history history // Sources are the reward types, the margin interest and the funding fees
+		totals  map[accrual]float64
+	}
+
+	// accrual is a series of the counters, labels are in the order of the metric
+	accrual struct {
+		Metric string    `json:"metric"`
+		Labels [3]string `json:"labels"`
+	}
+
+	// accrualState is what the collector keeps in the store, the totals are a list since JSON has no struct keys
+	accrualState struct {
+		History historyState   `json:"history"`
+		Totals  []accrualTotal `json:"totals"`
+	}
+	accrualTotal struct {
+		accrual
+		Value float64 `json:"value"`
+	}
+)
+
+const (
+	accrualEarn     = "earn"
+	accrualInterest = "interest"
+	accrualFunding  = "funding"
+)
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		a := &accruals{
+			permission: permission{name: "accruals", logger: l},
+			margin:     permission{name: "accruals", logger: l},
+			futures:    permission{name: "accruals", logger: l},
+			api:        api,
+			store:      store.Default,
+			enabled:    cfg.History.Accruals,
+			history:    newHistory("accruals", cfg.History),
+			totals:     make(map[accrual]float64),
+		}
+		if a.enabled {
+			a.load(l)
+		}
+		return a
+	})
+}
+
+// load continues from the totals and the history in the store
+func (a *accruals) load(l *zap.Logger) {
+	state := accrualState{}
+	ok, err := a.store.Get(accrualBucket, accrualKey, &state)
+	if err != nil {
+		l.Warn("Failed to load the accruals from the store, counting them from the lookback again", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	a.history.restore(state.History)
+	for _, t := range state.Totals {
+		a.totals[t.accrual] = t.Value
+	}
+}
+
+func (a *accruals) Name() string {
+	return a.name
+}
+
+func (a *accruals) Enabled() bool {
+	return a.enabled && a.permitted()
+}
+
+// Weight of both flexible reward types and the locked rewards, the margin interest and the futures funding fees
+func (a *accruals) Weight() int {
+	return 150*len(flexibleRewardTypes) + 150 + 1 + 30
+}
+
+func (a *accruals) Priority() Priority {
+	return PriorityLow
+}
+
+// DefaultInterval is long since rewards, interest and funding accrue hourly at most and the earn histories are expensive
+func (a *accruals) DefaultInterval() time.Duration {
+	return time.Hour
+}
+
+func (a *accruals) Collect(ctx context.Context) error {
+	now := time.Now()
+	changed := false
+	defer func() {
+		if changed {
+			a.save(ctx)
+		}
+	}()
+
+	for _, rewardType := range flexibleRewardTypes {
+		rewards, err := a.api.GetFlexibleRewards(ctx, rewardType, a.from(rewardType, now), now)
+		if err != nil {
+			return a.check(err)
+		}
+		a.lock.Lock()
+		for _, r := range rewards {
+			id := "flexible:" + r.ProjectID + ":" + r.Type + ":" + strconv.FormatInt(r.Time, 10)
+			if a.history.count(id, time.UnixMilli(r.Time)) {
+				a.totals[accrual{Metric: accrualEarn, Labels: [3]string{"flexible", strings.ToLower(r.Type), r.Asset}}] += parseOrZero(r.Rewards)
+			}
+		}
+		a.history.advance(rewardType, now.Add(-historyOverlap))
+		changed = true
+		a.lock.Unlock()
+	}
+
+	locked, err := a.api.GetLockedRewards(ctx, a.from("LOCKED", now), now)
+	if err != nil {
+		return a.check(err)
+	}
+	a.lock.Lock()
+	for _, r := range locked {
+		id := "locked:" + strconv.FormatInt(r.PositionID, 10) + ":" + strconv.FormatInt(r.Time, 10)
+		if a.history.count(id, time.UnixMilli(r.Time)) {
+			a.totals[accrual{Metric: accrualEarn, Labels: [3]string{"locked", "realtime", r.Asset}}] += parseOrZero(r.Amount)
+		}
+	}
+	a.history.advance("LOCKED", now.Add(-historyOverlap))
+	changed = true
+	a.lock.Unlock()
+
+	if a.margin.permitted() {
+		interests, err := a.api.GetMarginInterest(ctx, a.from("INTEREST", now), now)
+		if err != nil {
+			return a.margin.check(err)
+		}
+		a.lock.Lock()
+		for _, i := range interests {
+			if !a.history.count("interest:"+strconv.FormatInt(i.TxID, 10), time.UnixMilli(i.Time)) {
+				continue
+			}
+			account := "cross"
+			if len(i.IsolatedSymbol) > 0 {
+				account = "isolated"
+			}
+			a.totals[accrual{Metric: accrualInterest, Labels: [3]string{account, i.Asset}}] += parseOrZero(i.Interest)
+		}
+		a.history.advance("INTEREST", now.Add(-historyOverlap))
+		changed = true
+		a.lock.Unlock()
+	}
+
+	if a.futures.permitted() {
+		fees, err := a.api.GetFuturesIncome(ctx, "FUNDING_FEE", a.from("FUNDING_FEE", now), now)
+		if err != nil {
+			return a.futures.check(err)
+		}
+		a.lock.Lock()
+		for _, f := range fees {
+			if !a.history.count("funding:"+strconv.FormatInt(f.TranID, 10), time.UnixMilli(f.Time)) {
+				continue
+			}
+			// A counter can't go down, so received and paid funding are counted apart
+			fee, direction := parseOrZero(f.Income), "received"
+			if fee < 0 {
+				fee, direction = -fee, "paid"
+			}
+			a.totals[accrual{Metric: accrualFunding, Labels: [3]string{f.Symbol, f.Asset, direction}}] += fee
+		}
+		a.history.advance("FUNDING_FEE", now.Add(-historyOverlap))
+		changed = true
+		a.lock.Unlock()
+	}
+	return nil
+}
+
+func (a *accruals) from(source string, now time.Time) time.Time {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.history.from(source, now)
+}
+
+// save stores the totals along with the history they were counted from, so neither is counted twice after a restart
+func (a *accruals) save(ctx context.Context) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	state := accrualState{History: a.history.state(), Totals: make([]accrualTotal, 0, len(a.totals))}
+	for k, v := range a.totals {
+		state.Totals = append(state.Totals, accrualTotal{accrual: k, Value: v})
+	}
+	if err := a.store.Put(accrualBucket, accrualKey, state); err != nil {
+		tracing.Logger(ctx, a.logger).Warn("Failed to store the accruals, they are counted from the lookback again after a restart", zap.Error(err))
+	}
+}
+
+func (a *accruals) Gather() []prometheus.Family {
+	rewards := prometheus.NewCounter("binance_earn_rewards_total", "Simple Earn rewards paid to the account by product, reward type and asset")
+	interest := prometheus.NewCounter("binance_margin_interest_total", "Interest charged on the margin loans of the account by margin account and asset")
+	funding := prometheus.NewCounter("binance_futures_funding_fees_total", "USDⓈ-M futures funding fees by symbol, margin asset and whether the account received or paid them")
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if !a.history.started() {
+		return nil
+	}
+	keys := make([]accrual, 0, len(a.totals))
+	for k := range a.totals {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Metric != keys[j].Metric {
+			return keys[i].Metric < keys[j].Metric
+		}
+		for n := range keys[i].Labels {
+			if keys[i].Labels[n] != keys[j].Labels[n] {
+				return keys[i].Labels[n] < keys[j].Labels[n]
+			}
+		}
+		return false
+	})
+	for _, k := range keys {
+		v, l := a.totals[k], k.Labels
+		switch k.Metric {
+		case accrualEarn:
+			rewards.Add(v, prometheus.L("product", l[0]), prometheus.L("type", l[1]), prometheus.L("asset", l[2]))
+		case accrualInterest:
+			interest.Add(v, prometheus.L("account", l[0]), prometheus.L("asset", l[1]))
+		case accrualFunding:
+			funding.Add(v, prometheus.L("symbol", l[0]), prometheus.L("asset", l[1]), prometheus.L("direction", l[2]))
+		}
+	}
+	return []prometheus.Family{*rewards, *interest, *funding}
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+const (
+	adaptiveSpeedUp  = 0.8  // Scale applied after a cycle with plenty of headroom
+	adaptiveSlowDown = 1.5  // Scale applied after a cycle close to the limit, backing off faster than speeding up
+	headroomLow      = 0.25 // Share of the weight limit left below which the poller slows down
+	headroomHigh     = 0.5  // Share of the weight limit left above which the poller speeds up
+)
+
+/*
+adaptive scales the poll intervals with the request weight headroom binance reports after every cycle. Weight used by
+other consumers of the same IP shows up in the reported weight, so the exporter backs off when they need the limit
+and catches up again once they are done.
+*/
+type adaptive struct {
+	cfg        config.Adaptive
+	interval   time.Duration // EXPORTER_POLL_INTERVAL, the interval the min and max bounds apply to
+	usedWeight func() (int, bool)
+	lock       sync.Mutex
+	scale      float64
+}
+
+// newAdaptive returns nil unless EXPORTER_ADAPTIVE_INTERVAL is set
+func newAdaptive(cfg config.Collection, usedWeight func() (int, bool)) *adaptive {
+	if !cfg.Adaptive.Enabled {
+		return nil
+	}
+	return &adaptive{cfg: cfg.Adaptive, interval: cfg.Interval, usedWeight: usedWeight, scale: 1}
+}
+
+// adapt updates the scale from the weight binance reported last
+func (a *adaptive) adapt() {
+	if a == nil {
+		return
+	}
+	used, ok := a.usedWeight()
+	if !ok {
+		return
+	}
+	headroom := 1 - float64(used)/float64(a.cfg.WeightLimit)
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	switch {
+	case headroom < headroomLow:
+		a.scale *= adaptiveSlowDown
+	case headroom > headroomHigh:
+		a.scale *= adaptiveSpeedUp
+	}
+	if lower := float64(a.cfg.MinInterval) / float64(a.interval); a.scale < lower {
+		a.scale = lower
+	}
+	if upper := float64(a.cfg.MaxInterval) / float64(a.interval); a.scale > upper {
+		a.scale = upper
+	}
+}
+
+// scaled returns the interval adapted to the current headroom
+func (a *adaptive) scaled(interval time.Duration) time.Duration {
+	if a == nil {
+		return interval
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return time.Duration(float64(interval) * a.scale)
+}
+
+func (a *adaptive) Gather() []prometheus.Family {
+	if a == nil {
+		return nil
+	}
+	f := prometheus.NewGauge("binance_poll_interval_seconds", "EXPORTER_POLL_INTERVAL as adapted to the request weight headroom, the other intervals scale along")
+	f.Add(a.scaled(a.interval).Seconds())
+	return []prometheus.Family{*f}
+}
@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"golang.org/x/time/rate"
+)
+
+/*
+budget spreads the request weight of the collectors over the minute. It refills continuously at the configured weight
+per minute and holds at most a minute worth of it. Collectors below PriorityHigh have to leave part of the budget for
+the more important ones, so as the budget runs low the low priority collectors are dropped first and the balances
+keep being refreshed.
+*/
+type budget struct {
+	limiter *rate.Limiter
+	size    int
+	lock    sync.Mutex
+}
+
+// reserves are the parts of the budget that have to be left after a collector of the priority ran
+var reserves = map[Priority]float64{
+	PriorityLow:    0.5,
+	PriorityNormal: 0.2,
+	PriorityHigh:   0,
+}
+
+// newBudget returns a budget of perMinute request weight, nil for no budget
+func newBudget(perMinute int) *budget {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &budget{limiter: rate.NewLimiter(rate.Limit(float64(perMinute)/60), perMinute), size: perMinute}
+}
+
+// take spends the weight of the collector if enough of the budget is left for its priority
+func (b *budget) take(c Collector, priority Priority, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	weight := weightOf(c)
+	if weight > b.size {
+		// Never affordable, let it run on a full budget instead
+		weight = b.size
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	left := b.limiter.TokensAt(now) - float64(weight)
+	if left < reserves[priority]*float64(b.size) {
+		return false
+	}
+	return b.limiter.AllowN(now, weight)
+}
+
+func (b *budget) Gather() []prometheus.Family {
+	if b == nil {
+		return nil
+	}
+	f := prometheus.NewGauge("binance_weight_budget_remaining", "Request weight left of EXPORTER_WEIGHT_BUDGET, it refills continuously over the minute")
+	f.Add(math.Max(0, math.Floor(b.limiter.Tokens())))
+	return []prometheus.Family{*f}
+}
+
+func weightOf(c Collector) int {
+	if w, ok := c.(Weigher); ok {
+		return w.Weight()
+	}
+	return 1
+}
@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// categoryUnknown is the category of assets before the coin metadata was fetched and of assets binance doesn't list
+const categoryUnknown = "unknown"
+
+/*
+assetCategories are the categories of every coin binance lists. Coin metadata is the same for every account, so all
+registries share coinCategories and whichever fetched it last wins.
+*/
+type assetCategories struct {
+	lock    sync.RWMutex
+	byAsset map[string]string
+}
+
+var coinCategories = &assetCategories{}
+
+func (a *assetCategories) of(asset string) string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if category, ok := a.byAsset[asset]; ok {
+		return category
+	}
+	return categoryUnknown
+}
+
+func (a *assetCategories) set(byAsset map[string]string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.byAsset = byAsset
+}
+
+// categorize sorts the coin into one of config.AssetCategories
+func categorize(c binance.Coin) string {
+	switch {
+	case c.IsLegalMoney:
+		return config.CategoryFiat
+	case strings.HasSuffix(c.Name, " Fan Token"):
+		return config.CategoryFanToken
+	case !c.Trading:
+		return config.CategoryUntradable
+	}
+	return config.CategoryCrypto
+}
+
+/*
+excludeCategories drops the assets of the excluded categories. Assets of unknown category are kept, so until the coin
+metadata was fetched nothing is excluded.
+*/
+func excludeCategories(balances []balance.Balance, excluded []string) []balance.Balance {
+	if len(excluded) == 0 {
+		return balances
+	}
+	kept := make([]balance.Balance, 0, len(balances))
+	for _, b := range balances {
+		category := coinCategories.of(b.Asset)
+		drop := false
+		for _, e := range excluded {
+			drop = drop || e == category
+		}
+		if !drop {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// categories fetches the coin metadata the category label and EXPORTER_EXCLUDE_ASSET_CATEGORIES depend on
+type categories struct {
+	permission
+	api     binance.BinanceAPI
+	enabled bool
+	lock    sync.Mutex
+	held    map[string]string // Category of every asset with a spot balance
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &categories{
+			permission: permission{name: "asset_categories", logger: l},
+			api:        api,
+			enabled:    cfg.Assets.CategoryLabel || len(cfg.Assets.ExcludeCategories) > 0,
+		}
+	})
+}
+
+func (c *categories) Name() string {
+	return c.name
+}
+
+func (c *categories) Enabled() bool {
+	return c.enabled && c.permitted()
+}
+
+func (c *categories) Weight() int {
+	return 10
+}
+
+// Priority is high since the wallets export unknown categories until the first collection
+func (c *categories) Priority() Priority {
+	return PriorityHigh
+}
+
+// DefaultInterval is long since binance rarely lists or delists coins
+func (c *categories) DefaultInterval() time.Duration {
+	return time.Hour
+}
+
+func (c *categories) Collect(ctx context.Context) error {
+	coins, err := c.api.GetCoins(ctx)
+	if err != nil {
+		return c.check(err)
+	}
+	byAsset := make(map[string]string, len(coins))
+	held := make(map[string]string)
+	for _, coin := range coins {
+		category := categorize(coin)
+		byAsset[coin.Coin] = category
+		for _, balance := range []string{coin.Free, coin.Locked, coin.Freeze, coin.Withdrawing, coin.Ipoing, coin.Ipoable, coin.Storage} {
+			if parseOrZero(balance) > 0 {
+				held[coin.Coin] = category
+				break
+			}
+		}
+	}
+	coinCategories.set(byAsset)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.held = held
+	return nil
+}
+
+// Gather exports the category of the held assets only, binance lists thousands of coins
+func (c *categories) Gather() []prometheus.Family {
+	category := prometheus.NewGauge("binance_asset_category", "Category of the asset held in the spot wallet from the binance coin metadata, 1 for its category")
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for asset, name := range c.held {
+		category.Add(1, prometheus.L("asset", asset), prometheus.L("category", name))
+	}
+	return []prometheus.Family{*category}
+}
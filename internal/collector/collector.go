@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+type (
+	/*
+		Collector fetches one kind of data from binance and turns it into metrics. The metrics of the last successful
+		collection are served until the next one succeeds.
+	*/
+	Collector interface {
+		Name() string
+		// Enabled reports whether the collector should run, the poller skips disabled collectors
+		Enabled() bool
+		Collect(ctx context.Context) error
+		prometheus.Gatherer
+	}
+
+	// Disabler is implemented by collectors that can be switched off at runtime, e.g. because of missing permissions
+	Disabler interface {
+		// DisableReason returns why the collector was disabled, empty while it is enabled
+		DisableReason() string
+	}
+
+	// Interval is implemented by collectors of slow changing data, which then don't follow EXPORTER_POLL_INTERVAL
+	Interval interface {
+		// DefaultInterval is used unless EXPORTER_COLLECTOR_INTERVALS sets one for the collector
+		DefaultInterval() time.Duration
+	}
+
+	// Valuer is implemented by collectors of holdings that count towards the total balance of the account
+	Valuer interface {
+		// ValueBTC returns the net value of the holdings in BTC, false while it is unknown
+		ValueBTC() (float64, bool)
+	}
+
+	// Weigher is implemented by collectors whose runs cost more than 1 request weight
+	Weigher interface {
+		// Weight estimates the request weight of one run from the weights binance documents for its endpoints
+		Weight() int
+	}
+
+	/*
+		Prioritizer is implemented by collectors that are more or less important than PriorityNormal. Higher priority
+		collectors start first in a cycle, so they still run when the cycle is cut short.
+	*/
+	Prioritizer interface {
+		Priority() Priority
+	}
+
+	// Priority decides which collectors are dropped first once the request weight budget runs low
+	Priority int
+
+	// Factory builds a collector on top of the client
+	Factory func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector
+)
+
+const (
+	PriorityLow    Priority = iota // Cosmetic data like trading rules and rates
+	PriorityNormal                 // The default
+	PriorityHigh                   // Balances and risk metrics
+)
+
+// priorityNames are the values of EXPORTER_COLLECTOR_PRIORITIES
+var priorityNames = map[string]Priority{"low": PriorityLow, "normal": PriorityNormal, "high": PriorityHigh}
+
+// factories of the built-in collectors, every collector registers itself from its own file
+var factories []Factory
+
+func register(f Factory) {
+	factories = append(factories, f)
+}
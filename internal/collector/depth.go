@@ -0,0 +1,136 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// depth samples the order book of the configured symbols and exports the volume resting near the mid price
+type depth struct {
+	api     binance.BinanceAPI
+	symbols []string
+	bands   []int
+	limit   int
+	lock    sync.Mutex
+	books   map[string]bookVolume
+}
+
+// bookVolume is the bid and ask volume of a book within each band, indexed like depth.bands
+type bookVolume struct {
+	bids []float64
+	asks []float64
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &depth{api: api, symbols: cfg.Market.DepthSymbols, bands: cfg.Market.DepthBands, limit: cfg.Market.DepthLimit}
+	})
+}
+
+func (d *depth) Name() string {
+	return "depth"
+}
+
+func (d *depth) Enabled() bool {
+	return len(d.symbols) > 0
+}
+
+// Weight grows with EXPORTER_DEPTH_LIMIT, binance charges deeper books more
+func (d *depth) Weight() int {
+	weight := 5
+	switch {
+	case d.limit > 1000:
+		weight = 250
+	case d.limit > 500:
+		weight = 50
+	case d.limit > 100:
+		weight = 25
+	}
+	return weight * len(d.symbols)
+}
+
+func (d *depth) Priority() Priority {
+	return PriorityLow
+}
+
+func (d *depth) Collect(ctx context.Context) error {
+	books := make(map[string]bookVolume, len(d.symbols))
+	for _, symbol := range d.symbols {
+		book, err := d.api.GetDepth(ctx, symbol, d.limit)
+		if err != nil {
+			return err
+		}
+		if volume, ok := volumeWithin(book, d.bands); ok {
+			books[symbol] = volume
+		}
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.books = books
+	return nil
+}
+
+func (d *depth) Gather() []prometheus.Family {
+	bids := prometheus.NewGauge("binance_depth_bid_volume", "Bid quantity resting within bps basis points below the mid price")
+	asks := prometheus.NewGauge("binance_depth_ask_volume", "Ask quantity resting within bps basis points above the mid price")
+	imbalance := prometheus.NewGauge("binance_depth_imbalance", "(bid - ask) / (bid + ask) volume within bps basis points of the mid price, from -1 to 1")
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, symbol := range d.symbols {
+		volume, ok := d.books[symbol]
+		if !ok {
+			continue
+		}
+		for i, bps := range d.bands {
+			l := []prometheus.Label{prometheus.L("symbol", symbol), prometheus.L("bps", strconv.Itoa(bps))}
+			bid, ask := volume.bids[i], volume.asks[i]
+			bids.Add(bid, l...)
+			asks.Add(ask, l...)
+			if bid+ask > 0 {
+				imbalance.Add((bid-ask)/(bid+ask), l...)
+			}
+		}
+	}
+	return []prometheus.Family{*bids, *asks, *imbalance}
+}
+
+/*
+volumeWithin sums up the quantity of the levels within each band around the mid price of the book. A band reaching
+past the deepest level fetched only covers the fetched levels. It returns false for a book with an empty side.
+*/
+func volumeWithin(book binance.Depth, bands []int) (bookVolume, bool) {
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return bookVolume{}, false
+	}
+	bestBid, bestAsk := parseOrZero(book.Bids[0][0]), parseOrZero(book.Asks[0][0])
+	if bestBid <= 0 || bestAsk <= 0 {
+		return bookVolume{}, false
+	}
+	mid := (bestBid + bestAsk) / 2
+
+	volume := bookVolume{bids: make([]float64, len(bands)), asks: make([]float64, len(bands))}
+	for i, bps := range bands {
+		distance := mid * float64(bps) / 10000
+		for _, level := range book.Bids {
+			if parseOrZero(level[0]) < mid-distance {
+				break
+			}
+			volume.bids[i] += parseOrZero(level[1])
+		}
+		for _, level := range book.Asks {
+			if parseOrZero(level[0]) > mid+distance {
+				break
+			}
+			volume.asks[i] += parseOrZero(level[1])
+		}
+	}
+	return volume, true
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/expr"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+derived evaluates the EXPORTER_DERIVED_METRICS expressions over the metrics of the collectors after every poll cycle,
+so exporter local math like the share of stablecoins in the total balance needs no recording rule. Expressions see
+the built-in metric names, before the configured namespace and constant labels are applied. A metric whose expression
+has no value, like a division by zero, is not exported until it has one again.
+*/
+type derived struct {
+	metrics  []derivedMetric
+	failures *prometheus.Vec
+	lock     sync.Mutex
+	values   map[string]float64 // By metric name, only the ones that had a value in the last evaluation
+}
+
+type derivedMetric struct {
+	name       string
+	expression *expr.Expression
+}
+
+// newDerived returns nil without derived metrics, the expressions were validated with the configuration
+func newDerived(definitions map[string]string, l *zap.Logger) *derived {
+	if len(definitions) == 0 {
+		return nil
+	}
+	d := &derived{
+		failures: prometheus.NewCounterVec("binance_derived_metric_failures_total", "Evaluations of the expression of the derived metric that had no value", "metric"),
+		values:   make(map[string]float64),
+	}
+	for name, definition := range definitions {
+		expression, err := expr.Parse(definition)
+		if err != nil {
+			l.Warn("Ignoring invalid derived metric", zap.String("metric", name), zap.Error(err))
+			continue
+		}
+		if !strings.HasPrefix(name, prometheus.DefaultNamespace+"_") {
+			name = prometheus.DefaultNamespace + "_" + name
+		}
+		d.metrics = append(d.metrics, derivedMetric{name: name, expression: expression})
+	}
+	sort.Slice(d.metrics, func(i, j int) bool { return d.metrics[i].name < d.metrics[j].name })
+	return d
+}
+
+// evaluate replaces the values of the derived metrics with the ones of their expressions over families
+func (d *derived) evaluate(families []prometheus.Family) {
+	if d == nil {
+		return
+	}
+	values := make(map[string]float64, len(d.metrics))
+	for _, m := range d.metrics {
+		v, ok := m.expression.Eval(families)
+		if !ok {
+			d.failures.Inc(m.name)
+			continue
+		}
+		values[m.name] = v
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.values = values
+}
+
+func (d *derived) Gather() []prometheus.Family {
+	if d == nil {
+		return nil
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	families := make([]prometheus.Family, 0, len(d.metrics)+1)
+	for _, m := range d.metrics {
+		if v, ok := d.values[m.name]; ok {
+			f := prometheus.NewGauge(m.name, "Derived from "+m.expression.String())
+			f.Add(v)
+			families = append(families, *f)
+		}
+	}
+	return append(families, d.failures.Gather()...)
+}
@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"sort"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+// MetricDoc describes a metric as it is exported right now
+type MetricDoc struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Help      string   `json:"help"`
+	Labels    []string `json:"labels"`
+	Collector string   `json:"collector,omitempty"` // Empty for the metrics of the exporter itself
+	Endpoints []string `json:"endpoints,omitempty"` // Binance endpoints the collector requested so far
+}
+
+/*
+Docs describes every metric the enabled collectors and the registry export, followed by the metrics of exporter, which
+are not tied to a collector. Since the docs are built from what gets exported they can't drift from it, but metrics
+without samples, e.g. of collectors that did not run yet, are missing. The endpoints fill in as the collectors run.
+*/
+func (r *Registry) Docs(exporter []prometheus.Family) []MetricDoc {
+	docs := make([]MetricDoc, 0)
+	seen := make(map[string]bool)
+	add := func(families []prometheus.Family, collector string, endpoints []string) {
+		for _, f := range prometheus.Merge(families) {
+			if len(f.Samples) == 0 || seen[f.Name] {
+				continue
+			}
+			seen[f.Name] = true
+			docs = append(docs, MetricDoc{Name: f.Name, Type: f.Type, Help: f.Help, Labels: labelNames(f), Collector: collector, Endpoints: endpoints})
+		}
+	}
+	for _, c := range r.collectors {
+		if c.Enabled() {
+			add(r.naming().Apply(c.Gather()), c.Name(), r.endpointsOf(c.Name()))
+		}
+	}
+	add(r.naming().Apply(r.derived.Gather()), "", nil)
+	add(r.naming().Apply(r.selfFamilies()), "", nil)
+	add(exporter, "", nil)
+	return docs
+}
+
+// endpointsOf returns the endpoints the collector requested so far in alphabetical order
+func (r *Registry) endpointsOf(name string) []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := make([]string, 0, len(r.endpoints[name]))
+	for endpoint := range r.endpoints[name] {
+		res = append(res, endpoint)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// labelNames returns the names of all labels of the samples of the family, in the order they first appear
+func labelNames(f prometheus.Family) []string {
+	res := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, s := range f.Samples {
+		for _, l := range s.Labels {
+			if !seen[l.Name] {
+				seen[l.Name] = true
+				res = append(res, l.Name)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+package collector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.uber.org/zap"
+)
+
+// dualInvestment exports the Dual Investment positions waiting for settlement
+type dualInvestment struct {
+	permission
+	api       binance.BinanceAPI
+	lock      sync.Mutex
+	positions []binance.DualInvestment
+	btc       map[string]float64 // BTC price of every invested asset, nil until the first collection
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &dualInvestment{permission: permission{name: "dual_investment", logger: l}, api: api}
+	})
+}
+
+func (d *dualInvestment) Name() string {
+	return d.name
+}
+
+func (d *dualInvestment) Enabled() bool {
+	return d.permitted()
+}
+
+func (d *dualInvestment) Priority() Priority {
+	return PriorityLow
+}
+
+func (d *dualInvestment) Collect(ctx context.Context) error {
+	positions, err := d.api.GetDualInvestments(ctx)
+	if err != nil {
+		return d.check(err)
+	}
+	btc := make(map[string]float64)
+	for _, p := range positions {
+		if _, ok := btc[p.InvestCoin]; ok {
+			continue
+		}
+		price, err := btcPrice(ctx, d.api, p.InvestCoin)
+		if err != nil {
+			tracing.Logger(ctx, d.logger).Debug("No BTC price of the invested asset, leaving it out of the totals", zap.String("asset", p.InvestCoin), zap.Error(err))
+			continue
+		}
+		btc[p.InvestCoin] = price
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.positions, d.btc = positions, btc
+	return nil
+}
+
+func (d *dualInvestment) Gather() []prometheus.Family {
+	amount := prometheus.NewGauge("binance_dual_investment_amount", "Amount of the invested asset subscribed to the Dual Investment product")
+	strike := prometheus.NewGauge("binance_dual_investment_strike_price", "Strike price of the Dual Investment product")
+	apr := prometheus.NewGauge("binance_dual_investment_apr", "Annual percentage rate of the Dual Investment product, 0.05 is 5%")
+	settle := prometheus.NewGauge("binance_dual_investment_settlement_timestamp_seconds", "Unix time the Dual Investment product settles at")
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.btc == nil {
+		return nil
+	}
+	for _, p := range d.positions {
+		l := []prometheus.Label{
+			prometheus.L("id", p.ID),
+			prometheus.L("invest_asset", p.InvestCoin),
+			prometheus.L("exercised_asset", p.ExercisedCoin),
+			prometheus.L("option_type", p.OptionType),
+		}
+		addParsed(amount, p.SubscriptionAmount, l...)
+		addParsed(strike, p.StrikePrice, l...)
+		addParsed(apr, p.APR, l...)
+		settle.Add(float64(p.SettleDate)/1000, l...)
+	}
+	return []prometheus.Family{*amount, *strike, *apr, *settle}
+}
+
+// ValueBTC values the positions by their invested amount, what they settle in is only known at settlement
+func (d *dualInvestment) ValueBTC() (float64, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.btc == nil {
+		return 0, false
+	}
+	total := 0.0
+	for _, p := range d.positions {
+		total += parseOrZero(p.SubscriptionAmount) * d.btc[p.InvestCoin]
+	}
+	return total, true
+}
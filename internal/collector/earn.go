@@ -0,0 +1,165 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.uber.org/zap"
+)
+
+/*
+earn exports the Simple Earn positions of the account. Subscribed assets leave the spot wallet, so without them the
+totals would drop whenever something gets staked. BNB Vault merged into flexible BNB and Launchpool stakes are drawn
+from Simple Earn, both show up here.
+*/
+type earn struct {
+	permission
+	api      binance.BinanceAPI
+	lock     sync.Mutex
+	flexible []binance.FlexiblePosition
+	locked   []binance.LockedPosition
+	btc      map[string]float64 // BTC price of every subscribed asset, nil until the first collection
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &earn{permission: permission{name: "earn", logger: l}, api: api}
+	})
+}
+
+func (e *earn) Name() string {
+	return e.name
+}
+
+func (e *earn) Enabled() bool {
+	return e.permitted()
+}
+
+// Weight of the position lists, the prices of the assets are small compared to them
+func (e *earn) Weight() int {
+	return 300
+}
+
+func (e *earn) Collect(ctx context.Context) error {
+	flexible, err := e.api.GetFlexiblePositions(ctx)
+	if err != nil {
+		return e.check(err)
+	}
+	locked, err := e.api.GetLockedPositions(ctx)
+	if err != nil {
+		return e.check(err)
+	}
+
+	btc := make(map[string]float64)
+	price := func(asset string) {
+		if _, ok := btc[asset]; ok {
+			return
+		}
+		p, err := btcPrice(ctx, e.api, asset)
+		if err != nil {
+			tracing.Logger(ctx, e.logger).Debug("No BTC price of the earn asset, leaving it out of the totals", zap.String("asset", asset), zap.Error(err))
+			return
+		}
+		btc[asset] = p
+	}
+	for _, p := range flexible {
+		price(p.Asset)
+	}
+	for _, p := range locked {
+		price(p.Asset)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.flexible, e.locked, e.btc = flexible, locked, btc
+	return nil
+}
+
+func (e *earn) Gather() []prometheus.Family {
+	amount := prometheus.NewGauge("binance_earn_position_amount", "Amount of the asset subscribed to the Simple Earn product")
+	rewards := prometheus.NewGauge("binance_earn_position_rewards", "Rewards the Simple Earn position accrued so far, in the reward asset")
+	apr := prometheus.NewGauge("binance_earn_position_apr", "Annual percentage rate of the Simple Earn position, 0.05 is 5%")
+	maturity := prometheus.NewGauge("binance_earn_position_maturity_timestamp_seconds", "Unix time the locked Simple Earn position stops accruing rewards")
+	autoSubscribe := prometheus.NewGauge("binance_earn_auto_subscribe_enabled", "1 if idle spot and funding balances of the asset are swept into the flexible Simple Earn product")
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.btc == nil {
+		return nil
+	}
+	for _, p := range e.flexible {
+		l := []prometheus.Label{balance.EarnFlexible.Label(), prometheus.L("type", "flexible"), prometheus.L("asset", p.Asset), prometheus.L("product", p.ProductID)}
+		addParsed(amount, p.TotalAmount, l...)
+		addParsed(rewards, p.CumulativeTotalRewards, append(l[:len(l):len(l)], prometheus.L("reward_asset", p.Asset))...)
+		addParsed(apr, p.LatestAnnualPercentageRate, l...)
+		isAuto := 0.0
+		if p.AutoSubscribe {
+			isAuto = 1
+		}
+		autoSubscribe.Add(isAuto, balance.EarnFlexible.Label(), prometheus.L("asset", p.Asset), prometheus.L("product", p.ProductID))
+	}
+	for _, p := range e.locked {
+		l := []prometheus.Label{balance.EarnLocked.Label(), prometheus.L("type", "locked"), prometheus.L("asset", p.Asset), prometheus.L("product", p.ProjectID)}
+		addParsed(amount, p.Amount, l...)
+		addParsed(rewards, p.RewardAmt, append(l[:len(l):len(l)], prometheus.L("reward_asset", p.RewardAsset))...)
+		addParsed(apr, p.APY, l...)
+		if ms, err := strconv.ParseInt(p.RewardsEndDate, 10, 64); err == nil {
+			maturity.Add(float64(ms)/1000, l...)
+		}
+	}
+	return []prometheus.Family{*amount, *rewards, *apr, *maturity, *autoSubscribe}
+}
+
+// ValueBTC sums up the subscribed amounts at their BTC price, accrued rewards are paid out to the wallets
+func (e *earn) ValueBTC() (float64, bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.btc == nil {
+		return 0, false
+	}
+	total := 0.0
+	for _, p := range e.flexible {
+		total += parseOrZero(p.TotalAmount) * e.btc[p.Asset]
+	}
+	for _, p := range e.locked {
+		total += parseOrZero(p.Amount) * e.btc[p.Asset]
+	}
+	return total, true
+}
+
+/*
+btcPrice returns the price of the asset in BTC through the average price of its BTC pair. Assets usually quoted the
+other way round, like stablecoins, fall back to the inverse of the BTC<asset> pair.
+*/
+func btcPrice(ctx context.Context, api binance.BinanceAPI, asset string) (float64, error) {
+	if asset == "BTC" {
+		return 1, nil
+	}
+	price, err := api.GetAvgPrice(ctx, asset+"BTC")
+	if err == nil {
+		return strconv.ParseFloat(price.Price, 64)
+	}
+	if !errors.Is(err, binance.ErrBadSymbol) {
+		return 0, err
+	}
+	price, err = api.GetAvgPrice(ctx, "BTC"+asset)
+	if err != nil {
+		return 0, err
+	}
+	inverse, err := strconv.ParseFloat(price.Price, 64)
+	if err != nil {
+		return 0, err
+	}
+	if inverse == 0 {
+		return 0, fmt.Errorf("no price of BTC%s", asset)
+	}
+	return 1 / inverse, nil
+}
@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// earnRates exports the current Simple Earn flexible rates of the configured assets, whether held or not
+type earnRates struct {
+	permission
+	api      binance.BinanceAPI
+	assets   []string
+	lock     sync.Mutex
+	products []binance.FlexibleProduct
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &earnRates{permission: permission{name: "earn_rates", logger: l}, api: api, assets: cfg.Market.EarnAssets}
+	})
+}
+
+func (e *earnRates) Name() string {
+	return e.name
+}
+
+func (e *earnRates) Enabled() bool {
+	return len(e.assets) > 0 && e.permitted()
+}
+
+func (e *earnRates) Weight() int {
+	return 150 * len(e.assets)
+}
+
+func (e *earnRates) Priority() Priority {
+	return PriorityLow
+}
+
+// DefaultInterval is long since binance adjusts flexible rates a few times a day at most
+func (e *earnRates) DefaultInterval() time.Duration {
+	return 15 * time.Minute
+}
+
+func (e *earnRates) Collect(ctx context.Context) error {
+	products := make([]binance.FlexibleProduct, 0, len(e.assets))
+	for _, asset := range e.assets {
+		page, err := e.api.GetFlexibleProducts(ctx, asset)
+		if err != nil {
+			return e.check(err)
+		}
+		products = append(products, page.Rows...)
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.products = products
+	return nil
+}
+
+func (e *earnRates) Gather() []prometheus.Family {
+	apr := prometheus.NewGauge("binance_earn_flexible_apr", "Current annual percentage rate of the Simple Earn flexible product, 0.05 is 5%")
+	tierAPR := prometheus.NewGauge("binance_earn_flexible_tier_apr", "Additional annual percentage rate of the Simple Earn flexible product for holdings within the tier")
+	purchasable := prometheus.NewGauge("binance_earn_flexible_purchasable", "1 while the Simple Earn flexible product can be subscribed to")
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, p := range e.products {
+		l := []prometheus.Label{prometheus.L("asset", p.Asset), prometheus.L("product", p.ProductID)}
+		addParsed(apr, p.LatestAnnualPercentageRate, l...)
+		canPurchase := 0.0
+		if p.CanPurchase && !p.IsSoldOut {
+			canPurchase = 1
+		}
+		purchasable.Add(canPurchase, l...)
+
+		tiers := make([]string, 0, len(p.TierAnnualPercentageRate))
+		for tier := range p.TierAnnualPercentageRate {
+			tiers = append(tiers, tier)
+		}
+		sort.Strings(tiers)
+		for _, tier := range tiers {
+			tierAPR.Add(p.TierAnnualPercentageRate[tier], append(l[:len(l):len(l)], prometheus.L("tier", tier))...)
+		}
+	}
+	return []prometheus.Family{*apr, *tierAPR, *purchasable}
+}
@@ -0,0 +1,121 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+type (
+	/*
+		earnSubscriptions counts the Simple Earn flexible subscriptions by type and the wallet they were paid from, so
+		auto-subscribe sweeping working capital out of spot and funding into Earn gets noticed.
+	*/
+	earnSubscriptions struct {
+		permission
+		api        binance.BinanceAPI
+		enabled    bool
+		lock       sync.Mutex
+		history    history
+		subscribed map[subscriptionKey]float64
+	}
+	subscriptionKey struct {
+		asset, subscriptionType string
+		wallet                  balance.Wallet
+	}
+)
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &earnSubscriptions{
+			permission: permission{name: "earn_subscriptions", logger: l},
+			api:        api,
+			enabled:    cfg.History.EarnSubscriptions,
+			history:    newHistory("earn_subscriptions", cfg.History),
+			subscribed: make(map[subscriptionKey]float64),
+		}
+	})
+}
+
+func (e *earnSubscriptions) Name() string {
+	return e.name
+}
+
+func (e *earnSubscriptions) Enabled() bool {
+	return e.enabled && e.permitted()
+}
+
+// Weight of a page of the subscription history
+func (e *earnSubscriptions) Weight() int {
+	return 150
+}
+
+func (e *earnSubscriptions) Priority() Priority {
+	return PriorityLow
+}
+
+// DefaultInterval is long since the subscription history is expensive and auto-subscribe sweeps once a day
+func (e *earnSubscriptions) DefaultInterval() time.Duration {
+	return 15 * time.Minute
+}
+
+func (e *earnSubscriptions) Collect(ctx context.Context) error {
+	now := time.Now()
+	e.lock.Lock()
+	from := e.history.from("flexible", now)
+	e.lock.Unlock()
+	records, err := e.api.GetFlexibleSubscriptions(ctx, from, now)
+	if err != nil {
+		return e.check(err)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, r := range records {
+		if r.Status != "SUCCESS" || !e.history.count(strconv.FormatInt(r.PurchaseID, 10), time.UnixMilli(r.Time)) {
+			continue
+		}
+		subscriptionType := strings.ToLower(r.Type)
+		e.subscribed[subscriptionKey{asset: r.Asset, subscriptionType: subscriptionType, wallet: balance.Spot}] += parseOrZero(r.AmtFromSpot)
+		e.subscribed[subscriptionKey{asset: r.Asset, subscriptionType: subscriptionType, wallet: balance.Funding}] += parseOrZero(r.AmtFromFunding)
+	}
+	e.history.advance("flexible", now.Add(-historyOverlap))
+	return nil
+}
+
+func (e *earnSubscriptions) Gather() []prometheus.Family {
+	subscribed := prometheus.NewCounter("binance_earn_subscribed_amount_total", "Amount subscribed to Simple Earn flexible products by asset, subscription type (auto or normal) and the wallet it was taken from")
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if !e.history.started() {
+		return nil
+	}
+	keys := make([]subscriptionKey, 0, len(e.subscribed))
+	for k := range e.subscribed {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.asset != b.asset {
+			return a.asset < b.asset
+		}
+		if a.subscriptionType != b.subscriptionType {
+			return a.subscriptionType < b.subscriptionType
+		}
+		return a.wallet < b.wallet
+	})
+	for _, k := range keys {
+		subscribed.Add(e.subscribed[k], prometheus.L("asset", k.asset), prometheus.L("type", k.subscriptionType), k.wallet.Label())
+	}
+	return []prometheus.Family{*subscribed}
+}
@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// exchangeInfo exports the trading status and rules of every symbol a market data collector watches
+type exchangeInfo struct {
+	api     binance.BinanceAPI
+	symbols []string
+	lock    sync.Mutex
+	info    binance.ExchangeInfo
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &exchangeInfo{api: api, symbols: cfg.Market.Symbols()}
+	})
+}
+
+func (e *exchangeInfo) Name() string {
+	return "exchange_info"
+}
+
+func (e *exchangeInfo) Enabled() bool {
+	return len(e.symbols) > 0
+}
+
+func (e *exchangeInfo) Weight() int {
+	return 20
+}
+
+func (e *exchangeInfo) Priority() Priority {
+	return PriorityLow
+}
+
+// DefaultInterval is long since trading rules rarely change and exchangeInfo costs 20 request weight
+func (e *exchangeInfo) DefaultInterval() time.Duration {
+	return 15 * time.Minute
+}
+
+func (e *exchangeInfo) Collect(ctx context.Context) error {
+	info, err := e.api.GetExchangeInfo(ctx, e.symbols)
+	if err != nil {
+		return err
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.info = info
+	return nil
+}
+
+func (e *exchangeInfo) Gather() []prometheus.Family {
+	status := prometheus.NewGauge("binance_symbol_status", "Trading status of the symbol, 1 for the current status, e.g. TRADING, BREAK or HALT")
+	trading := prometheus.NewGauge("binance_symbol_trading", "1 while the symbol is trading, 0 while it is on break or halted")
+	tick := prometheus.NewGauge("binance_symbol_tick_size", "Price increment of the symbol")
+	step := prometheus.NewGauge("binance_symbol_step_size", "Quantity increment of the symbol")
+	minQty := prometheus.NewGauge("binance_symbol_min_quantity", "Smallest order quantity of the symbol")
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, s := range e.info.Symbols {
+		l := prometheus.L("symbol", s.Symbol)
+		status.Add(1, l, prometheus.L("status", s.Status))
+		isTrading := 0.0
+		if s.Status == "TRADING" {
+			isTrading = 1
+		}
+		trading.Add(isTrading, l)
+		if f, ok := s.Filter("PRICE_FILTER"); ok {
+			addParsed(tick, f.TickSize, l)
+		}
+		if f, ok := s.Filter("LOT_SIZE"); ok {
+			addParsed(step, f.StepSize, l)
+			addParsed(minQty, f.MinQty, l)
+		}
+	}
+	return []prometheus.Family{*status, *trading, *tick, *step, *minQty}
+}
@@ -0,0 +1,207 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+futures exports the open USDⓈ-M futures positions with their distance to liquidation, margin ratio and
+auto-deleveraging quantile, and the leverage and margin mode configured per symbol. Binance keeps a configuration for
+every symbol, the ones at the defaults of 20x cross margin without a position are left out.
+*/
+type futures struct {
+	permission
+	api       binance.BinanceAPI
+	lock      sync.Mutex
+	positions []binance.PositionRisk
+	account   *binance.FuturesAccount   // nil until the first collection
+	adl       map[string]map[string]int // symbol -> position side -> quantile
+	configs   []binance.SymbolConfig    // Of open positions and symbols configured away from the defaults
+	btcUSD    float64
+}
+
+const (
+	defaultLeverage   = 20
+	defaultMarginType = "CROSSED"
+)
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &futures{permission: permission{name: "futures", logger: l}, api: api}
+	})
+}
+
+func (f *futures) Name() string {
+	return f.name
+}
+
+func (f *futures) Enabled() bool {
+	return f.permitted()
+}
+
+// Weight of the position risk, account, ADL quantile and symbol config endpoints and the BTC price
+func (f *futures) Weight() int {
+	return 22
+}
+
+func (f *futures) Priority() Priority {
+	return PriorityHigh
+}
+
+func (f *futures) Collect(ctx context.Context) error {
+	positions, err := f.api.GetPositionRisk(ctx)
+	if err != nil {
+		return f.check(err)
+	}
+	open := make([]binance.PositionRisk, 0)
+	for _, p := range positions {
+		if parseOrZero(p.PositionAmt) != 0 {
+			open = append(open, p)
+		}
+	}
+	account, err := f.api.GetFuturesAccount(ctx)
+	if err != nil {
+		return f.check(err)
+	}
+	quantiles, err := f.api.GetADLQuantiles(ctx)
+	if err != nil {
+		return f.check(err)
+	}
+	adl := make(map[string]map[string]int, len(quantiles))
+	for _, q := range quantiles {
+		adl[q.Symbol] = q.AdlQuantile
+	}
+	all, err := f.api.GetFuturesSymbolConfigs(ctx)
+	if err != nil {
+		return f.check(err)
+	}
+	held := make(map[string]bool, len(open))
+	for _, p := range open {
+		held[p.Symbol] = true
+	}
+	configs := make([]binance.SymbolConfig, 0)
+	for _, c := range all {
+		if held[c.Symbol] || c.Leverage != defaultLeverage || c.MarginType != defaultMarginType {
+			configs = append(configs, c)
+		}
+	}
+
+	price, err := f.api.GetAvgPrice(ctx, usdSymbol)
+	if err != nil {
+		return err
+	}
+	btcUSD, err := strconv.ParseFloat(price.Price, 64)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.positions = open
+	f.account = &account
+	f.btcUSD = btcUSD
+	f.adl = adl
+	f.configs = configs
+	return nil
+}
+
+// ValueBTC converts the margin balance of the account, its wallet balance plus the unrealized profit, from USDT to BTC
+func (f *futures) ValueBTC() (float64, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.account == nil || f.btcUSD == 0 {
+		return 0, false
+	}
+	return parseOrZero(f.account.TotalMarginBalance) / f.btcUSD, true
+}
+
+func (f *futures) Gather() []prometheus.Family {
+	amount := prometheus.NewGauge("binance_futures_position_amount", "Size of the futures position in the base asset, negative for shorts")
+	pnl := prometheus.NewGauge("binance_futures_unrealized_profit", "Unrealized profit of the futures position in the margin asset")
+	leverage := prometheus.NewGauge("binance_futures_leverage", "Leverage of the futures position")
+	distance := prometheus.NewGauge("binance_liquidation_distance_ratio", "Relative distance of the current price to the liquidation price of the position, 0.1 is 10%")
+	maint := prometheus.NewGauge("binance_futures_maintenance_margin", "Maintenance margin of the futures position in the margin asset")
+	ratio := prometheus.NewGauge("binance_futures_margin_ratio", "Maintenance margin over margin balance of the futures position, liquidation at 1. Cross positions share the ratio of the account")
+	adl := prometheus.NewGauge("binance_futures_adl_quantile", "Auto-deleveraging queue position of the futures position from 0 to 4, 4 is deleveraged first")
+	accountRatio := prometheus.NewGauge("binance_futures_account_margin_ratio", "Maintenance margin over margin balance of the cross margin futures account, liquidation at 1")
+	symbolLeverage := prometheus.NewGauge("binance_futures_symbol_leverage", "Leverage configured for the futures symbol, new positions open with it")
+	marginMode := prometheus.NewGauge("binance_futures_symbol_margin_mode", "1 for the margin mode, cross or isolated, configured for the futures symbol")
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.account == nil {
+		return nil
+	}
+	crossRatio, crossOK := marginRatio(parseOrZero(f.account.TotalMaintMargin), parseOrZero(f.account.TotalMarginBalance))
+	if crossOK {
+		accountRatio.Add(crossRatio)
+	}
+	for _, p := range f.positions {
+		l := []prometheus.Label{prometheus.L("symbol", p.Symbol), prometheus.L("side", positionSide(p))}
+		addParsed(amount, p.PositionAmt, l...)
+		addParsed(pnl, p.UnRealizedProfit, l...)
+		addParsed(leverage, p.Leverage, l...)
+		if d, ok := liquidationDistance(parseOrZero(p.MarkPrice), parseOrZero(p.LiquidationPrice)); ok {
+			distance.Add(d, append([]prometheus.Label{prometheus.L("kind", "futures")}, l...)...)
+		}
+
+		m := f.maintMargin(p)
+		maint.Add(m, l...)
+		if p.MarginType == "isolated" {
+			if r, ok := marginRatio(m, parseOrZero(p.IsolatedWallet)+parseOrZero(p.UnRealizedProfit)); ok {
+				ratio.Add(r, l...)
+			}
+		} else if crossOK {
+			ratio.Add(crossRatio, l...)
+		}
+		if q, ok := f.adl[p.Symbol][p.PositionSide]; ok {
+			adl.Add(float64(q), l...)
+		}
+	}
+	for _, c := range f.configs {
+		symbolLeverage.Add(float64(c.Leverage), prometheus.L("symbol", c.Symbol))
+		mode := "cross"
+		if c.MarginType == "ISOLATED" {
+			mode = "isolated"
+		}
+		marginMode.Add(1, prometheus.L("symbol", c.Symbol), prometheus.L("mode", mode))
+	}
+	return []prometheus.Family{*amount, *pnl, *leverage, *distance, *maint, *ratio, *adl, *accountRatio, *symbolLeverage, *marginMode}
+}
+
+// maintMargin looks up the maintenance margin of the position in the account, which positionRisk doesn't carry
+func (f *futures) maintMargin(p binance.PositionRisk) float64 {
+	for _, a := range f.account.Positions {
+		if a.Symbol == p.Symbol && a.PositionSide == p.PositionSide {
+			return parseOrZero(a.MaintMargin)
+		}
+	}
+	return 0
+}
+
+func marginRatio(maint, balance float64) (float64, bool) {
+	if balance <= 0 {
+		return 0, false
+	}
+	return maint / balance, true
+}
+
+// positionSide is long or short, in one-way mode binance reports BOTH and the sign of the amount tells
+func positionSide(p binance.PositionRisk) string {
+	switch p.PositionSide {
+	case "LONG", "SHORT":
+		return strings.ToLower(p.PositionSide)
+	}
+	if parseOrZero(p.PositionAmt) < 0 {
+		return "short"
+	}
+	return "long"
+}
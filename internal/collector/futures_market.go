@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// longShortPeriod is the period the long/short account ratio is taken over
+const longShortPeriod = "5m"
+
+/*
+futuresMarket exports exchange level risk context of the configured USDⓈ-M futures symbols: the balance of the
+insurance fund covering their liquidations and the share of accounts long and short. Neither depends on the account,
+they are context series for risk dashboards.
+*/
+type futuresMarket struct {
+	api     binance.BinanceAPI
+	symbols []string
+	lock    sync.Mutex
+	funds   map[string]binance.InsuranceFund // By symbol
+	ratios  []binance.LongShortRatio
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &futuresMarket{api: api, symbols: cfg.Market.FuturesSymbols}
+	})
+}
+
+func (f *futuresMarket) Name() string {
+	return "futures_market"
+}
+
+func (f *futuresMarket) Enabled() bool {
+	return len(f.symbols) > 0
+}
+
+// Weight of the insurance fund and the long/short ratio of every symbol
+func (f *futuresMarket) Weight() int {
+	return 2 * len(f.symbols)
+}
+
+func (f *futuresMarket) Priority() Priority {
+	return PriorityLow
+}
+
+// DefaultInterval is the period of the long/short ratio, binance doesn't update it in between
+func (f *futuresMarket) DefaultInterval() time.Duration {
+	return 5 * time.Minute
+}
+
+func (f *futuresMarket) Collect(ctx context.Context) error {
+	funds := make(map[string]binance.InsuranceFund, len(f.symbols))
+	ratios := make([]binance.LongShortRatio, 0, len(f.symbols))
+	for _, symbol := range f.symbols {
+		fund, err := f.api.GetInsuranceFund(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		funds[symbol] = fund
+		ratio, err := f.api.GetLongShortRatio(ctx, symbol, longShortPeriod)
+		if err != nil {
+			return err
+		}
+		ratios = append(ratios, ratio)
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.funds = funds
+	f.ratios = ratios
+	return nil
+}
+
+func (f *futuresMarket) Gather() []prometheus.Family {
+	fund := prometheus.NewGauge("binance_futures_insurance_fund_balance", "Balance of the insurance fund covering the liquidations of the futures symbol, symbols sharing a fund report the same balance")
+	ratio := prometheus.NewGauge("binance_futures_long_short_account_ratio", "Accounts long the futures symbol over accounts short it over the last "+longShortPeriod)
+	long := prometheus.NewGauge("binance_futures_long_account_share", "Share of the accounts with a position in the futures symbol that are long, 0.6 is 60%")
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, symbol := range f.symbols {
+		for _, a := range f.funds[symbol].Assets {
+			addParsed(fund, a.MarginBalance, prometheus.L("symbol", symbol), prometheus.L("asset", a.Asset))
+		}
+	}
+	for _, r := range f.ratios {
+		l := prometheus.L("symbol", r.Symbol)
+		addParsed(ratio, r.LongShortRatio, l)
+		addParsed(long, r.LongAccount, l)
+	}
+	return []prometheus.Family{*fund, *ratio, *long}
+}
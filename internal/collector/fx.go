@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/fx"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+fxRates fetches the exchange rates of the configured currencies from EXPORTER_FX_URL, the totals are reported in them
+as well. Reference rates are published once a day, so the rates are refreshed on their own interval instead of every
+poll cycle, and a failed fetch keeps the last rates.
+*/
+type fxRates struct {
+	source     *fx.Source
+	currencies []string
+	interval   time.Duration
+	lock       sync.Mutex
+	rates      fx.Rates // Of the configured currencies only, nil until fetched
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &fxRates{
+			source:     fx.New(cfg.FX.URL, cfg.UserAgent, cfg.FX.Timeout),
+			currencies: cfg.FX.Currencies,
+			interval:   cfg.FX.Interval,
+		}
+	})
+}
+
+func (f *fxRates) Name() string {
+	return "fx"
+}
+
+func (f *fxRates) Enabled() bool {
+	return len(f.currencies) > 0
+}
+
+// Weight is 0, the rates don't come from binance
+func (f *fxRates) Weight() int {
+	return 0
+}
+
+func (f *fxRates) DefaultInterval() time.Duration {
+	return f.interval
+}
+
+func (f *fxRates) Collect(ctx context.Context) error {
+	all, err := f.source.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	rates := make(fx.Rates, len(f.currencies))
+	missing := make([]string, 0)
+	for _, currency := range f.currencies {
+		if rate, ok := all[currency]; ok {
+			rates[currency] = rate
+		} else {
+			missing = append(missing, currency)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("fx rates have no rate for %s of EXPORTER_FX_CURRENCIES", strings.Join(missing, ", "))
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.rates = rates
+	return nil
+}
+
+func (f *fxRates) Gather() []prometheus.Family {
+	rate := prometheus.NewGauge("binance_fx_rate", "Units of the currency per US dollar")
+
+	rates := f.current()
+	if rates == nil {
+		return nil
+	}
+	currencies := make([]string, 0, len(rates))
+	for currency := range rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		rate.Add(rates[currency], prometheus.L("currency", currency))
+	}
+	return []prometheus.Family{*rate}
+}
+
+// current returns the last rates, nil until they were fetched
+func (f *fxRates) current() fx.Rates {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.rates
+}
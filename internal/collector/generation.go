@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/fx"
+)
+
+/*
+generation is the state of the holdings once a poll cycle completed. Totals and snapshots are computed from the last
+generation instead of the live collectors, so a scrape in the middle of a cycle never sums up wallets the cycle
+refreshed already with wallets it didn't get to yet.
+*/
+type generation struct {
+	cycle      uint64 // Id of the cycle, increasing with every cycle the registry runs
+	time       time.Time
+	wallets    map[balance.Wallet][]balance.Balance // Balances by wallet, shared with the wallet snapshots and never modified
+	valuations map[string]float64                   // Net value in BTC by collector, only collectors whose value is known
+	btcUSD     float64                              // 0 while the price is unknown
+	fx         fx.Rates                             // Rates of EXPORTER_FX_CURRENCIES, nil while unknown
+}
+
+// publish stores the state of the collectors as the generation of the cycle, unless a later cycle published already
+func (r *Registry) publish(cycle uint64) {
+	g := &generation{
+		cycle:      cycle,
+		time:       r.clock.Now().UTC(),
+		wallets:    make(map[balance.Wallet][]balance.Balance),
+		valuations: make(map[string]float64),
+	}
+	for _, c := range r.collectors {
+		if !c.Enabled() {
+			continue
+		}
+		switch c := c.(type) {
+		case *wallet:
+			g.wallets[c.name] = c.balances()
+		case *totals:
+			g.btcUSD = c.price()
+		case *fxRates:
+			g.fx = c.current()
+		}
+		if v, ok := c.(Valuer); ok {
+			if value, ok := v.ValueBTC(); ok {
+				g.valuations[c.Name()] = value
+			}
+		}
+	}
+	for {
+		current := r.generation.Load()
+		if current != nil && current.cycle > cycle {
+			return
+		}
+		if r.generation.CompareAndSwap(current, g) {
+			return
+		}
+	}
+}
+
+// lastGeneration returns the state after the last completed cycle, nil before the first one
+func (r *Registry) lastGeneration() *generation {
+	return r.generation.Load()
+}
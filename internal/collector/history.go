@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+// historyOverlap is queried again on every collection, so records binance lists late are still counted once
+const historyOverlap = 10 * time.Minute
+
+var evictions = prometheus.NewCounterVec("binance_evicted_entries_total", "Entries dropped from in-memory state because it reached its configured bound", "collector", "kind")
+
+func init() {
+	prometheus.Default.MustRegister(evictions)
+}
+
+/*
+history tracks where the next query of every history source starts and which records were counted already, so
+collectors turning account history into counters count every record exactly once. The first query of a source starts
+lookback ago. At most limit ids are remembered, the oldest ones are evicted first and then risk being counted again if a
+query still returns them. It is not safe for concurrent use, collectors guard it with their lock.
+*/
+type history struct {
+	collector string
+	lookback  time.Duration
+	limit     int
+	since     map[string]time.Time // Start of the next query by source
+	counted   map[string]time.Time // Ids already counted with their time, dropped once they are older than any query
+}
+
+// historyState is a history as kept in the store
+type historyState struct {
+	Since   map[string]time.Time `json:"since"`
+	Counted map[string]time.Time `json:"counted"`
+}
+
+func newHistory(collector string, cfg config.History) history {
+	return history{
+		collector: collector,
+		lookback:  cfg.Lookback,
+		limit:     cfg.MaxEntries,
+		since:     make(map[string]time.Time),
+		counted:   make(map[string]time.Time),
+	}
+}
+
+// from returns where the next query of the source starts
+func (h *history) from(source string, now time.Time) time.Time {
+	if since, ok := h.since[source]; ok {
+		return since
+	}
+	return now.Add(-h.lookback)
+}
+
+// count marks the record as counted and reports whether it wasn't before
+func (h *history) count(id string, at time.Time) bool {
+	if _, ok := h.counted[id]; ok {
+		return false
+	}
+	if len(h.counted) >= h.limit {
+		h.evict()
+	}
+	h.counted[id] = at
+	return true
+}
+
+// evict forgets the oldest tenth of the counted ids, so evicting doesn't have to run on every new record
+func (h *history) evict() {
+	ids := make([]string, 0, len(h.counted))
+	for id := range h.counted {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return h.counted[ids[i]].Before(h.counted[ids[j]]) })
+	n := len(ids)/10 + 1
+	for _, id := range ids[:n] {
+		delete(h.counted, id)
+	}
+	evictions.Add(float64(n), h.collector, "history_ids")
+}
+
+// advance moves the start of the next query of the source and forgets ids no query returns anymore
+func (h *history) advance(source string, next time.Time) {
+	h.since[source] = next
+	oldest := next
+	for _, since := range h.since {
+		if since.Before(oldest) {
+			oldest = since
+		}
+	}
+	for id, at := range h.counted {
+		if at.Before(oldest) {
+			delete(h.counted, id)
+		}
+	}
+}
+
+// started reports whether any source was queried yet
+func (h *history) started() bool {
+	return len(h.since) > 0
+}
+
+// state returns the history to store, sharing its maps
+func (h *history) state() historyState {
+	return historyState{Since: h.since, Counted: h.counted}
+}
+
+// restore continues from a stored history, a source not stored starts lookback ago again
+func (h *history) restore(state historyState) {
+	for source, since := range state.Since {
+		h.since[source] = since
+	}
+	for id, at := range state.Counted {
+		h.counted[id] = at
+	}
+}
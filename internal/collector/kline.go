@@ -0,0 +1,180 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	hourlyKlines     = 24*7 + 1 // Enough hourly candles for the 7d return
+	dailyKlines      = 200      // Enough daily candles for the SMA200
+	volatilityWindow = 30       // Daily returns the realized volatility is computed over
+)
+
+/*
+kline derives simple signal indicators from recent klines of the configured symbols, so basic alerts can be written
+in PromQL. Returns are rolling and based on hourly candles, volatility and moving averages on daily closes.
+*/
+type kline struct {
+	api        binance.BinanceAPI
+	symbols    []string
+	lock       sync.Mutex
+	indicators map[string]indicators
+}
+
+// indicators of one symbol, NaN where there were not enough candles
+type indicators struct {
+	return24h  float64
+	return7d   float64
+	volatility float64
+	sma50      float64
+	sma200     float64
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &kline{api: api, symbols: cfg.Market.KlineSymbols}
+	})
+}
+
+func (k *kline) Name() string {
+	return "kline"
+}
+
+func (k *kline) Enabled() bool {
+	return len(k.symbols) > 0
+}
+
+// Weight of the hourly and daily candles of every symbol
+func (k *kline) Weight() int {
+	return 4 * len(k.symbols)
+}
+
+func (k *kline) Priority() Priority {
+	return PriorityLow
+}
+
+func (k *kline) Collect(ctx context.Context) error {
+	res := make(map[string]indicators, len(k.symbols))
+	for _, symbol := range k.symbols {
+		hourly, err := k.api.GetKlines(ctx, symbol, "1h", hourlyKlines)
+		if err != nil {
+			return err
+		}
+		daily, err := k.api.GetKlines(ctx, symbol, "1d", dailyKlines)
+		if err != nil {
+			return err
+		}
+		hourlyCloses, dailyCloses := closes(hourly), closes(daily)
+		res[symbol] = indicators{
+			return24h:  returnOver(hourlyCloses, 24),
+			return7d:   returnOver(hourlyCloses, 24*7),
+			volatility: realizedVolatility(dailyCloses, volatilityWindow),
+			sma50:      sma(dailyCloses, 50),
+			sma200:     sma(dailyCloses, 200),
+		}
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.indicators = res
+	return nil
+}
+
+func (k *kline) Gather() []prometheus.Family {
+	returns := prometheus.NewGauge("binance_kline_return_ratio", "Rolling return of the symbol over the window, 0.05 is 5%")
+	volatility := prometheus.NewGauge("binance_kline_realized_volatility", "Annualized standard deviation of the daily log returns of the symbol over the window")
+	smas := prometheus.NewGauge("binance_kline_sma", "Simple moving average of the daily closes of the symbol over period days")
+	cross := prometheus.NewGauge("binance_kline_sma_cross", "1 while the SMA50 of the symbol is above its SMA200, -1 while below")
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	for _, symbol := range k.symbols {
+		i, ok := k.indicators[symbol]
+		if !ok {
+			continue
+		}
+		s := prometheus.L("symbol", symbol)
+		addFinite(returns, i.return24h, s, prometheus.L("window", "24h"))
+		addFinite(returns, i.return7d, s, prometheus.L("window", "7d"))
+		addFinite(volatility, i.volatility, s, prometheus.L("window", "30d"))
+		addFinite(smas, i.sma50, s, prometheus.L("period", "50"))
+		addFinite(smas, i.sma200, s, prometheus.L("period", "200"))
+		if !math.IsNaN(i.sma50) && !math.IsNaN(i.sma200) {
+			state := -1.0
+			if i.sma50 > i.sma200 {
+				state = 1
+			}
+			cross.Add(state, s)
+		}
+	}
+	return []prometheus.Family{*returns, *volatility, *smas, *cross}
+}
+
+// addFinite skips indicators that could not be computed
+func addFinite(f *prometheus.Family, value float64, labels ...prometheus.Label) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+	f.Add(value, labels...)
+}
+
+func closes(klines []binance.Kline) []float64 {
+	res := make([]float64, 0, len(klines))
+	for _, k := range klines {
+		res = append(res, parseOrZero(k.Close))
+	}
+	return res
+}
+
+// returnOver is the return from the close periods candles ago to the latest close
+func returnOver(closes []float64, periods int) float64 {
+	if len(closes) <= periods || closes[len(closes)-1-periods] <= 0 {
+		return math.NaN()
+	}
+	return closes[len(closes)-1]/closes[len(closes)-1-periods] - 1
+}
+
+// realizedVolatility is the annualized sample standard deviation of the last window daily log returns
+func realizedVolatility(closes []float64, window int) float64 {
+	if len(closes) <= window {
+		return math.NaN()
+	}
+	recent := closes[len(closes)-1-window:]
+	returns := make([]float64, 0, window)
+	for i := 1; i < len(recent); i++ {
+		if recent[i-1] <= 0 || recent[i] <= 0 {
+			return math.NaN()
+		}
+		returns = append(returns, math.Log(recent[i]/recent[i-1]))
+	}
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+	// Crypto trades every day of the year
+	return math.Sqrt(variance * 365)
+}
+
+func sma(closes []float64, period int) float64 {
+	if len(closes) < period {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, c := range closes[len(closes)-period:] {
+		sum += c
+	}
+	return sum / float64(period)
+}
@@ -0,0 +1,35 @@
+package collector
+
+import (
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+/*
+assetLabels returns the labels identifying an asset: the asset itself plus alias, group and category labels when those
+are configured. Groups are looked up by asset first, then by alias, so WBTC -> BTC -> majors works.
+*/
+func assetLabels(cfg config.Assets, asset string) []prometheus.Label {
+	labels := []prometheus.Label{prometheus.L("asset", asset)}
+	alias, ok := cfg.Aliases[asset]
+	if !ok {
+		alias = asset
+	}
+	if len(cfg.Aliases) > 0 {
+		labels = append(labels, prometheus.L("alias", alias))
+	}
+	if len(cfg.Groups) > 0 {
+		group, ok := cfg.Groups[asset]
+		if !ok {
+			group, ok = cfg.Groups[alias]
+		}
+		if !ok {
+			group = cfg.DefaultGroup
+		}
+		labels = append(labels, prometheus.L("group", group))
+	}
+	if cfg.CategoryLabel {
+		labels = append(labels, prometheus.L("category", coinCategories.of(asset)))
+	}
+	return labels
+}
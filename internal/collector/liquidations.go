@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+liquidations counts the forced orders binance streams for the configured futures symbols, a market stress signal that
+is not tied to the account. Binance streams the largest liquidation per symbol and second, the counters are a lower
+bound of all liquidations.
+*/
+type liquidations struct {
+	streamer
+	api      binance.BinanceAPI
+	symbols  []string
+	count    *prometheus.Vec
+	notional *prometheus.Vec
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &liquidations{
+			streamer: streamer{name: "liquidations", logger: l},
+			api:      api,
+			symbols:  cfg.Market.LiquidationSymbols,
+			count:    prometheus.NewCounterVec("binance_liquidations_total", "Liquidations of futures positions of the symbol streamed by binance, by side of the liquidated position", "symbol", "side"),
+			notional: prometheus.NewCounterVec("binance_liquidation_notional_total", "Notional value of the liquidated futures positions of the symbol in the quote asset, by side of the liquidated position", "symbol", "side"),
+		}
+	})
+}
+
+func (l *liquidations) Name() string {
+	return l.name
+}
+
+func (l *liquidations) Enabled() bool {
+	return len(l.symbols) > 0
+}
+
+// Weight is 0, streams don't count towards the request weight
+func (l *liquidations) Weight() int {
+	return 0
+}
+
+func (l *liquidations) Priority() Priority {
+	return PriorityLow
+}
+
+func (l *liquidations) Collect(context.Context) error {
+	return l.status(func(ctx context.Context) error {
+		return l.api.StreamLiquidations(ctx, l.symbols, l.observe)
+	})
+}
+
+func (l *liquidations) observe(o binance.Liquidation) {
+	side := "long"
+	if o.Side == "BUY" {
+		side = "short"
+	}
+	l.count.Inc(o.Symbol, side)
+	qty, qtyErr := strconv.ParseFloat(o.Quantity, 64)
+	price, priceErr := strconv.ParseFloat(o.AveragePrice, 64)
+	if qtyErr == nil && priceErr == nil {
+		l.notional.Add(qty*price, o.Symbol, side)
+	}
+}
+
+func (l *liquidations) Gather() []prometheus.Family {
+	return append(l.count.Gather(), l.notional.Gather()...)
+}
@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+)
+
+// listingInterval is how long the symbols binance lists are cached for
+const listingInterval = 24 * time.Hour
+
+/*
+listing caches the symbols binance trades, for collectors that pick their symbols from the assets of the account
+instead of a configured list. The full exchangeInfo costs 20 request weight, so it is refreshed once a day.
+*/
+type listing struct {
+	lock    sync.Mutex
+	symbols map[string]binance.SymbolInfo // Only the TRADING ones
+	listed  time.Time
+	clock   clock.Clock // nil is the wall clock
+}
+
+// clocked is implemented by the collectors caching the listing, so the clock of the registry times their refreshes
+type clocked interface {
+	useClock(c clock.Clock)
+}
+
+func (l *listing) useClock(c clock.Clock) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.clock = c
+}
+
+// now returns the time of the clock, the caller holds the lock
+func (l *listing) now() time.Time {
+	if l.clock == nil {
+		return time.Now()
+	}
+	return l.clock.Now()
+}
+
+// due returns whether the next refresh fetches the listing
+func (l *listing) due() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.now().Sub(l.listed) > listingInterval
+}
+
+// refresh fetches the listing if it is due
+func (l *listing) refresh(ctx context.Context, api binance.BinanceAPI) error {
+	if !l.due() {
+		return nil
+	}
+	info, err := api.GetExchangeInfo(ctx, nil)
+	if err != nil {
+		return err
+	}
+	symbols := make(map[string]binance.SymbolInfo, len(info.Symbols))
+	for _, s := range info.Symbols {
+		if s.Status == "TRADING" {
+			symbols[s.Symbol] = s
+		}
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.symbols = symbols
+	l.listed = l.now()
+	return nil
+}
+
+// trades returns whether binance trades the symbol
+func (l *listing) trades(symbol string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	_, ok := l.symbols[symbol]
+	return ok
+}
+
+// pair returns the traded symbol of the two assets and whether base is its quote asset, false if there is none
+func (l *listing) pair(base, quote string) (string, bool, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, ok := l.symbols[base+quote]; ok {
+		return base + quote, false, true
+	}
+	if _, ok := l.symbols[quote+base]; ok {
+		return quote + base, true, true
+	}
+	return "", false, false
+}
@@ -0,0 +1,35 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"go.uber.org/zap"
+)
+
+func TestListingRefreshesDaily(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	l := &listing{}
+	l.useClock(fake)
+	if !l.due() {
+		t.Fatal("an empty listing is not due")
+	}
+	if err := l.refresh(context.Background(), binance.NewDemoClient(zap.NewNop())); err != nil {
+		t.Fatal(err)
+	}
+	if !l.trades("BTCUSDT") {
+		t.Error("listing doesn't trade BTCUSDT after the refresh")
+	}
+
+	fake.Advance(listingInterval)
+	if l.due() {
+		t.Errorf("listing is due %s after the refresh", listingInterval)
+	}
+	fake.Advance(time.Second)
+	if !l.due() {
+		t.Errorf("listing is not due %s after the refresh", listingInterval+time.Second)
+	}
+}
@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/maintenance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// maintenanceGrace is how long a window stays exported after its start, most maintenance takes a few hours
+const maintenanceGrace = 24 * time.Hour
+
+/*
+scheduledMaintenance exports the system maintenance binance announced on binance.com, so alerts can be silenced and
+bots paused before a window starts. Binance announces maintenance days ahead, the announcements are fetched on their own
+interval and a failed fetch keeps the last windows.
+*/
+type scheduledMaintenance struct {
+	feed     *maintenance.Feed
+	enabled  bool
+	interval time.Duration
+	lock     sync.Mutex
+	windows  []maintenance.Window // nil until fetched
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &scheduledMaintenance{
+			feed:     maintenance.New(cfg.Maintenance.URL, cfg.UserAgent, cfg.Maintenance.Timeout),
+			enabled:  cfg.Maintenance.Enabled,
+			interval: cfg.Maintenance.Interval,
+		}
+	})
+}
+
+func (m *scheduledMaintenance) Name() string {
+	return "maintenance"
+}
+
+func (m *scheduledMaintenance) Enabled() bool {
+	return m.enabled
+}
+
+// Weight is 0, the announcements don't come from the binance API
+func (m *scheduledMaintenance) Weight() int {
+	return 0
+}
+
+func (m *scheduledMaintenance) DefaultInterval() time.Duration {
+	return m.interval
+}
+
+func (m *scheduledMaintenance) Collect(ctx context.Context) error {
+	windows, err := m.feed.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.windows = windows
+	return nil
+}
+
+func (m *scheduledMaintenance) Gather() []prometheus.Family {
+	scheduled := prometheus.NewGauge("binance_maintenance_scheduled_timestamp_seconds", "Unix time the announced system maintenance starts, until a day after its start")
+	announced := prometheus.NewGauge("binance_maintenance_announced_timestamp_seconds", "Unix time the system maintenance was announced")
+	next := prometheus.NewGauge("binance_maintenance_next_timestamp_seconds", "Unix time the next announced system maintenance starts, absent while none is scheduled")
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.windows == nil {
+		return nil
+	}
+	now := time.Now()
+	upcoming := false
+	for _, w := range m.windows {
+		if now.Sub(w.Start) > maintenanceGrace {
+			continue
+		}
+		labels := []prometheus.Label{prometheus.L("announcement", w.ID), prometheus.L("title", w.Title)}
+		scheduled.Add(float64(w.Start.Unix()), labels...)
+		announced.Add(float64(w.Announced.Unix()), labels...)
+		if !upcoming && w.Start.After(now) {
+			next.Add(float64(w.Start.Unix()))
+			upcoming = true
+		}
+	}
+	return []prometheus.Family{*scheduled, *announced, *next}
+}
@@ -0,0 +1,169 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// margin exports the margin level and balances of the cross margin account
+type margin struct {
+	permission
+	api     binance.BinanceAPI
+	lock    sync.Mutex
+	account *binance.CrossMarginAccount
+}
+
+// isolatedMargin exports the margin level and liquidation distance of every isolated margin pair
+type isolatedMargin struct {
+	permission
+	api     binance.BinanceAPI
+	lock    sync.Mutex
+	account *binance.IsolatedMarginAccount
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &margin{permission: permission{name: "margin", logger: l}, api: api}
+	})
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &isolatedMargin{permission: permission{name: "isolated_margin", logger: l}, api: api}
+	})
+}
+
+func (m *margin) Name() string {
+	return m.name
+}
+
+func (m *margin) Enabled() bool {
+	return m.permitted()
+}
+
+func (m *margin) Weight() int {
+	return 10
+}
+
+func (m *margin) Priority() Priority {
+	return PriorityHigh
+}
+
+func (m *margin) Collect(ctx context.Context) error {
+	account, err := m.api.GetCrossMarginAccount(ctx)
+	if err != nil {
+		return m.check(err)
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.account = &account
+	return nil
+}
+
+func (m *margin) ValueBTC() (float64, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.account == nil {
+		return 0, false
+	}
+	return parseOrZero(m.account.TotalNetAssetOfBtc), true
+}
+
+func (m *margin) Gather() []prometheus.Family {
+	level := prometheus.NewGauge("binance_margin_level", "Margin level of the margin account, total assets over liabilities, liquidation starts at 1.1")
+	assets := prometheus.NewGauge("binance_margin_total_asset_btc", "Total assets of the cross margin account in BTC")
+	liabilities := prometheus.NewGauge("binance_margin_total_liability_btc", "Total liabilities of the cross margin account in BTC")
+	net := prometheus.NewGauge("binance_margin_net_asset_btc", "Net assets of the margin account in BTC")
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.account == nil {
+		return nil
+	}
+	l := prometheus.L("account", "cross")
+	// Without any debt binance reports a huge margin level, which isn't worth alerting on
+	if parseOrZero(m.account.TotalLiabilityOfBtc) > 0 {
+		addParsed(level, m.account.MarginLevel, l)
+	}
+	addParsed(assets, m.account.TotalAssetOfBtc, l)
+	addParsed(liabilities, m.account.TotalLiabilityOfBtc, l)
+	addParsed(net, m.account.TotalNetAssetOfBtc, l)
+	return []prometheus.Family{*level, *assets, *liabilities, *net}
+}
+
+func (m *isolatedMargin) Name() string {
+	return m.name
+}
+
+func (m *isolatedMargin) Enabled() bool {
+	return m.permitted()
+}
+
+func (m *isolatedMargin) Weight() int {
+	return 10
+}
+
+func (m *isolatedMargin) Priority() Priority {
+	return PriorityHigh
+}
+
+func (m *isolatedMargin) Collect(ctx context.Context) error {
+	account, err := m.api.GetIsolatedMarginAccount(ctx)
+	if err != nil {
+		return m.check(err)
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.account = &account
+	return nil
+}
+
+func (m *isolatedMargin) ValueBTC() (float64, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.account == nil {
+		return 0, false
+	}
+	return parseOrZero(m.account.TotalNetAssetOfBtc), true
+}
+
+func (m *isolatedMargin) Gather() []prometheus.Family {
+	level := prometheus.NewGauge("binance_margin_level", "Margin level of the margin account, total assets over liabilities, liquidation starts at 1.1")
+	net := prometheus.NewGauge("binance_margin_net_asset_btc", "Net assets of the margin account in BTC")
+	distance := prometheus.NewGauge("binance_liquidation_distance_ratio", "Relative distance of the current price to the liquidation price of the position, 0.1 is 10%")
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.account == nil {
+		return nil
+	}
+	account := prometheus.L("account", "isolated")
+	addParsed(net, m.account.TotalNetAssetOfBtc, account)
+	for _, pair := range m.account.Assets {
+		if !pair.Enabled {
+			continue
+		}
+		symbol := prometheus.L("symbol", pair.Symbol)
+		liquidation := parseOrZero(pair.LiquidatePrice)
+		// Pairs without debt have no liquidation price
+		if liquidation <= 0 {
+			continue
+		}
+		addParsed(level, pair.MarginLevel, account, symbol)
+		if d, ok := liquidationDistance(parseOrZero(pair.IndexPrice), liquidation); ok {
+			distance.Add(d, prometheus.L("kind", "isolated_margin"), symbol)
+		}
+	}
+	return []prometheus.Family{*level, *net, *distance}
+}
+
+// liquidationDistance is how far price has to move, relative to itself, to reach the liquidation price
+func liquidationDistance(price, liquidation float64) (float64, bool) {
+	if price <= 0 || liquidation <= 0 {
+		return 0, false
+	}
+	return math.Abs(price-liquidation) / price, true
+}
@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+options exports the options account and its open positions. Only the basics are exported, the Greeks depend on a
+pricing model and are left to whatever trades the positions. The USDT equity counts towards the totals at the BTCUSDT
+price.
+*/
+type options struct {
+	permission
+	api       binance.BinanceAPI
+	enabled   bool
+	lock      sync.Mutex
+	account   *binance.OptionsAccount // nil until the first collection
+	positions []binance.OptionsPosition
+	btcUSD    float64
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &options{
+			permission: permission{name: "options", logger: l},
+			api:        api,
+			enabled:    cfg.Derivatives.Options,
+		}
+	})
+}
+
+func (o *options) Name() string {
+	return o.name
+}
+
+func (o *options) Enabled() bool {
+	return o.enabled && o.permitted()
+}
+
+// Weight of the margin account and position endpoints and the BTC price
+func (o *options) Weight() int {
+	return 10
+}
+
+func (o *options) Priority() Priority {
+	return PriorityHigh
+}
+
+func (o *options) Collect(ctx context.Context) error {
+	account, err := o.api.GetOptionsAccount(ctx)
+	if err != nil {
+		return o.check(err)
+	}
+	positions, err := o.api.GetOptionsPositions(ctx)
+	if err != nil {
+		return o.check(err)
+	}
+	price, err := o.api.GetAvgPrice(ctx, usdSymbol)
+	if err != nil {
+		return err
+	}
+	btcUSD, err := strconv.ParseFloat(price.Price, 64)
+	if err != nil {
+		return err
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.account, o.positions, o.btcUSD = &account, positions, btcUSD
+	return nil
+}
+
+// ValueBTC converts the equity of the account, which includes the mark value of the positions, to BTC
+func (o *options) ValueBTC() (float64, bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.account == nil || o.btcUSD == 0 {
+		return 0, false
+	}
+	equity := 0.0
+	for _, a := range o.account.Assets {
+		equity += parseOrZero(a.Equity)
+	}
+	return equity / o.btcUSD, true
+}
+
+func (o *options) Gather() []prometheus.Family {
+	equity := prometheus.NewGauge("binance_options_account_equity", "Equity of the options account, its margin balance plus the mark value of the positions")
+	margin := prometheus.NewGauge("binance_options_account_margin_balance", "Margin balance of the options account")
+	available := prometheus.NewGauge("binance_options_account_available", "Funds of the options account available for new orders")
+	locked := prometheus.NewGauge("binance_options_account_locked", "Funds of the options account locked by open orders")
+	pnl := prometheus.NewGauge("binance_options_account_unrealized_pnl", "Unrealized profit of all positions of the options account")
+	risk := prometheus.NewGauge("binance_options_account_risk_level", "Risk level of the options account, 1 for the current one")
+	quantity := prometheus.NewGauge("binance_options_position_quantity", "Contracts held of the options symbol")
+	markValue := prometheus.NewGauge("binance_options_position_mark_value", "Value of the position at the mark price, in the quote asset")
+	unrealized := prometheus.NewGauge("binance_options_position_unrealized_pnl", "Unrealized profit of the position at the mark price, in the quote asset")
+	expiry := prometheus.NewGauge("binance_options_position_expiry_timestamp_seconds", "Unix time the options symbol expires at")
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.account == nil {
+		return nil
+	}
+	for _, a := range o.account.Assets {
+		l := []prometheus.Label{balance.Options.Label(), prometheus.L("asset", a.Asset)}
+		addParsed(equity, a.Equity, l...)
+		addParsed(margin, a.MarginBalance, l...)
+		addParsed(available, a.Available, l...)
+		addParsed(locked, a.Locked, l...)
+		addParsed(pnl, a.UnrealizedPNL, l...)
+	}
+	risk.Add(1, prometheus.L("level", o.account.RiskLevel))
+	for _, p := range o.positions {
+		labels := []prometheus.Label{prometheus.L("symbol", p.Symbol), prometheus.L("side", p.Side), prometheus.L("option_side", p.OptionSide)}
+		addParsed(quantity, p.Quantity, labels...)
+		addParsed(markValue, p.MarkValue, labels...)
+		addParsed(unrealized, p.UnrealizedPNL, labels...)
+		expiry.Add(float64(p.ExpiryDate/1000), prometheus.L("symbol", p.Symbol))
+	}
+	return []prometheus.Family{*equity, *margin, *available, *locked, *pnl, *risk, *quantity, *markValue, *unrealized, *expiry}
+}
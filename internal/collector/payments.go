@@ -0,0 +1,208 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// c2cFinal are the P2P order states that don't change anymore, orders are only counted once they reach one
+var c2cFinal = map[string]bool{"COMPLETED": true, "CANCELLED": true, "CANCELLED_BY_SYSTEM": true}
+
+type (
+	/*
+		payments counts Binance Pay transactions and P2P orders by type, status and asset, so merchants can reconcile
+		incoming payments in grafana. Every collection queries the history since the previous one and counts what wasn't
+		counted yet, the first one starts EXPORTER_HISTORY_LOOKBACK_DAYS back.
+	*/
+	payments struct {
+		permission
+		api     binance.BinanceAPI
+		enabled bool
+		lock    sync.Mutex
+		history history // Sources are pay and the C2C trade types
+		pay     map[payKey]*tally
+		c2c     map[c2cKey]*tally
+	}
+	payKey struct {
+		orderType, asset, direction string
+	}
+	c2cKey struct {
+		side, status, asset, fiat string
+	}
+	tally struct {
+		count, volume, fiatVolume float64
+	}
+)
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &payments{
+			permission: permission{name: "payments", logger: l},
+			api:        api,
+			enabled:    cfg.History.Payments,
+			history:    newHistory("payments", cfg.History),
+			pay:        make(map[payKey]*tally),
+			c2c:        make(map[c2cKey]*tally),
+		}
+	})
+}
+
+func (p *payments) Name() string {
+	return p.name
+}
+
+func (p *payments) Enabled() bool {
+	return p.enabled && p.permitted()
+}
+
+func (p *payments) Weight() int {
+	return 100
+}
+
+func (p *payments) Priority() Priority {
+	return PriorityLow
+}
+
+// DefaultInterval is long since the Pay history costs thousands of request weight per call
+func (p *payments) DefaultInterval() time.Duration {
+	return 10 * time.Minute
+}
+
+func (p *payments) Collect(ctx context.Context) error {
+	now := time.Now()
+	transactions, err := p.api.GetPayTransactions(ctx, p.from("pay", now), now)
+	if err != nil {
+		return p.check(err)
+	}
+	p.countPay(transactions, now)
+
+	for _, side := range []string{"BUY", "SELL"} {
+		orders, err := p.api.GetC2COrders(ctx, side, p.from(side, now), now)
+		if err != nil {
+			return p.check(err)
+		}
+		p.countC2C(side, orders, now)
+	}
+	return nil
+}
+
+func (p *payments) from(source string, now time.Time) time.Time {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.history.from(source, now)
+}
+
+func (p *payments) countPay(transactions []binance.PayTransaction, now time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, t := range transactions {
+		if !p.history.count("pay:"+t.TransactionID, time.UnixMilli(t.TransactionTime)) {
+			continue
+		}
+		amount := parseOrZero(t.Amount)
+		direction := "in"
+		if amount < 0 {
+			direction = "out"
+		}
+		key := payKey{orderType: t.OrderType, asset: t.Currency, direction: direction}
+		if p.pay[key] == nil {
+			p.pay[key] = &tally{}
+		}
+		p.pay[key].count++
+		p.pay[key].volume += math.Abs(amount)
+	}
+	p.history.advance("pay", now.Add(-historyOverlap))
+}
+
+func (p *payments) countC2C(side string, orders []binance.C2COrder, now time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	next := now.Add(-historyOverlap)
+	for _, o := range orders {
+		created := time.UnixMilli(o.CreateTime)
+		if !c2cFinal[o.OrderStatus] {
+			// Queried again until it settles
+			if created.Before(next) {
+				next = created
+			}
+			continue
+		}
+		if !p.history.count(side+":"+o.OrderNumber, created) {
+			continue
+		}
+		key := c2cKey{side: side, status: o.OrderStatus, asset: o.Asset, fiat: o.Fiat}
+		if p.c2c[key] == nil {
+			p.c2c[key] = &tally{}
+		}
+		p.c2c[key].count++
+		p.c2c[key].volume += parseOrZero(o.Amount)
+		p.c2c[key].fiatVolume += parseOrZero(o.TotalPrice)
+	}
+	p.history.advance(side, next)
+}
+
+func (p *payments) Gather() []prometheus.Family {
+	payCount := prometheus.NewCounter("binance_pay_transactions_total", "Binance Pay transactions by order type, asset and direction")
+	payVolume := prometheus.NewCounter("binance_pay_volume_total", "Amount of the asset moved by Binance Pay transactions")
+	c2cCount := prometheus.NewCounter("binance_c2c_orders_total", "Settled P2P orders by side, status, asset and fiat currency")
+	c2cVolume := prometheus.NewCounter("binance_c2c_volume_total", "Amount of the asset traded by settled P2P orders")
+	c2cFiat := prometheus.NewCounter("binance_c2c_fiat_volume_total", "Fiat value of settled P2P orders")
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.history.started() {
+		return nil
+	}
+	payKeys := make([]payKey, 0, len(p.pay))
+	for k := range p.pay {
+		payKeys = append(payKeys, k)
+	}
+	sort.Slice(payKeys, func(i, j int) bool {
+		a, b := payKeys[i], payKeys[j]
+		if a.orderType != b.orderType {
+			return a.orderType < b.orderType
+		}
+		if a.asset != b.asset {
+			return a.asset < b.asset
+		}
+		return a.direction < b.direction
+	})
+	for _, k := range payKeys {
+		l := []prometheus.Label{prometheus.L("type", k.orderType), prometheus.L("asset", k.asset), prometheus.L("direction", k.direction)}
+		payCount.Add(p.pay[k].count, l...)
+		payVolume.Add(p.pay[k].volume, l...)
+	}
+
+	c2cKeys := make([]c2cKey, 0, len(p.c2c))
+	for k := range p.c2c {
+		c2cKeys = append(c2cKeys, k)
+	}
+	sort.Slice(c2cKeys, func(i, j int) bool {
+		a, b := c2cKeys[i], c2cKeys[j]
+		if a.side != b.side {
+			return a.side < b.side
+		}
+		if a.status != b.status {
+			return a.status < b.status
+		}
+		if a.asset != b.asset {
+			return a.asset < b.asset
+		}
+		return a.fiat < b.fiat
+	})
+	for _, k := range c2cKeys {
+		l := []prometheus.Label{prometheus.L("side", k.side), prometheus.L("status", k.status), prometheus.L("asset", k.asset), prometheus.L("fiat", k.fiat)}
+		c2cCount.Add(p.c2c[k].count, l...)
+		c2cVolume.Add(p.c2c[k].volume, l...)
+		c2cFiat.Add(p.c2c[k].fiatVolume, l...)
+	}
+	return []prometheus.Family{*payCount, *payVolume, *c2cCount, *c2cVolume, *c2cFiat}
+}
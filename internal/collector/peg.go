@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// pegReference is the quote of USDT itself, which can't be priced against itself
+const pegReference = "USDC"
+
+/*
+stablecoinPeg exports how far the held stablecoins of EXPORTER_STABLECOINS trade off 1.00, so a depeg of an asset in
+the spot or funding wallet alerts. Stablecoins are priced at the mid of their USDT book ticker and USDT at the one of
+USDC, inverted where binance lists the symbol the other way around. A depeg of the reference shows up as all other
+stablecoins moving together.
+*/
+type stablecoinPeg struct {
+	api         binance.BinanceAPI
+	stablecoins []string
+	listing     listing
+	lock        sync.Mutex
+	prices      map[string]pegPrice // By held stablecoin with a book
+}
+
+type pegPrice struct {
+	quote string
+	price float64
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &stablecoinPeg{api: api, stablecoins: cfg.Market.Stablecoins}
+	})
+}
+
+func (s *stablecoinPeg) Name() string {
+	return "stablecoin_peg"
+}
+
+func (s *stablecoinPeg) useClock(c clock.Clock) {
+	s.listing.useClock(c)
+}
+
+func (s *stablecoinPeg) Enabled() bool {
+	return len(s.stablecoins) > 0
+}
+
+// Weight of one book ticker request for all stablecoins, plus the symbol listing when it has to be refreshed
+func (s *stablecoinPeg) Weight() int {
+	if s.listing.due() {
+		return 24
+	}
+	return 4
+}
+
+func (s *stablecoinPeg) Priority() Priority {
+	return PriorityHigh
+}
+
+func (s *stablecoinPeg) Collect(ctx context.Context) error {
+	if err := s.listing.refresh(ctx, s.api); err != nil {
+		return err
+	}
+	held := make(map[string]bool)
+	// The wallet collectors keep the last balances, spending no request weight on them here
+	for _, b := range append(balance.FromBinance(balance.Spot, s.api.GetSpotAssets()), balance.FromBinance(balance.Funding, s.api.GetFundingAssets())...) {
+		if balance.Sum(b.Free, b.Locked, b.Freeze, b.Withdrawing) > 0 {
+			held[b.Asset] = true
+		}
+	}
+	legs := make(map[string]leg)
+	quotes := make(map[string]string)
+	symbols := make([]string, 0)
+	for _, asset := range s.stablecoins {
+		quote := usdAsset
+		if asset == usdAsset {
+			quote = pegReference
+		}
+		symbol, inverse, ok := s.listing.pair(asset, quote)
+		if !held[asset] || !ok {
+			continue
+		}
+		legs[asset], quotes[asset] = leg{symbol, inverse}, quote
+		symbols = append(symbols, symbol)
+	}
+
+	prices := make(map[string]pegPrice, len(legs))
+	if len(symbols) > 0 {
+		sort.Strings(symbols)
+		books, err := s.api.GetBookTickers(ctx, symbols)
+		if err != nil {
+			return err
+		}
+		mids := make(map[string]float64, len(books))
+		for _, b := range books {
+			bid, bidErr := strconv.ParseFloat(b.BidPrice, 64)
+			ask, askErr := strconv.ParseFloat(b.AskPrice, 64)
+			if bidErr == nil && askErr == nil && bid > 0 && ask > 0 {
+				mids[b.Symbol] = (bid + ask) / 2
+			}
+		}
+		for asset, l := range legs {
+			if usd := (route{legs: []leg{l}}).resolve(mids); usd.ok {
+				prices[asset] = pegPrice{quote: quotes[asset], price: usd.usd}
+			}
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.prices = prices
+	return nil
+}
+
+func (s *stablecoinPeg) Gather() []prometheus.Family {
+	price := prometheus.NewGauge("binance_stablecoin_price", "Mid price of the held stablecoin in the quote stablecoin")
+	deviation := prometheus.NewGauge("binance_stablecoin_peg_deviation", "Deviation of the price of the held stablecoin from 1.00, negative below the peg")
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.prices == nil {
+		return nil
+	}
+	assets := make([]string, 0, len(s.prices))
+	for asset := range s.prices {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	for _, asset := range assets {
+		p := s.prices[asset]
+		price.Add(p.price, prometheus.L("asset", asset), prometheus.L("quote", p.quote))
+		deviation.Add(p.price-1, prometheus.L("asset", asset))
+	}
+	return []prometheus.Family{*price, *deviation}
+}
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"go.uber.org/zap"
+)
+
+/*
+permission switches a collector off once binance answers that the key will never be allowed to call its endpoint, e.g.
+because the account has no margin account. Embedding it makes the collector a Disabler.
+*/
+type permission struct {
+	name   string
+	logger *zap.Logger
+	lock   sync.Mutex
+	reason string
+}
+
+// check disables the collector if err says it can't ever succeed, err is returned either way
+func (p *permission) check(err error) error {
+	var apiErr *binance.APIError
+	if !errors.As(err, &apiErr) || len(apiErr.DisableReason()) == 0 {
+		return err
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if len(p.reason) == 0 {
+		p.reason = apiErr.DisableReason()
+		p.logger.Warn("API key is not permitted to use this collector, disabling it.", zap.String("collector", p.name), zap.String("reason", p.reason), zap.Error(err))
+	}
+	return err
+}
+
+func (p *permission) DisableReason() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.reason
+}
+
+func (p *permission) permitted() bool {
+	return len(p.DisableReason()) == 0
+}
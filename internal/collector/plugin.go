@@ -0,0 +1,255 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.uber.org/zap"
+)
+
+var (
+	invalidSamples = prometheus.NewCounterVec("binance_plugin_invalid_samples_total", "Samples printed by the plugin collector that were dropped, by reason", "collector", "reason")
+
+	// pluginMetrics holds the vectors of all plugins, so two plugins can't define the same metric
+	pluginMetrics = prometheus.NewRegistry()
+	pluginFactory = prometheus.NewFactory(pluginMetrics)
+)
+
+/*
+pluginDropped are the prefixes of the variables the exporter is configured through, which are not passed on to plugins
+unless EXPORTER_PLUGIN_ENV names them. They hold the binance key, which may have more permissions than a plugin needs,
+tokens and credentials, so every new one is kept from plugins without having to be listed.
+*/
+var pluginDropped = []string{"EXPORTER_", "B_", "AWS_"}
+
+func init() {
+	prometheus.Default.MustRegister(invalidSamples)
+}
+
+type (
+	/*
+		plugin runs an external command every interval and exports the samples it prints on stdout as a JSON array:
+
+			[{"name": "staking_apr", "help": "...", "type": "gauge", "labels": {"asset": "ETH"}, "value": 0.034}]
+
+		Metrics are named binance_plugin_<name> so they can't mix with the built-in ones, type is gauge or counter and
+		defaults to gauge. Every sample of a metric needs the same label names. Invalid samples are dropped and counted,
+		a failing command or unreadable output fails the run and keeps the samples of the last successful one.
+	*/
+	plugin struct {
+		name       string
+		command    []string
+		timeout    time.Duration
+		maxSamples int
+		env        []string // Variables passed on to the command despite their prefix
+		logger     *zap.Logger
+		lock       sync.Mutex
+		metrics    map[string]pluginMetric // By metric name, created on first sight
+		collected  bool
+	}
+
+	// pluginMetric is the vector of a metric and what its samples have to match
+	pluginMetric struct {
+		vec    *prometheus.Vec
+		typ    string
+		labels string // Comma separated label names
+	}
+
+	pluginSample struct {
+		Name   string            `json:"name"`
+		Help   string            `json:"help"`
+		Type   string            `json:"type"`
+		Labels map[string]string `json:"labels"`
+		Value  *float64          `json:"value"`
+	}
+)
+
+// newPlugins creates a collector for every configured plugin command, sorted by name
+func newPlugins(cfg config.Plugins, l *zap.Logger) []Collector {
+	names := make([]string, 0, len(cfg.Commands))
+	for name := range cfg.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]Collector, 0, len(names))
+	for _, name := range names {
+		res = append(res, &plugin{
+			name:       "plugin_" + name,
+			command:    cfg.Commands[name],
+			timeout:    cfg.Timeout,
+			maxSamples: cfg.MaxSamples,
+			env:        cfg.Env,
+			logger:     l,
+			metrics:    make(map[string]pluginMetric),
+		})
+	}
+	return res
+}
+
+func (p *plugin) Name() string {
+	return p.name
+}
+
+func (p *plugin) Enabled() bool {
+	return true
+}
+
+// Weight is 0, a plugin calling binance spends the weight of its own key
+func (p *plugin) Weight() int {
+	return 0
+}
+
+func (p *plugin) Collect(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+	cmd.Env = pluginEnv(p.env)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return fmt.Errorf("plugin command failed: %w: %.512s", err, msg)
+		}
+		return fmt.Errorf("plugin command failed: %w", err)
+	}
+
+	samples := make([]pluginSample, 0)
+	if err := json.Unmarshal(stdout.Bytes(), &samples); err != nil {
+		return fmt.Errorf("plugin printed no JSON array of samples: %w", err)
+	}
+	if len(samples) > p.maxSamples {
+		return fmt.Errorf("plugin printed %d samples, more than the %d of EXPORTER_PLUGIN_MAX_SAMPLES", len(samples), p.maxSamples)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, m := range p.metrics {
+		m.vec.Reset()
+	}
+	seen := make(map[string]bool, len(samples))
+	for _, s := range samples {
+		if reason := p.set(s, seen); len(reason) > 0 {
+			invalidSamples.Inc(p.name, reason)
+			tracing.Logger(ctx, p.logger).Debug("Dropped invalid plugin sample", zap.String("collector", p.name), zap.String("metric", s.Name), zap.String("reason", reason))
+		}
+	}
+	p.collected = true
+	return nil
+}
+
+// set validates the sample and sets it on the vector of its metric, it returns why the sample was dropped otherwise
+func (p *plugin) set(s pluginSample, seen map[string]bool) string {
+	if s.Value == nil {
+		return "missing_value"
+	}
+	if len(s.Type) == 0 {
+		s.Type = prometheus.GaugeType
+	}
+	if s.Type != prometheus.GaugeType && s.Type != prometheus.CounterType {
+		return "invalid_type"
+	}
+	labels := make([]string, 0, len(s.Labels))
+	for name := range s.Labels {
+		if name == "exchange" {
+			// Added by the registry to every metric
+			return "invalid_label"
+		}
+		labels = append(labels, name)
+	}
+	sort.Strings(labels)
+	values := make([]string, len(labels))
+	for i, name := range labels {
+		values[i] = s.Labels[name]
+	}
+
+	name := prometheus.DefaultNamespace + "_plugin_" + s.Name
+	m, ok := p.metrics[name]
+	if !ok {
+		var err error
+		m = pluginMetric{typ: s.Type, labels: strings.Join(labels, ",")}
+		if s.Type == prometheus.CounterType {
+			m.vec, err = pluginFactory.NewCounterVec(name, s.Help, labels...)
+		} else {
+			m.vec, err = pluginFactory.NewGaugeVec(name, s.Help, labels...)
+		}
+		switch {
+		case errors.Is(err, prometheus.ErrDuplicate):
+			return "taken_by_other_plugin"
+		case errors.Is(err, prometheus.ErrInvalidName):
+			return "invalid_name"
+		case err != nil:
+			return "invalid_label"
+		}
+		p.metrics[name] = m
+	}
+	if m.typ != s.Type {
+		return "type_mismatch"
+	}
+	if m.labels != strings.Join(labels, ",") {
+		return "label_mismatch"
+	}
+	id := name + "{" + strings.Join(values, "\xff") + "}"
+	if seen[id] {
+		return "duplicate"
+	}
+	seen[id] = true
+	// Counters are reported as totals by the plugin, so they are set like gauges
+	m.vec.Set(*s.Value, values...)
+	return ""
+}
+
+func (p *plugin) Gather() []prometheus.Family {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.collected {
+		return nil
+	}
+	names := make([]string, 0, len(p.metrics))
+	for name := range p.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]prometheus.Family, 0, len(names))
+	for _, name := range names {
+		res = append(res, p.metrics[name].vec.Gather()...)
+	}
+	return res
+}
+
+// pluginEnv returns the environment of the exporter without its configuration, except for the variables in pass
+func pluginEnv(pass []string) []string {
+	res := make([]string, 0)
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if pluginPassed(name, pass) {
+			res = append(res, kv)
+		}
+	}
+	return res
+}
+
+func pluginPassed(name string, pass []string) bool {
+	for _, p := range pass {
+		if name == p {
+			return true
+		}
+	}
+	for _, prefix := range pluginDropped {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
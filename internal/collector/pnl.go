@@ -0,0 +1,235 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/ledger"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/store"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.uber.org/zap"
+)
+
+// pnlBucket holds the position of every EXPORTER_PNL_SYMBOLS symbol in the store
+const pnlBucket = "pnl_positions"
+
+/*
+pnl ingests the trade history of the configured symbols into positions and values them at the current price, so the
+exporter tracks cost basis and PnL instead of only mirroring balances. Positions are kept in the store and only trades
+newer than the last ingested one get fetched.
+*/
+type pnl struct {
+	permission
+	api       binance.BinanceAPI
+	store     *store.Store
+	symbols   []string
+	lock      sync.Mutex
+	pages     int                         // Pages of trades fetched per symbol and collection
+	maxLots   int                         // FIFO lots kept per symbol
+	positions map[string]*ledger.Position // By symbol
+	caughtUp  map[string]bool             // Symbols whose trade history was ingested up to the latest trade
+	prices    map[string]float64          // Current price by symbol, nil until the first collection
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		p := &pnl{
+			permission: permission{name: "pnl", logger: l},
+			api:        api,
+			store:      store.Default,
+			symbols:    cfg.PnL.Symbols,
+			pages:      cfg.PnL.BackfillPages,
+			maxLots:    cfg.PnL.MaxLots,
+			positions:  make(map[string]*ledger.Position, len(cfg.PnL.Symbols)),
+			caughtUp:   make(map[string]bool, len(cfg.PnL.Symbols)),
+		}
+		for _, symbol := range p.symbols {
+			p.positions[symbol] = loadPosition(p.store, symbol, cfg.PnL.Method, l)
+		}
+		return p
+	})
+}
+
+// loadPosition returns the stored position of the symbol, or an empty one if none was stored with method
+func loadPosition(s *store.Store, symbol, method string, l *zap.Logger) *ledger.Position {
+	position := &ledger.Position{}
+	ok, err := s.Get(pnlBucket, symbol, position)
+	if !ok {
+		return ledger.NewPosition(method)
+	}
+	if err != nil {
+		l.Warn("Failed to load the position from the store, ingesting the trade history again", zap.String("symbol", symbol), zap.Error(err))
+		return ledger.NewPosition(method)
+	}
+	if len(position.Method) == 0 {
+		// Stored before sales could be matched FIFO
+		position.Method = ledger.Average
+	}
+	if position.Method != method {
+		l.Info("Position was stored with another PnL method, ingesting the trade history again", zap.String("symbol", symbol), zap.String("method", method))
+		return ledger.NewPosition(method)
+	}
+	return position
+}
+
+func (p *pnl) Name() string {
+	return p.name
+}
+
+func (p *pnl) Enabled() bool {
+	return len(p.symbols) > 0 && p.permitted()
+}
+
+// Weight of one page of trades and the average price of every symbol, backfills take more pages
+func (p *pnl) Weight() int {
+	return 22 * len(p.symbols)
+}
+
+func (p *pnl) Collect(ctx context.Context) error {
+	prices := make(map[string]float64, len(p.symbols))
+	for _, symbol := range p.symbols {
+		if err := p.ingest(ctx, symbol); err != nil {
+			return p.check(err)
+		}
+		price, err := p.api.GetAvgPrice(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		if prices[symbol], err = strconv.ParseFloat(price.Price, 64); err != nil {
+			return err
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.prices = prices
+	return nil
+}
+
+/*
+ingest applies the trades of the symbol newer than its position to it. Backfilling a long history fetches at most
+EXPORTER_PNL_BACKFILL_PAGES pages per collection, so it spreads over several collections instead of spending the request
+weight of the whole key at once. The position is stored after every page and a restart resumes from there.
+*/
+func (p *pnl) ingest(ctx context.Context, symbol string) error {
+	p.lock.Lock()
+	position := *p.positions[symbol]
+	// Trades are applied to a copy, a failing page leaves the position as it was
+	position.Lots = append([]ledger.Lot(nil), position.Lots...)
+	p.lock.Unlock()
+
+	base, _ := config.USDBase(symbol)
+	for page := 0; page < p.pages; page++ {
+		trades, err := p.api.GetMyTrades(ctx, symbol, position.LastTradeID+1, binance.MaxTradesPerPage)
+		if err != nil {
+			return err
+		}
+		start := position.LastTradeID
+		for _, t := range trades {
+			position.Apply(fill(t, base))
+		}
+		if merged := position.CapLots(p.maxLots); merged > 0 {
+			evictions.Add(float64(merged), p.name, "fifo_lots")
+		}
+		caughtUp := len(trades) < binance.MaxTradesPerPage
+		p.checkpoint(ctx, symbol, position, position.LastTradeID != start, caughtUp)
+		if caughtUp {
+			return nil
+		}
+		position.Lots = append([]ledger.Lot(nil), position.Lots...)
+	}
+	tracing.Logger(ctx, p.logger).Info("Backfilling the trade history, continuing with the next collection",
+		zap.String("symbol", symbol), zap.Int64("last_trade_id", position.LastTradeID))
+	return nil
+}
+
+// checkpoint makes the position the current one of the symbol and stores it if it changed
+func (p *pnl) checkpoint(ctx context.Context, symbol string, position ledger.Position, changed, caughtUp bool) {
+	p.lock.Lock()
+	*p.positions[symbol] = position
+	p.caughtUp[symbol] = caughtUp
+	p.lock.Unlock()
+	if !changed {
+		return
+	}
+	if err := p.store.Put(pnlBucket, symbol, position); err != nil {
+		tracing.Logger(ctx, p.logger).Warn("Failed to store the position, the trade history is ingested again after a restart", zap.String("symbol", symbol), zap.Error(err))
+	}
+}
+
+// fill reduces a trade of a symbol with the base asset to what its cost basis depends on
+func fill(t binance.Trade, base string) ledger.Fill {
+	return ledger.Fill{
+		ID:              t.ID,
+		Buy:             t.IsBuyer,
+		Price:           parseOrZero(t.Price),
+		Quantity:        parseOrZero(t.Qty),
+		QuoteQuantity:   parseOrZero(t.QuoteQty),
+		Commission:      parseOrZero(t.Commission),
+		CommissionBase:  t.CommissionAsset == base,
+		CommissionQuote: t.CommissionAsset == t.Symbol[len(base):],
+	}
+}
+
+func (p *pnl) Gather() []prometheus.Family {
+	cost := prometheus.NewGauge("binance_asset_cost_basis_usd", "Average cost of one unit of the holding of the asset bought through the PnL symbols, in USD")
+	quantity := prometheus.NewGauge("binance_asset_cost_basis_quantity", "Holding of the asset the cost basis covers, assets deposited from elsewhere are not included")
+	unrealized := prometheus.NewGauge("binance_asset_unrealized_pnl_usd", "Gain of the holding of the asset over its cost basis at the current price, in USD")
+	profit := prometheus.NewCounter("binance_realized_profit_usd_total", "Sum of the gains of the sales of the symbol that made a profit over their cost basis, in USD")
+	loss := prometheus.NewCounter("binance_realized_loss_usd_total", "Sum of the losses of the sales of the symbol that sold below their cost basis, in USD")
+	backfilling := prometheus.NewGauge("binance_pnl_backfill_in_progress", "1 while the trade history of the symbol is still being ingested, its PnL is not exported until it completes")
+	lastTrade := prometheus.NewGauge("binance_pnl_last_trade_id", "Id of the last trade of the symbol included in its position")
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.prices == nil {
+		return nil
+	}
+	// Symbols sharing a base asset, like BTCUSDT and BTCUSDC, add up to one holding
+	type holding struct {
+		quantity, cost, pnl float64
+		partial             bool // A symbol of the asset is still backfilling
+	}
+	holdings := make(map[string]*holding)
+	for _, symbol := range p.symbols {
+		base, _ := config.USDBase(symbol)
+		if holdings[base] == nil {
+			holdings[base] = &holding{}
+		}
+		position := p.positions[symbol]
+		lastTrade.Add(float64(position.LastTradeID), prometheus.L("symbol", symbol))
+		if !p.caughtUp[symbol] {
+			backfilling.Add(1, prometheus.L("symbol", symbol))
+			holdings[base].partial = true
+			continue
+		}
+		backfilling.Add(0, prometheus.L("symbol", symbol))
+		profit.Add(position.RealizedProfit, prometheus.L("symbol", symbol))
+		loss.Add(position.RealizedLoss, prometheus.L("symbol", symbol))
+		holdings[base].quantity += position.Quantity
+		holdings[base].cost += position.Cost
+		holdings[base].pnl += position.UnrealizedPnL(p.prices[symbol])
+	}
+	assets := make([]string, 0, len(holdings))
+	for asset := range holdings {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	for _, asset := range assets {
+		h := holdings[asset]
+		if h.partial {
+			continue
+		}
+		if h.quantity > 0 {
+			cost.Add(h.cost/h.quantity, prometheus.L("asset", asset))
+		}
+		quantity.Add(h.quantity, prometheus.L("asset", asset))
+		unrealized.Add(h.pnl, prometheus.L("asset", asset))
+	}
+	return []prometheus.Family{*cost, *quantity, *unrealized, *profit, *loss, *backfilling, *lastTrade}
+}
@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+portfolioMargin exports the Portfolio Margin account. Its collateral lives outside of the classic margin and futures
+accounts, so without it a Portfolio Margin user sees none of it. The equity counts towards the totals at the BTCUSDT
+price.
+*/
+type portfolioMargin struct {
+	permission
+	api      binance.BinanceAPI
+	enabled  bool
+	lock     sync.Mutex
+	account  *binance.PortfolioAccount // nil until the first collection
+	balances []binance.PortfolioBalance
+	btcUSD   float64
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &portfolioMargin{
+			permission: permission{name: "portfolio_margin", logger: l},
+			api:        api,
+			enabled:    cfg.Derivatives.PortfolioMargin,
+		}
+	})
+}
+
+func (p *portfolioMargin) Name() string {
+	return p.name
+}
+
+func (p *portfolioMargin) Enabled() bool {
+	return p.enabled && p.permitted()
+}
+
+// Weight of the account and balance endpoints and the BTC price
+func (p *portfolioMargin) Weight() int {
+	return 42
+}
+
+func (p *portfolioMargin) Priority() Priority {
+	return PriorityHigh
+}
+
+func (p *portfolioMargin) Collect(ctx context.Context) error {
+	account, err := p.api.GetPortfolioAccount(ctx)
+	if err != nil {
+		return p.check(err)
+	}
+	balances, err := p.api.GetPortfolioBalances(ctx)
+	if err != nil {
+		return p.check(err)
+	}
+	price, err := p.api.GetAvgPrice(ctx, usdSymbol)
+	if err != nil {
+		return err
+	}
+	btcUSD, err := strconv.ParseFloat(price.Price, 64)
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.account, p.balances, p.btcUSD = &account, balances, btcUSD
+	return nil
+}
+
+// ValueBTC converts the equity without collateral rates, what the holdings are worth, to BTC
+func (p *portfolioMargin) ValueBTC() (float64, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.account == nil || p.btcUSD == 0 {
+		return 0, false
+	}
+	return parseOrZero(p.account.ActualEquity) / p.btcUSD, true
+}
+
+func (p *portfolioMargin) Gather() []prometheus.Family {
+	uniMMR := prometheus.NewGauge("binance_portfolio_margin_uni_mmr", "Unified maintenance margin ratio of the Portfolio Margin account, liquidation starts at 1.05")
+	equity := prometheus.NewGauge("binance_portfolio_margin_account_equity_usd", "Equity of the Portfolio Margin account with the collateral rates applied, in USD")
+	actual := prometheus.NewGauge("binance_portfolio_margin_actual_equity_usd", "Equity of the Portfolio Margin account without the collateral rates, in USD")
+	initial := prometheus.NewGauge("binance_portfolio_margin_initial_margin_usd", "Initial margin of the Portfolio Margin account, in USD")
+	maint := prometheus.NewGauge("binance_portfolio_margin_maint_margin_usd", "Maintenance margin of the Portfolio Margin account, in USD")
+	available := prometheus.NewGauge("binance_portfolio_margin_available_balance_usd", "Balance of the Portfolio Margin account available for new positions, in USD")
+	status := prometheus.NewGauge("binance_portfolio_margin_account_status", "Status of the Portfolio Margin account, 1 for the current one")
+	wallet := prometheus.NewGauge("binance_portfolio_margin_asset_balance", "Balance of the asset in the Portfolio Margin account by wallet, portfolio_margin is all of its wallets")
+	borrowed := prometheus.NewGauge("binance_portfolio_margin_asset_borrowed", "Amount of the asset borrowed in the cross margin wallet of the Portfolio Margin account")
+	interest := prometheus.NewGauge("binance_portfolio_margin_asset_interest", "Interest owed on the asset borrowed in the cross margin wallet of the Portfolio Margin account")
+	unrealized := prometheus.NewGauge("binance_portfolio_margin_asset_unrealized_pnl", "Unrealized profit of the futures positions margined in the asset, by futures wallet")
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.account == nil {
+		return nil
+	}
+	addParsed(uniMMR, p.account.UniMMR)
+	addParsed(equity, p.account.AccountEquity)
+	addParsed(actual, p.account.ActualEquity)
+	addParsed(initial, p.account.AccountInitialMargin)
+	addParsed(maint, p.account.AccountMaintMargin)
+	addParsed(available, p.account.TotalAvailableBalance)
+	status.Add(1, prometheus.L("status", p.account.AccountStatus))
+	for _, b := range p.balances {
+		a := prometheus.L("asset", b.Asset)
+		addParsed(wallet, b.TotalWalletBalance, a, balance.PortfolioMargin.Label())
+		addParsed(wallet, b.CrossMarginAsset, a, balance.CrossMargin.Label())
+		addParsed(wallet, b.UMWalletBalance, a, balance.FuturesUSDM.Label())
+		addParsed(wallet, b.CMWalletBalance, a, balance.FuturesCOINM.Label())
+		addParsed(borrowed, b.CrossMarginBorrowed, a, balance.CrossMargin.Label())
+		addParsed(interest, b.CrossMarginInterest, a, balance.CrossMargin.Label())
+		addParsed(unrealized, b.UMUnrealizedPNL, a, balance.FuturesUSDM.Label())
+		addParsed(unrealized, b.CMUnrealizedPNL, a, balance.FuturesCOINM.Label())
+	}
+	return []prometheus.Family{*uniMMR, *equity, *actual, *initial, *maint, *available, *status, *wallet, *borrowed, *interest, *unrealized}
+}
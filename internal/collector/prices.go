@@ -0,0 +1,204 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// usdAsset is the asset prices resolve to, USDT standing in for the dollar like in usdSymbol
+const usdAsset = "USDT"
+
+type (
+	/*
+		prices resolves the USD price of every asset held in the spot or funding wallet. Assets without a USDT symbol
+		are priced through a bridge asset, asset→BTC→USDT or asset→BNB→USDT by default, taking the first route whose
+		symbols binance trades. Legs are priced at the mid of the book ticker, inverted where binance only lists the
+		symbol the other way around. Assets no route or price was found for are flagged instead of valued at 0.
+	*/
+	prices struct {
+		api      binance.BinanceAPI
+		enabled  bool
+		bridges  []string
+		labels   config.Assets
+		listing  listing
+		lock     sync.Mutex
+		resolved map[string]price // By asset
+		held     []balance.Balance
+	}
+
+	// price of an asset in USD and the bridge asset it was resolved through, via is empty if it has a USDT symbol
+	price struct {
+		usd float64
+		via string
+		ok  bool
+	}
+
+	// route converts an asset to USDT, through the bridge asset via unless it is empty
+	route struct {
+		legs []leg
+		via  string
+	}
+
+	// leg is one conversion of a route, inverse if the symbol has the target as its base asset
+	leg struct {
+		symbol  string
+		inverse bool
+	}
+)
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &prices{api: api, enabled: cfg.Market.AssetPrices, bridges: cfg.Market.PriceBridges, labels: cfg.Assets}
+	})
+}
+
+func (p *prices) Name() string {
+	return "asset_prices"
+}
+
+func (p *prices) useClock(c clock.Clock) {
+	p.listing.useClock(c)
+}
+
+func (p *prices) Enabled() bool {
+	return p.enabled
+}
+
+// Weight of one book ticker request for all legs, plus the symbol listing when it has to be refreshed
+func (p *prices) Weight() int {
+	if p.listing.due() {
+		return 24
+	}
+	return 4
+}
+
+func (p *prices) Collect(ctx context.Context) error {
+	if err := p.listing.refresh(ctx, p.api); err != nil {
+		return err
+	}
+	// The wallet collectors keep the last balances, spending no request weight on them here
+	held := append(balance.FromBinance(balance.Spot, p.api.GetSpotAssets()), balance.FromBinance(balance.Funding, p.api.GetFundingAssets())...)
+	routes := make(map[string]route)
+	symbols := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, b := range held {
+		if _, ok := routes[b.Asset]; ok || b.Asset == usdAsset {
+			continue
+		}
+		r := p.route(b.Asset)
+		routes[b.Asset] = r
+		for _, l := range r.legs {
+			if !seen[l.symbol] {
+				seen[l.symbol] = true
+				symbols = append(symbols, l.symbol)
+			}
+		}
+	}
+
+	mids := make(map[string]float64, len(symbols))
+	if len(symbols) > 0 {
+		sort.Strings(symbols)
+		books, err := p.api.GetBookTickers(ctx, symbols)
+		if err != nil {
+			return err
+		}
+		for _, b := range books {
+			bid, bidErr := strconv.ParseFloat(b.BidPrice, 64)
+			ask, askErr := strconv.ParseFloat(b.AskPrice, 64)
+			if bidErr == nil && askErr == nil && bid > 0 && ask > 0 {
+				mids[b.Symbol] = (bid + ask) / 2
+			}
+		}
+	}
+
+	resolved := map[string]price{usdAsset: {usd: 1, ok: true}}
+	for asset, r := range routes {
+		resolved[asset] = r.resolve(mids)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.resolved = resolved
+	p.held = held
+	return nil
+}
+
+// route returns the route converting the asset to USDT through the first bridge binance trades, without legs if there is none
+func (p *prices) route(asset string) route {
+	if symbol, inverse, ok := p.listing.pair(asset, usdAsset); ok {
+		return route{legs: []leg{{symbol, inverse}}}
+	}
+	for _, bridge := range p.bridges {
+		if bridge == asset || bridge == usdAsset {
+			continue
+		}
+		first, firstInverse, ok := p.listing.pair(asset, bridge)
+		if !ok {
+			continue
+		}
+		if second, secondInverse, ok := p.listing.pair(bridge, usdAsset); ok {
+			return route{legs: []leg{{first, firstInverse}, {second, secondInverse}}, via: bridge}
+		}
+	}
+	return route{}
+}
+
+// resolve multiplies the mid prices of the legs, it has no price if there are no legs or a leg has no book
+func (r route) resolve(mids map[string]float64) price {
+	if len(r.legs) == 0 {
+		return price{}
+	}
+	res := price{usd: 1, via: r.via, ok: true}
+	for _, l := range r.legs {
+		mid, ok := mids[l.symbol]
+		if !ok {
+			return price{}
+		}
+		if l.inverse {
+			mid = 1 / mid
+		}
+		res.usd *= mid
+	}
+	return res
+}
+
+func (p *prices) Gather() []prometheus.Family {
+	usd := prometheus.NewGauge("binance_asset_price_usd", "Price of the asset in USD, resolved through the bridge asset of the via label if it has no USDT symbol")
+	valuation := prometheus.NewGauge("binance_asset_usd_valuation", "Value of the asset in the wallet in USD")
+	unresolved := prometheus.NewGauge("binance_asset_price_unresolved", "1 for held assets no USD price could be resolved for, they are missing from binance_asset_usd_valuation")
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.resolved == nil {
+		return nil
+	}
+	assets := make([]string, 0, len(p.resolved))
+	for asset := range p.resolved {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	for _, asset := range assets {
+		l := assetLabels(p.labels, asset)
+		if r := p.resolved[asset]; r.ok {
+			usd.Add(r.usd, append(l, prometheus.L("via", r.via))...)
+		} else {
+			unresolved.Add(1, l...)
+		}
+	}
+	for _, b := range p.held {
+		if r := p.resolved[b.Asset]; r.ok {
+			amount := balance.Sum(b.Free, b.Locked, b.Freeze, b.Withdrawing)
+			valuation.Add(amount*r.usd, append([]prometheus.Label{b.Wallet.Label()}, assetLabels(p.labels, b.Asset)...)...)
+		}
+	}
+	return []prometheus.Family{*usd, *valuation, *unresolved}
+}
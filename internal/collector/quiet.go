@@ -0,0 +1,63 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/schedule"
+)
+
+// Polling modes of binance_polling_mode
+const (
+	modeNormal  = "normal"
+	modeReduced = "reduced" // Within quiet hours, polling every EXPORTER_QUIET_FACTOR intervals
+	modePaused  = "paused"  // Within quiet hours, not polling
+	modeStandby = "standby" // Another replica is the leader
+)
+
+var pollingModes = []string{modeNormal, modeReduced, modePaused, modeStandby}
+
+// quiet are the EXPORTER_QUIET_HOURS windows during which polling pauses or slows down
+type quiet struct {
+	windows []*schedule.Window
+	mode    string // modePaused or modeReduced
+	factor  float64
+}
+
+// newQuiet returns nil without EXPORTER_QUIET_HOURS, the windows were validated with the config
+func newQuiet(cfg config.Quiet) *quiet {
+	if len(cfg.Windows) == 0 {
+		return nil
+	}
+	q := &quiet{mode: modePaused, factor: cfg.Factor}
+	if cfg.Mode == config.QuietReduce {
+		q.mode = modeReduced
+	}
+	for _, w := range cfg.Windows {
+		if window, err := schedule.Parse(w); err == nil {
+			q.windows = append(q.windows, window)
+		}
+	}
+	return q
+}
+
+// modeAt returns the polling mode at t, modeNormal outside of the windows
+func (q *quiet) modeAt(t time.Time) string {
+	if q == nil {
+		return modeNormal
+	}
+	for _, w := range q.windows {
+		if w.Contains(t) {
+			return q.mode
+		}
+	}
+	return modeNormal
+}
+
+// scaled returns the interval stretched by EXPORTER_QUIET_FACTOR while polling is reduced
+func (q *quiet) scaled(interval time.Duration, mode string) time.Duration {
+	if mode != modeReduced {
+		return interval
+	}
+	return time.Duration(float64(interval) * q.factor)
+}
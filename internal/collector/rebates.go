@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// futuresRebates are the futures income types paid out by referral programs
+var futuresRebates = []string{"REFERRAL_KICKBACK", "COMMISSION_REBATE"}
+
+// spotRebateTypes names the numeric types of the spot rebate history like the futures income types
+var spotRebateTypes = map[int]string{1: "commission_rebate", 2: "referral_kickback"}
+
+type (
+	/*
+		rebates counts the referral and commission rebates paid to the account on spot and USDⓈ-M futures, so the income
+		of referral programs is tracked without exporting the history by hand. Accounts without futures only count spot.
+	*/
+	rebates struct {
+		permission
+		futures permission // Switched off on its own, spot rebates are counted either way
+		api     binance.BinanceAPI
+		enabled bool
+		lock    sync.Mutex
+		history history // Sources are spot and the futures income types
+		earned  map[rebateKey]float64
+	}
+	rebateKey struct {
+		market, rebateType, asset string
+	}
+)
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &rebates{
+			permission: permission{name: "rebates", logger: l},
+			futures:    permission{name: "rebates", logger: l},
+			api:        api,
+			enabled:    cfg.History.Rebates,
+			history:    newHistory("rebates", cfg.History),
+			earned:     make(map[rebateKey]float64),
+		}
+	})
+}
+
+func (r *rebates) Name() string {
+	return r.name
+}
+
+func (r *rebates) Enabled() bool {
+	return r.enabled && r.permitted()
+}
+
+// Weight of the spot history and the futures income of every rebate type
+func (r *rebates) Weight() int {
+	return 1 + 30*len(futuresRebates)
+}
+
+func (r *rebates) Priority() Priority {
+	return PriorityLow
+}
+
+// DefaultInterval is long since rebates are paid out daily and the spot history is expensive
+func (r *rebates) DefaultInterval() time.Duration {
+	return 15 * time.Minute
+}
+
+func (r *rebates) Collect(ctx context.Context) error {
+	now := time.Now()
+	spot, err := r.api.GetSpotRebates(ctx, r.from("spot", now), now)
+	if err != nil {
+		return r.check(err)
+	}
+	r.lock.Lock()
+	for _, rebate := range spot {
+		at := time.UnixMilli(rebate.UpdateTime)
+		id := "spot:" + rebate.Asset + ":" + strconv.Itoa(rebate.Type) + ":" + strconv.FormatInt(rebate.UpdateTime, 10) + ":" + rebate.Amount
+		if !r.history.count(id, at) {
+			continue
+		}
+		rebateType, ok := spotRebateTypes[rebate.Type]
+		if !ok {
+			rebateType = strconv.Itoa(rebate.Type)
+		}
+		r.earned[rebateKey{market: "spot", rebateType: rebateType, asset: rebate.Asset}] += parseOrZero(rebate.Amount)
+	}
+	r.history.advance("spot", now.Add(-historyOverlap))
+	r.lock.Unlock()
+
+	if !r.futures.permitted() {
+		return nil
+	}
+	for _, incomeType := range futuresRebates {
+		incomes, err := r.api.GetFuturesIncome(ctx, incomeType, r.from(incomeType, now), now)
+		if err != nil {
+			return r.futures.check(err)
+		}
+		r.lock.Lock()
+		for _, income := range incomes {
+			if !r.history.count("futures:"+strconv.FormatInt(income.TranID, 10)+":"+income.IncomeType, time.UnixMilli(income.Time)) {
+				continue
+			}
+			r.earned[rebateKey{market: "futures", rebateType: strings.ToLower(incomeType), asset: income.Asset}] += parseOrZero(income.Income)
+		}
+		r.history.advance(incomeType, now.Add(-historyOverlap))
+		r.lock.Unlock()
+	}
+	return nil
+}
+
+func (r *rebates) from(source string, now time.Time) time.Time {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.history.from(source, now)
+}
+
+func (r *rebates) Gather() []prometheus.Family {
+	earned := prometheus.NewCounter("binance_rebate_earnings_total", "Referral and commission rebates paid to the account by market, type and asset")
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.history.started() {
+		return nil
+	}
+	keys := make([]rebateKey, 0, len(r.earned))
+	for k := range r.earned {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.market != b.market {
+			return a.market < b.market
+		}
+		if a.rebateType != b.rebateType {
+			return a.rebateType < b.rebateType
+		}
+		return a.asset < b.asset
+	})
+	for _, k := range keys {
+		earned.Add(r.earned[k], prometheus.L("market", k.market), prometheus.L("type", k.rebateType), prometheus.L("asset", k.asset))
+	}
+	return []prometheus.Family{*earned}
+}
@@ -0,0 +1,531 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/exchange"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnknownCollector  = errors.New("unknown collector")
+	ErrCollectorDisabled = errors.New("collector is disabled")
+)
+
+// durationBuckets are the upper bounds in seconds of the cycle and collector run duration histograms
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+/*
+Registry holds the collectors of one exchange the poller iterates and times every one of them. All metrics it gathers
+carry the name of the exchange as exchange label.
+*/
+type Registry struct {
+	exchange    string
+	collectors  []Collector
+	cfg         config.Collection
+	priorities  map[string]Priority // EXPORTER_COLLECTOR_PRIORITIES
+	clock       clock.Clock
+	logger      *zap.Logger
+	duration    *prometheus.Vec
+	runs        *prometheus.HistogramVec // Durations of all runs of every collector
+	cycleTime   *prometheus.HistogramVec
+	overrun     *prometheus.Vec
+	errors      *prometheus.Vec
+	failing     *prometheus.Vec
+	lastSuccess *prometheus.Vec
+	skipped     *prometheus.Vec
+	budget      *budget   // nil without EXPORTER_WEIGHT_BUDGET
+	adaptive    *adaptive // nil without EXPORTER_ADAPTIVE_INTERVAL
+	slo         *slo      // nil with an EXPORTER_SLO_WINDOW of 0
+	derived     *derived  // nil without EXPORTER_DERIVED_METRICS
+	quiet       *quiet    // nil without EXPORTER_QUIET_HOURS
+	security    *security
+	lock        sync.Mutex
+	lastRun     map[string]time.Time       // Start of the last run of every collector
+	succeeded   map[string]bool            // Collectors that succeeded at least once
+	failures    map[string]int             // Failed runs in a row of every collector
+	endpoints   map[string]map[string]bool // Binance endpoints every collector requested so far
+	mode        string                     // Polling mode of the last tick
+	ready       atomic.Bool                // Set once the first cycle completed
+	cycles      atomic.Uint64              // Id of the last cycle started
+	generation  atomic.Pointer[generation]
+	heartbeat   atomic.Int64 // Unix nanoseconds of the last time the poller was not busy
+	active      func() bool  // Poll skips its cycles while this returns false
+	onSuccess   func()       // Called after every cycle in which all due collectors ran and succeeded, may be nil
+}
+
+// New creates a registry with all built-in collectors of the binance provider
+func New(provider exchange.Provider, api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) *Registry {
+	r := &Registry{
+		exchange:    provider.Name(),
+		cfg:         cfg.Collection,
+		priorities:  make(map[string]Priority, len(cfg.Collection.Priorities)),
+		logger:      l,
+		clock:       clock.Real,
+		duration:    prometheus.NewGaugeVec("binance_collector_duration_seconds", "Duration of the last run of the collector", "collector"),
+		runs:        prometheus.NewHistogramVec("binance_collector_run_duration_seconds", "Duration of the runs of the collector", durationBuckets, "collector"),
+		cycleTime:   prometheus.NewHistogramVec("binance_poll_cycle_duration_seconds", "Duration of the poll cycles that ran at least one collector", durationBuckets),
+		overrun:     prometheus.NewGaugeVec("binance_poll_cycle_overrun_seconds", "Time the last poll cycle took longer than EXPORTER_POLL_INTERVAL, 0 if it finished within it"),
+		errors:      prometheus.NewCounterVec("binance_collector_errors_total", "Failed runs of the collector", "collector"),
+		failing:     prometheus.NewGaugeVec("binance_collector_consecutive_failures", "Runs of the collector that failed in a row since its last successful run", "collector"),
+		lastSuccess: prometheus.NewGaugeVec("binance_collector_last_success_timestamp_seconds", "Unix time of the last successful run of the collector", "collector"),
+		skipped:     prometheus.NewCounterVec("binance_collector_skipped_total", "Runs of the collector skipped because the cycle was cut short, by reason", "collector", "reason"),
+		lastRun:     make(map[string]time.Time),
+		succeeded:   make(map[string]bool),
+		failures:    make(map[string]int),
+		endpoints:   make(map[string]map[string]bool),
+		budget:      newBudget(cfg.Collection.WeightBudget),
+		adaptive:    newAdaptive(cfg.Collection, api.UsedWeight),
+		slo:         newSLO(cfg.Collection.SLO),
+		quiet:       newQuiet(cfg.Collection.Quiet),
+		mode:        modeNormal,
+		derived:     newDerived(cfg.Metrics.Derived, l),
+	}
+	for name, priority := range cfg.Collection.Priorities {
+		r.priorities[name] = priorityNames[priority]
+	}
+	r.beat()
+	for _, f := range factories {
+		r.Register(f(api, cfg, l))
+	}
+	r.Register(newPlugins(cfg.Plugins, l)...)
+	// Totals sum up the other collectors, so they are created once all of them exist
+	r.Register(newTotals(api, r.lastGeneration))
+	// The balance drop rule compares the generations the registry publishes
+	r.security = newSecurity(api, cfg, l)
+	r.Register(r.security)
+	for name := range cfg.Collection.Intervals {
+		if r.find(name) == nil {
+			l.Warn("Poll interval configured for an unknown collector", zap.String("collector", name))
+		}
+	}
+	for name := range cfg.Collection.Priorities {
+		if r.find(name) == nil {
+			l.Warn("Priority configured for an unknown collector", zap.String("collector", name))
+		}
+	}
+	for _, name := range cfg.Startup.ReadyCollectors {
+		if r.find(name) == nil {
+			l.Warn("Readiness configured to wait for an unknown collector", zap.String("collector", name))
+		}
+	}
+	return r
+}
+
+func (r *Registry) find(name string) Collector {
+	for _, c := range r.collectors {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (r *Registry) Register(cs ...Collector) {
+	r.collectors = append(r.collectors, cs...)
+}
+
+func (r *Registry) Collectors() []Collector {
+	return r.collectors
+}
+
+// Wallets returns the names of the enabled wallet collectors
+func (r *Registry) Wallets() []string {
+	res := make([]string, 0)
+	for _, c := range r.collectors {
+		if _, ok := c.(*wallet); ok && c.Enabled() {
+			res = append(res, c.Name())
+		}
+	}
+	return res
+}
+
+// Intervals returns the poll interval of every enabled collector by name
+func (r *Registry) Intervals() map[string]time.Duration {
+	res := make(map[string]time.Duration)
+	for _, c := range r.collectors {
+		if c.Enabled() {
+			res[c.Name()] = r.intervalOf(c)
+		}
+	}
+	return res
+}
+
+// Collect runs every enabled collector once, regardless of its interval
+func (r *Registry) Collect(ctx context.Context) {
+	r.cycle(ctx, func(Collector, time.Time) bool { return true })
+	r.ready.Store(true)
+}
+
+/*
+Refresh runs the named collector, or every enabled one when name is empty, right away regardless of its interval. It
+returns the names of the collectors that ran.
+*/
+func (r *Registry) Refresh(ctx context.Context, name string) ([]string, error) {
+	if len(name) > 0 {
+		c := r.find(name)
+		if c == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCollector, name)
+		}
+		if !c.Enabled() {
+			return nil, fmt.Errorf("%w: %s", ErrCollectorDisabled, name)
+		}
+	}
+	ran := make([]string, 0)
+	r.cycle(ctx, func(c Collector, _ time.Time) bool {
+		if len(name) > 0 && c.Name() != name {
+			return false
+		}
+		ran = append(ran, c.Name())
+		return true
+	})
+	return ran, nil
+}
+
+// Events returns the recent events of the security rules, newest first
+func (r *Registry) Events() []SecurityEvent {
+	return r.security.Events()
+}
+
+// UseClock replaces the wall clock the poller, the collector timings and the health check use, e.g. by a clock.Fake
+func (r *Registry) UseClock(c clock.Clock) {
+	r.clock = c
+	for _, col := range r.collectors {
+		if col, ok := col.(clocked); ok {
+			col.useClock(c)
+		}
+	}
+}
+
+// PollWhile makes Poll skip its cycles while active returns false, e.g. while another replica is the leader
+func (r *Registry) PollWhile(active func() bool) {
+	r.active = active
+}
+
+// OnSuccess makes every cycle in which all due collectors ran and succeeded call f, e.g. to ping a heartbeat
+func (r *Registry) OnSuccess(f func()) {
+	r.onSuccess = f
+}
+
+// Ready reports whether a full collection cycle completed
+func (r *Registry) Ready() bool {
+	return r.ready.Load()
+}
+
+/*
+Waiting returns the enabled collectors out of names, or out of all collectors if names is empty, that did not succeed
+once yet. Collectors disabled for a missing permission are not waited for.
+*/
+func (r *Registry) Waiting(names []string) []string {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := make([]string, 0)
+	for _, c := range r.collectors {
+		if !c.Enabled() || r.succeeded[c.Name()] || (len(wanted) > 0 && !wanted[c.Name()]) {
+			continue
+		}
+		res = append(res, c.Name())
+	}
+	return res
+}
+
+/*
+Poll wakes up every cfg.Tick() and runs the collectors whose interval elapsed, until ctx is done. Cycles are skipped
+while on standby and within quiet hours, unless EXPORTER_QUIET_MODE=reduce stretches the intervals instead.
+*/
+func (r *Registry) Poll(ctx context.Context) {
+	tick := r.cfg.Tick()
+	ticker := r.clock.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		r.beat()
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			r.beat()
+			mode := r.quiet.modeAt(now)
+			if r.active != nil && !r.active() {
+				mode = modeStandby
+			}
+			r.lock.Lock()
+			r.mode = mode
+			r.lock.Unlock()
+			if mode == modeStandby || mode == modePaused {
+				continue
+			}
+			r.cycle(ctx, func(c Collector, last time.Time) bool {
+				// Half a tick of slack so ticker jitter doesn't push a collector to the next tick
+				return now.Sub(last)+tick/2 >= r.quiet.scaled(r.adaptive.scaled(r.intervalOf(c)), mode)
+			})
+			r.adaptive.adapt()
+		}
+	}
+}
+
+// intervalOf returns the configured interval of the collector, falling back to its own default and then the global one
+func (r *Registry) intervalOf(c Collector) time.Duration {
+	if _, ok := r.cfg.Intervals[c.Name()]; ok {
+		return r.cfg.IntervalOf(c.Name())
+	}
+	if i, ok := c.(Interval); ok {
+		return i.DefaultInterval()
+	}
+	return r.cfg.Interval
+}
+
+func (r *Registry) beat() {
+	r.heartbeat.Store(r.clock.Now().UnixNano())
+}
+
+/*
+Healthy reports whether the poller is making progress. Between two heartbeats the poller waits at most a tick and runs
+a cycle bounded by the cycle timeout, a poller silent for longer than twice that hangs in a collector ignoring its
+context.
+*/
+func (r *Registry) Healthy() bool {
+	since := r.clock.Since(time.Unix(0, r.heartbeat.Load()))
+	return since < 2*(r.cfg.Tick()+r.cfg.CycleTimeout)
+}
+
+/*
+cycle runs the enabled collectors for which due returns true, at most cfg.Concurrency of them at the same time and
+the highest priority ones first. Collectors still running when the cycle deadline passes get their context
+cancelled, the ones that didn't start yet are skipped, as are the ones left once binance rate limits the cycle. The
+whole cycle is traced as one span and publishes the generation totals are computed from once it completed.
+*/
+func (r *Registry) cycle(ctx context.Context, due func(c Collector, lastRun time.Time) bool) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.CycleTimeout)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "poll_cycle")
+	defer span.End()
+	id := r.cycles.Add(1)
+	start := r.clock.Now()
+
+	pending := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		if c.Enabled() && due(c, r.lastRunOf(c.Name())) {
+			pending = append(pending, c)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return r.priorityOf(pending[i]) > r.priorityOf(pending[j])
+	})
+
+	sem := make(chan struct{}, r.cfg.Concurrency)
+	wg := sync.WaitGroup{}
+	limited := atomic.Bool{}
+	// A failed or skipped collector makes the cycle incomplete
+	incomplete := atomic.Bool{}
+	for i, c := range pending {
+		if !r.budget.take(c, r.priorityOf(c), r.clock.Now()) {
+			// Not counted as run, so it is due again on the next tick
+			tracing.Logger(ctx, r.logger).Debug("Request weight budget is low, dropping the collector from this cycle", zap.String("collector", c.Name()))
+			r.skipped.Inc(c.Name(), "weight_budget")
+			incomplete.Store(true)
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			r.skip(ctx, pending[i:], "deadline")
+			incomplete.Store(true)
+			break
+		}
+		if limited.Load() {
+			<-sem
+			r.skip(ctx, pending[i:], "rate_limited")
+			incomplete.Store(true)
+			break
+		}
+		wg.Add(1)
+		go func(c Collector) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := r.run(ctx, c)
+			if err != nil {
+				incomplete.Store(true)
+			}
+			if errors.Is(err, binance.ErrTooManyRequests) {
+				limited.Store(true)
+			}
+		}(c)
+	}
+	wg.Wait()
+	if len(pending) > 0 {
+		took := r.clock.Since(start)
+		r.cycleTime.Observe(took.Seconds())
+		overrun := 0.0
+		if took > r.cfg.Interval {
+			overrun = (took - r.cfg.Interval).Seconds()
+		}
+		r.overrun.Set(overrun)
+	}
+	r.publish(id)
+	r.security.evaluate(ctx, r.lastGeneration())
+	r.derived.evaluate(r.collectorFamilies())
+	// Cycles without due collectors prove nothing about binance being reachable
+	if len(pending) > 0 && !incomplete.Load() && r.onSuccess != nil {
+		r.onSuccess()
+	}
+}
+
+// skip counts the collectors as skipped in this cycle, they are due again on the next tick
+func (r *Registry) skip(ctx context.Context, collectors []Collector, reason string) {
+	names := make([]string, 0, len(collectors))
+	for _, c := range collectors {
+		r.skipped.Inc(c.Name(), reason)
+		names = append(names, c.Name())
+	}
+	tracing.Logger(ctx, r.logger).Warn("Poll cycle was cut short, skipping the remaining collectors", zap.String("reason", reason), zap.Strings("collectors", names))
+}
+
+// priorityOf returns the configured priority of the collector, falling back to its own and then PriorityNormal
+func (r *Registry) priorityOf(c Collector) Priority {
+	if priority, ok := r.priorities[c.Name()]; ok {
+		return priority
+	}
+	if p, ok := c.(Prioritizer); ok {
+		return p.Priority()
+	}
+	return PriorityNormal
+}
+
+func (r *Registry) lastRunOf(name string) time.Time {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.lastRun[name]
+}
+
+func (r *Registry) run(ctx context.Context, c Collector) error {
+	ctx, span := tracing.Start(ctx, "collect."+c.Name())
+	ctx = binance.WithEndpointRecorder(ctx, func(endpoint string) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if r.endpoints[c.Name()] == nil {
+			r.endpoints[c.Name()] = make(map[string]bool)
+		}
+		r.endpoints[c.Name()][endpoint] = true
+	})
+	start := r.clock.Now()
+	r.lock.Lock()
+	r.lastRun[c.Name()] = start
+	r.lock.Unlock()
+	err := c.Collect(ctx)
+	took := r.clock.Since(start).Seconds()
+	r.duration.Set(took, c.Name())
+	r.runs.Observe(took, c.Name())
+	tracing.End(span, err)
+	r.slo.record(c.Name(), err)
+
+	if err != nil {
+		r.errors.Inc(c.Name())
+		r.lock.Lock()
+		r.failures[c.Name()]++
+		r.failing.Set(float64(r.failures[c.Name()]), c.Name())
+		r.lock.Unlock()
+		tracing.Logger(ctx, r.logger).Debug("Collector failed", zap.String("collector", c.Name()), zap.Error(err))
+		return err
+	}
+	r.lastSuccess.Set(float64(r.clock.Now().Unix()), c.Name())
+	r.lock.Lock()
+	r.succeeded[c.Name()] = true
+	r.failures[c.Name()] = 0
+	r.failing.Set(0, c.Name())
+	r.lock.Unlock()
+	return nil
+}
+
+// Gather returns the metrics of all collectors and the derived ones followed by the collector self metrics
+func (r *Registry) Gather() []prometheus.Family {
+	families := r.collectorFamilies()
+	families = append(families, r.derived.Gather()...)
+	families = append(families, r.selfFamilies()...)
+	// Labels of the collectors win, so a collector could still export series of another exchange
+	return r.naming().Apply(families)
+}
+
+func (r *Registry) collectorFamilies() []prometheus.Family {
+	var families []prometheus.Family
+	for _, c := range r.collectors {
+		families = append(families, c.Gather()...)
+	}
+	return families
+}
+
+func (r *Registry) naming() prometheus.Naming {
+	return prometheus.Naming{ConstLabels: []prometheus.Label{prometheus.L("exchange", r.exchange)}}
+}
+
+// selfFamilies are the metrics the registry exports about the collectors
+func (r *Registry) selfFamilies() []prometheus.Family {
+	var families []prometheus.Family
+	families = append(families, r.disabledFamily())
+	families = append(families, r.modeFamily())
+	families = append(families, r.duration.Gather()...)
+	families = append(families, r.runs.Gather()...)
+	families = append(families, r.cycleTime.Gather()...)
+	families = append(families, r.overrun.Gather()...)
+	families = append(families, r.errors.Gather()...)
+	families = append(families, r.failing.Gather()...)
+	families = append(families, r.lastSuccess.Gather()...)
+	families = append(families, r.skipped.Gather()...)
+	families = append(families, r.budget.Gather()...)
+	families = append(families, r.adaptive.Gather()...)
+	families = append(families, r.slo.Gather()...)
+	return families
+}
+
+// modeFamily has a series per polling mode, 1 for the current one
+func (r *Registry) modeFamily() prometheus.Family {
+	f := prometheus.NewGauge("binance_polling_mode", "Polling mode of the exporter, normal, reduced or paused within quiet hours or standby while another replica leads")
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, mode := range pollingModes {
+		v := 0.0
+		if mode == r.mode {
+			v = 1
+		}
+		f.Add(v, prometheus.L("mode", mode))
+	}
+	return *f
+}
+
+// disabledFamily reports the collectors that were switched off at runtime
+func (r *Registry) disabledFamily() prometheus.Family {
+	f := prometheus.NewGauge("binance_collector_disabled", "Collector was disabled because the API key is not permitted to use it")
+	disabled := make(map[string]string)
+	for _, c := range r.collectors {
+		if d, ok := c.(Disabler); ok {
+			if reason := d.DisableReason(); len(reason) > 0 {
+				disabled[c.Name()] = reason
+			}
+		}
+	}
+	names := make([]string, 0, len(disabled))
+	for name := range disabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f.Add(1, prometheus.L("collector", name), prometheus.L("reason", disabled[name]))
+	}
+	return *f
+}
@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+)
+
+func TestHealthyGoesStale(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	r := &Registry{
+		cfg: config.Collection{
+			Interval:     time.Minute,
+			Intervals:    map[string]time.Duration{"ticker": 15 * time.Second},
+			CycleTimeout: 10 * time.Second,
+		},
+		clock: fake,
+	}
+	stale := 2 * (r.cfg.Tick() + r.cfg.CycleTimeout)
+
+	r.beat()
+	if !r.Healthy() {
+		t.Fatal("not healthy right after a heartbeat")
+	}
+	fake.Advance(stale - time.Nanosecond)
+	if !r.Healthy() {
+		t.Errorf("not healthy %s after the heartbeat, stale only after %s", stale-time.Nanosecond, stale)
+	}
+	fake.Advance(2 * time.Nanosecond)
+	if r.Healthy() {
+		t.Errorf("still healthy %s after the heartbeat", stale+time.Nanosecond)
+	}
+
+	r.beat()
+	if !r.Healthy() {
+		t.Error("not healthy again after the next heartbeat")
+	}
+}
@@ -0,0 +1,222 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/store"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	// securityBucket holds the coins and networks the account withdrew to in the store
+	securityBucket = "security"
+	securityKey    = "withdrawals"
+)
+
+// Rules of EXPORTER_SECURITY_*, the values of the rule label
+const (
+	RuleBalanceDrop = "balance_drop"
+	RuleNewNetwork  = "new_withdrawal_network"
+)
+
+type (
+	// SecurityEvent is a suspicious change of the account one of the security rules flagged
+	SecurityEvent struct {
+		Time    time.Time `json:"time"`
+		Rule    string    `json:"rule"`
+		Message string    `json:"message"`
+	}
+
+	/*
+		security flags suspicious changes of the account as events, counted by rule and listed on /api/v1/events. A
+		balance drop compares the net value in BTC of the collectors valued in both of the last two completed cycles, so
+		a wallet failing to collect isn't a drop, while prices moving against BTC are. A new network is a withdrawal to
+		a coin and network the account never withdrew to before, the withdrawals of the lookback are what it withdrew to
+		before, kept in the store so a restart with EXPORTER_STORE_PATH doesn't forget them.
+	*/
+	security struct {
+		withdrawals permission // Switched off on its own, the balance drop is flagged either way
+		api         binance.BinanceAPI
+		store       *store.Store
+		cfg         config.Security
+		lookback    time.Duration
+		logger      *zap.Logger
+		events      *prometheus.Vec
+		lock        sync.Mutex
+		recent      []SecurityEvent            // Oldest first, at most cfg.MaxEvents
+		cycle       uint64                     // Cycle of the generation the values are from
+		values      map[string]float64         // Net value in BTC by collector after the last evaluated cycle
+		since       time.Time                  // Start of the next withdrawal query, zero before the first one
+		networks    map[withdrawalNetwork]bool // Coins and networks the account withdrew to
+	}
+
+	withdrawalNetwork struct {
+		Coin    string `json:"coin"`
+		Network string `json:"network"`
+	}
+
+	// securityState is what the collector keeps in the store
+	securityState struct {
+		Since    time.Time           `json:"since"`
+		Networks []withdrawalNetwork `json:"networks"`
+	}
+)
+
+func newSecurity(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) *security {
+	s := &security{
+		withdrawals: permission{name: "security", logger: l},
+		api:         api,
+		store:       store.Default,
+		cfg:         cfg.Security,
+		lookback:    cfg.History.Lookback,
+		logger:      l,
+		events:      prometheus.NewCounterVec("binance_security_events_total", "Suspicious changes of the account flagged by the security rule", "rule"),
+		networks:    make(map[withdrawalNetwork]bool),
+	}
+	// Enabled rules start at 0, so increase() sees their first event
+	if s.cfg.BalanceDrop > 0 {
+		s.events.Add(0, RuleBalanceDrop)
+	}
+	if s.cfg.NewNetworks {
+		s.events.Add(0, RuleNewNetwork)
+		s.load(l)
+	}
+	return s
+}
+
+// load continues from the coins and networks in the store
+func (s *security) load(l *zap.Logger) {
+	state := securityState{}
+	ok, err := s.store.Get(securityBucket, securityKey, &state)
+	if err != nil {
+		l.Warn("Failed to load the withdrawal networks from the store, taking them from the lookback again", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	s.since = state.Since
+	for _, n := range state.Networks {
+		s.networks[n] = true
+	}
+}
+
+func (s *security) Name() string {
+	return "security"
+}
+
+func (s *security) Enabled() bool {
+	return s.cfg.BalanceDrop > 0 || s.cfg.NewNetworks
+}
+
+func (s *security) Priority() Priority {
+	return PriorityLow
+}
+
+// DefaultInterval is a few minutes like the address book, a withdrawal to a new network should not go unnoticed for long
+func (s *security) DefaultInterval() time.Duration {
+	return 5 * time.Minute
+}
+
+// Collect fetches the withdrawals since the last run, the balance drop is evaluated by the registry after every cycle
+func (s *security) Collect(ctx context.Context) error {
+	if !s.cfg.NewNetworks || !s.withdrawals.permitted() {
+		return nil
+	}
+	now := time.Now()
+	s.lock.Lock()
+	from, seeding := s.since, s.since.IsZero()
+	s.lock.Unlock()
+	if seeding {
+		from = now.Add(-s.lookback)
+	}
+	withdrawals, err := s.api.GetWithdrawals(ctx, from, now)
+	if err != nil {
+		return s.withdrawals.check(err)
+	}
+
+	s.lock.Lock()
+	for _, w := range withdrawals {
+		n := withdrawalNetwork{Coin: w.Coin, Network: w.Network}
+		if s.networks[n] {
+			continue
+		}
+		s.networks[n] = true
+		// The withdrawals of the lookback are the ones the account is known to make
+		if !seeding {
+			s.flag(ctx, RuleNewNetwork, fmt.Sprintf("Withdrawal %s of %s %s on %s, the account never withdrew %s on %s before", w.ID, w.Amount, w.Coin, w.Network, w.Coin, w.Network))
+		}
+	}
+	s.since = now.Add(-historyOverlap)
+	state := securityState{Since: s.since, Networks: make([]withdrawalNetwork, 0, len(s.networks))}
+	for n := range s.networks {
+		state.Networks = append(state.Networks, n)
+	}
+	s.lock.Unlock()
+
+	if err := s.store.Put(securityBucket, securityKey, state); err != nil {
+		tracing.Logger(ctx, s.logger).Warn("Failed to store the withdrawal networks, they are taken from the lookback again after a restart", zap.Error(err))
+	}
+	return nil
+}
+
+// evaluate flags a drop of the net value from the previous generation to g, every generation is evaluated once
+func (s *security) evaluate(ctx context.Context, g *generation) {
+	if s.cfg.BalanceDrop <= 0 || g == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if g.cycle <= s.cycle {
+		return
+	}
+	before, after := 0.0, 0.0
+	for name, value := range g.valuations {
+		if previous, ok := s.values[name]; ok {
+			before += previous
+			after += value
+		}
+	}
+	s.cycle, s.values = g.cycle, g.valuations
+	if before <= 0 {
+		return
+	}
+	if drop := (before - after) / before; drop >= s.cfg.BalanceDrop {
+		s.flag(ctx, RuleBalanceDrop, fmt.Sprintf("Net value dropped by %.1f%% from %g to %g BTC within one poll cycle", drop*100, before, after))
+	}
+}
+
+// flag counts and records an event, the caller holds the lock
+func (s *security) flag(ctx context.Context, rule, message string) {
+	tracing.Logger(ctx, s.logger).Warn("Security rule flagged an event", zap.String("rule", rule), zap.String("event", message))
+	s.events.Inc(rule)
+	s.recent = append(s.recent, SecurityEvent{Time: time.Now().UTC(), Rule: rule, Message: message})
+	if len(s.recent) > s.cfg.MaxEvents {
+		s.recent = s.recent[len(s.recent)-s.cfg.MaxEvents:]
+	}
+}
+
+// Events returns the recent events, newest first
+func (s *security) Events() []SecurityEvent {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	res := make([]SecurityEvent, 0, len(s.recent))
+	for i := len(s.recent) - 1; i >= 0; i-- {
+		res = append(res, s.recent[i])
+	}
+	return res
+}
+
+func (s *security) Gather() []prometheus.Family {
+	if !s.Enabled() {
+		return nil
+	}
+	return s.events.Gather()
+}
@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+/*
+slo keeps the outcome of the last runs of every collector, so "the funding wallet succeeded less than 95% of the time
+over the last hour" is a plain comparison instead of a ratio of increases over the error and run counters. The error
+budget is the share of the failures the target allows that is left, it goes negative once the target is missed.
+*/
+type slo struct {
+	cfg  config.SLO
+	lock sync.Mutex
+	runs map[string]*outcomes
+}
+
+// outcomes is a ring buffer of the last runs of a collector
+type outcomes struct {
+	failed   []bool
+	next     int
+	failures int
+}
+
+// newSLO returns nil with an EXPORTER_SLO_WINDOW of 0
+func newSLO(cfg config.SLO) *slo {
+	if cfg.Window == 0 {
+		return nil
+	}
+	return &slo{cfg: cfg, runs: make(map[string]*outcomes)}
+}
+
+// record adds the outcome of a run of the collector, dropping the oldest run once the window is full
+func (s *slo) record(collector string, err error) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	o := s.runs[collector]
+	if o == nil {
+		o = &outcomes{failed: make([]bool, 0, s.cfg.Window)}
+		s.runs[collector] = o
+	}
+	failed := err != nil
+	if len(o.failed) < s.cfg.Window {
+		o.failed = append(o.failed, failed)
+	} else {
+		if o.failed[o.next] {
+			o.failures--
+		}
+		o.failed[o.next] = failed
+		o.next = (o.next + 1) % s.cfg.Window
+	}
+	if failed {
+		o.failures++
+	}
+}
+
+func (s *slo) Gather() []prometheus.Family {
+	if s == nil {
+		return nil
+	}
+	ratio := prometheus.NewGauge("binance_collector_success_ratio", "Share of the last EXPORTER_SLO_WINDOW runs of the collector that succeeded")
+	budget := prometheus.NewGauge("binance_collector_error_budget_remaining_ratio", "Share of the failures EXPORTER_SLO_TARGET allows over the window that is left, negative once the target is missed")
+	runs := prometheus.NewGauge("binance_collector_slo_runs", "Runs of the collector the success ratio covers, up to EXPORTER_SLO_WINDOW")
+	target := prometheus.NewGauge("binance_collector_slo_target", "Success ratio the error budgets are computed against")
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	names := make([]string, 0, len(s.runs))
+	for name := range s.runs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o := s.runs[name]
+		total := float64(len(o.failed))
+		l := prometheus.L("collector", name)
+		ratio.Add(1-float64(o.failures)/total, l)
+		budget.Add(1-float64(o.failures)/(total*(1-s.cfg.Target)), l)
+		runs.Add(total, l)
+	}
+	target.Add(s.cfg.Target)
+	return []prometheus.Family{*ratio, *budget, *runs, *target}
+}
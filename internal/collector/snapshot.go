@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+)
+
+// Snapshot is the state of the holdings of the account at one point in time
+type Snapshot struct {
+	Time       time.Time                            `json:"time"`
+	Cycle      uint64                               `json:"cycle"`      // Poll cycle the holdings are from, 0 before the first one completed
+	Wallets    map[balance.Wallet][]balance.Balance `json:"wallets"`    // Balances by wallet
+	Valuations map[string]float64                   `json:"valuations"` // Net value in BTC by collector
+	TotalBTC   float64                              `json:"total_btc"`
+	TotalUSD   float64                              `json:"total_usd,omitempty"` // 0 while the BTC price is unknown
+}
+
+// Snapshot returns the balances and valuations after the last completed poll cycle
+func (r *Registry) Snapshot() Snapshot {
+	s := Snapshot{Time: r.clock.Now().UTC(), Wallets: make(map[balance.Wallet][]balance.Balance), Valuations: make(map[string]float64)}
+	g := r.lastGeneration()
+	if g == nil {
+		return s
+	}
+	s.Time, s.Cycle = g.time, g.cycle
+	for name, balances := range g.wallets {
+		balances = append([]balance.Balance(nil), balances...)
+		sort.Slice(balances, func(i, j int) bool { return balances[i].Asset < balances[j].Asset })
+		s.Wallets[name] = balances
+	}
+	for name, value := range g.valuations {
+		s.Valuations[name] = value
+		s.TotalBTC += value
+	}
+	s.TotalUSD = s.TotalBTC * g.btcUSD
+	return s
+}
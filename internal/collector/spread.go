@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// spreadSampleInterval is the time between two spread observations of a symbol
+const spreadSampleInterval = time.Second
+
+// spreadBuckets are the upper bounds of the spread histogram in basis points
+var spreadBuckets = []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 25, 50, 100}
+
+/*
+spread observes the bid-ask spread of the watchlist symbols from the book ticker stream into a histogram, so execution
+cost percentiles like histogram_quantile(0.99, ...) are queryable instead of only the spread at scrape time. The book
+changes many times a second, sampling every symbol once per spreadSampleInterval weighs the histogram by time instead
+of by the number of book updates.
+*/
+type spread struct {
+	streamer
+	api       binance.BinanceAPI
+	symbols   []string
+	enabled   bool
+	histogram *prometheus.HistogramVec
+	lock      sync.Mutex
+	sampled   map[string]time.Time // Last observation by symbol
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &spread{
+			streamer:  streamer{name: "spread", logger: l},
+			api:       api,
+			symbols:   cfg.Market.Watchlist,
+			enabled:   cfg.Market.SpreadHistogram,
+			histogram: prometheus.NewHistogramVec("binance_symbol_spread_observed_bps", "Spread of the symbol in basis points of the mid price, sampled from the book ticker stream every second", spreadBuckets, "symbol"),
+			sampled:   make(map[string]time.Time),
+		}
+	})
+}
+
+func (s *spread) Name() string {
+	return s.name
+}
+
+func (s *spread) Enabled() bool {
+	return s.enabled && len(s.symbols) > 0
+}
+
+// Weight is 0, streams don't count towards the request weight
+func (s *spread) Weight() int {
+	return 0
+}
+
+func (s *spread) Priority() Priority {
+	return PriorityLow
+}
+
+func (s *spread) Collect(context.Context) error {
+	return s.status(func(ctx context.Context) error {
+		return s.api.StreamBookTickers(ctx, s.symbols, s.observe)
+	})
+}
+
+func (s *spread) observe(b binance.BookTicker) {
+	now := time.Now()
+	s.lock.Lock()
+	due := now.Sub(s.sampled[b.Symbol]) >= spreadSampleInterval
+	if due {
+		s.sampled[b.Symbol] = now
+	}
+	s.lock.Unlock()
+	if !due {
+		return
+	}
+	bid, bidErr := strconv.ParseFloat(b.BidPrice, 64)
+	ask, askErr := strconv.ParseFloat(b.AskPrice, 64)
+	if bidErr != nil || askErr != nil || bid <= 0 || ask <= 0 {
+		return
+	}
+	s.histogram.Observe(10000*(ask-bid)/((ask+bid)/2), b.Symbol)
+}
+
+func (s *spread) Gather() []prometheus.Family {
+	return s.histogram.Gather()
+}
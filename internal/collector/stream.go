@@ -0,0 +1,59 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/reporting"
+	"go.uber.org/zap"
+)
+
+// streamRetry is the wait before reconnecting a dropped market stream
+const streamRetry = 5 * time.Second
+
+/*
+streamer keeps a market stream of a collector connected. The stream is connected on the first run of the collector
+and reconnected whenever it drops, the runs only report whether it is connected, so a dropped stream shows up as a
+failing collector.
+*/
+type streamer struct {
+	name   string
+	logger *zap.Logger
+	once   sync.Once
+	lock   sync.Mutex
+	err    error // Why the stream dropped, nil while it is connected or connecting
+}
+
+// status connects the stream on the first call and returns why it dropped, nil while it is connected
+func (s *streamer) status(connect func(ctx context.Context) error) error {
+	s.once.Do(func() {
+		go func() {
+			defer reporting.Recover()
+			s.run(context.Background(), connect)
+		}()
+	})
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+// run keeps the stream connected until the context ends
+func (s *streamer) run(ctx context.Context, connect func(ctx context.Context) error) {
+	for ctx.Err() == nil {
+		s.setErr(nil)
+		err := connect(ctx)
+		s.setErr(err)
+		s.logger.Warn("Market stream dropped, reconnecting", zap.String("collector", s.name), zap.Error(err), zap.Duration("retry", streamRetry))
+		select {
+		case <-ctx.Done():
+		case <-time.After(streamRetry):
+		}
+	}
+}
+
+func (s *streamer) setErr(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.err = err
+}
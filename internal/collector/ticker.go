@@ -0,0 +1,165 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+ticker exports the average price and the best bid and ask of the watchlist symbols. With holdings discovery the
+watchlist also gets the symbol of every asset held in the spot or funding wallet against the quote asset, as far as
+binance trades it, so the watched symbols follow the holdings without maintaining a list.
+*/
+type ticker struct {
+	api        binance.BinanceAPI
+	watchlist  []string
+	holdings   bool
+	quote      string
+	lock       sync.Mutex
+	listing    listing
+	symbols    []string // Watched in the last run
+	discovered int      // Of symbols, not on the static watchlist
+	avg        map[string]binance.AvgPrice
+	books      []binance.BookTicker
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &ticker{api: api, watchlist: cfg.Market.Watchlist, holdings: cfg.Market.WatchHoldings, quote: cfg.Market.WatchlistQuote}
+	})
+}
+
+func (t *ticker) Name() string {
+	return "ticker"
+}
+
+func (t *ticker) useClock(c clock.Clock) {
+	t.listing.useClock(c)
+}
+
+func (t *ticker) Enabled() bool {
+	return len(t.watchlist) > 0 || t.holdings
+}
+
+// Weight of the average price of every symbol and one book ticker request for all of them, plus the symbol listing
+// when discovery has to refresh it
+func (t *ticker) Weight() int {
+	t.lock.Lock()
+	weight := 2*len(t.symbols) + 4
+	if t.symbols == nil {
+		weight = 2*len(t.watchlist) + 4
+	}
+	t.lock.Unlock()
+	if t.holdings && t.listing.due() {
+		weight += 20
+	}
+	return weight
+}
+
+func (t *ticker) Collect(ctx context.Context) error {
+	symbols, err := t.watched(ctx)
+	if err != nil {
+		return err
+	}
+	avg := make(map[string]binance.AvgPrice, len(symbols))
+	for _, symbol := range symbols {
+		price, err := t.api.GetAvgPrice(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		avg[symbol] = price
+	}
+	books := make([]binance.BookTicker, 0)
+	if len(symbols) > 0 {
+		if books, err = t.api.GetBookTickers(ctx, symbols); err != nil {
+			return err
+		}
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.symbols = symbols
+	t.discovered = len(symbols) - len(t.watchlist)
+	t.avg = avg
+	t.books = books
+	return nil
+}
+
+// watched returns the static watchlist and the symbols discovered from the holdings, sorted
+func (t *ticker) watched(ctx context.Context) ([]string, error) {
+	if !t.holdings {
+		return t.watchlist, nil
+	}
+	if err := t.listing.refresh(ctx, t.api); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, symbol := range t.watchlist {
+		seen[symbol] = true
+	}
+	// The wallet collectors keep the last balances, discovery spends no request weight on them
+	wallets := append(balance.FromBinance(balance.Spot, t.api.GetSpotAssets()), balance.FromBinance(balance.Funding, t.api.GetFundingAssets())...)
+	for _, b := range wallets {
+		symbol := b.Asset + t.quote
+		if b.Asset == t.quote || !t.listing.trades(symbol) || balance.Sum(b.Free, b.Locked, b.Freeze, b.Withdrawing) <= 0 {
+			continue
+		}
+		seen[symbol] = true
+	}
+	res := make([]string, 0, len(seen))
+	for symbol := range seen {
+		res = append(res, symbol)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func (t *ticker) Gather() []prometheus.Family {
+	avgPrice := prometheus.NewGauge("binance_symbol_avg_price", "Average price of the symbol over the last minutes")
+	bidPrice := prometheus.NewGauge("binance_symbol_bid_price", "Best bid price of the symbol")
+	bidQty := prometheus.NewGauge("binance_symbol_bid_quantity", "Quantity at the best bid price of the symbol")
+	askPrice := prometheus.NewGauge("binance_symbol_ask_price", "Best ask price of the symbol")
+	askQty := prometheus.NewGauge("binance_symbol_ask_quantity", "Quantity at the best ask price of the symbol")
+	spread := prometheus.NewGauge("binance_symbol_spread", "Difference between the best ask and bid price of the symbol")
+	spreadBps := prometheus.NewGauge("binance_symbol_spread_bps", "Spread of the symbol in basis points of the mid price")
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, symbol := range t.symbols {
+		if price, ok := t.avg[symbol]; ok {
+			addParsed(avgPrice, price.Price, prometheus.L("symbol", symbol))
+		}
+	}
+	for _, b := range t.books {
+		l := prometheus.L("symbol", b.Symbol)
+		addParsed(bidPrice, b.BidPrice, l)
+		addParsed(bidQty, b.BidQty, l)
+		addParsed(askPrice, b.AskPrice, l)
+		addParsed(askQty, b.AskQty, l)
+
+		bid, bidErr := strconv.ParseFloat(b.BidPrice, 64)
+		ask, askErr := strconv.ParseFloat(b.AskPrice, 64)
+		if bidErr != nil || askErr != nil || bid <= 0 || ask <= 0 {
+			continue
+		}
+		spread.Add(ask-bid, l)
+		spreadBps.Add(10000*(ask-bid)/((ask+bid)/2), l)
+	}
+	res := []prometheus.Family{*avgPrice, *bidPrice, *bidQty, *askPrice, *askQty, *spread, *spreadBps}
+	if t.holdings && t.symbols != nil {
+		discovered := prometheus.NewGauge("binance_watchlist_discovered_symbols", "Symbols watched because the asset is held, not because they are on EXPORTER_WATCHLIST")
+		discovered.Add(float64(t.discovered))
+		res = append(res, *discovered)
+	}
+	return res
+}
@@ -0,0 +1,97 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+// usdSymbol prices BTC in USD, USDT standing in for the dollar
+const usdSymbol = "BTCUSDT"
+
+/*
+totals sums up the value of every enabled collector holding assets into the total balance of the account, so net
+worth on binance is a single series instead of a PromQL sum over differently shaped metrics. The totals are computed
+from the generation of the last completed cycle, collecting only fetches the price to convert them to USD.
+*/
+type totals struct {
+	api        binance.BinanceAPI
+	generation func() *generation
+	lock       sync.Mutex
+	btcUSD     float64 // 0 until the price was fetched
+}
+
+func newTotals(api binance.BinanceAPI, generation func() *generation) *totals {
+	return &totals{api: api, generation: generation}
+}
+
+func (t *totals) Name() string {
+	return "totals"
+}
+
+func (t *totals) Enabled() bool {
+	return true
+}
+
+func (t *totals) Weight() int {
+	return 2
+}
+
+func (t *totals) Priority() Priority {
+	return PriorityHigh
+}
+
+func (t *totals) Collect(ctx context.Context) error {
+	price, err := t.api.GetAvgPrice(ctx, usdSymbol)
+	if err != nil {
+		return err
+	}
+	btcUSD, err := strconv.ParseFloat(price.Price, 64)
+	if err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.btcUSD = btcUSD
+	return nil
+}
+
+func (t *totals) Gather() []prometheus.Family {
+	btc := prometheus.NewGauge("binance_total_balance_btc", "Net value of all holdings of the account across wallets in BTC")
+	usd := prometheus.NewGauge("binance_total_balance_usd", "Net value of all holdings of the account across wallets in USD, priced through "+usdSymbol)
+	fiat := prometheus.NewGauge("binance_total_balance_fiat", "Net value of all holdings of the account across wallets in the currency, converted from USD at binance_fx_rate")
+
+	g := t.generation()
+	if g == nil || len(g.valuations) == 0 {
+		return nil
+	}
+	total := 0.0
+	for _, value := range g.valuations {
+		total += value
+	}
+	btc.Add(total)
+	if g.btcUSD > 0 {
+		usd.Add(total * g.btcUSD)
+		currencies := make([]string, 0, len(g.fx))
+		for currency := range g.fx {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+		for _, currency := range currencies {
+			fiat.Add(total*g.btcUSD*g.fx[currency], prometheus.L("currency", currency))
+		}
+	}
+	return []prometheus.Family{*btc, *usd, *fiat}
+}
+
+// price returns the BTC price in USD, 0 until it was fetched
+func (t *totals) price() float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.btcUSD
+}
@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+transferTypes are the universal transfer types polled unless EXPORTER_TRANSFER_TYPES says otherwise. Transfers between
+isolated margin accounts are left out, their history is queried per symbol.
+*/
+var transferTypes = []string{
+	"MAIN_FUNDING", "FUNDING_MAIN", "MAIN_MARGIN", "MARGIN_MAIN", "FUNDING_MARGIN", "MARGIN_FUNDING",
+	"MAIN_UMFUTURE", "UMFUTURE_MAIN", "MAIN_CMFUTURE", "CMFUTURE_MAIN", "UMFUTURE_MARGIN", "MARGIN_UMFUTURE",
+	"CMFUTURE_MARGIN", "MARGIN_CMFUTURE", "FUNDING_UMFUTURE", "UMFUTURE_FUNDING", "FUNDING_CMFUTURE", "CMFUTURE_FUNDING",
+	"MAIN_OPTION", "OPTION_MAIN", "UMFUTURE_OPTION", "OPTION_UMFUTURE", "MARGIN_OPTION", "OPTION_MARGIN",
+	"FUNDING_OPTION", "OPTION_FUNDING", "MAIN_PORTFOLIO_MARGIN", "PORTFOLIO_MARGIN_MAIN",
+}
+
+// transferWallets names the wallets of the transfer types like the wallet collectors, longest prefix first
+var transferWallets = []struct {
+	prefix, wallet string
+}{
+	{"PORTFOLIO_MARGIN", "portfolio_margin"},
+	{"UMFUTURE", "futures"},
+	{"CMFUTURE", "coin_futures"},
+	{"FUNDING", "funding"},
+	{"MARGIN", "margin"},
+	{"OPTION", "options"},
+	{"MAIN", "spot"},
+}
+
+// transferDirection returns the source and target wallet of the transfer type, false for types it doesn't know
+func transferDirection(transferType string) (string, string, bool) {
+	for _, from := range transferWallets {
+		rest, ok := strings.CutPrefix(transferType, from.prefix+"_")
+		if !ok {
+			continue
+		}
+		for _, to := range transferWallets {
+			if rest == to.prefix {
+				return from.wallet, to.wallet, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+type (
+	/*
+		transfers counts the confirmed universal transfers between the wallets of the account by type and asset, so
+		money moving from spot to funding shows up as such instead of as a withdrawal from one wallet and a deposit to
+		the other.
+	*/
+	transfers struct {
+		permission
+		api     binance.BinanceAPI
+		enabled bool
+		types   []string
+		lock    sync.Mutex
+		history history // A source per transfer type
+		counts  map[transferKey]int
+		amounts map[transferKey]float64
+	}
+	transferKey struct {
+		transferType, asset string
+	}
+)
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		types := cfg.History.TransferTypes
+		if len(types) == 0 {
+			types = transferTypes
+		}
+		for _, transferType := range types {
+			if _, _, ok := transferDirection(transferType); !ok {
+				l.Warn("Unknown transfer type, its transfers are only counted by type", zap.String("type", transferType))
+			}
+		}
+		return &transfers{
+			permission: permission{name: "transfers", logger: l},
+			api:        api,
+			enabled:    cfg.History.Transfers,
+			types:      types,
+			history:    newHistory("transfers", cfg.History),
+			counts:     make(map[transferKey]int),
+			amounts:    make(map[transferKey]float64),
+		}
+	})
+}
+
+func (t *transfers) Name() string {
+	return t.name
+}
+
+func (t *transfers) Enabled() bool {
+	return t.enabled && t.permitted()
+}
+
+// Weight of a page of the history of every transfer type
+func (t *transfers) Weight() int {
+	return len(t.types)
+}
+
+func (t *transfers) Priority() Priority {
+	return PriorityLow
+}
+
+func (t *transfers) DefaultInterval() time.Duration {
+	return 5 * time.Minute
+}
+
+func (t *transfers) Collect(ctx context.Context) error {
+	now := time.Now()
+	for _, transferType := range t.types {
+		records, err := t.api.GetUniversalTransfers(ctx, transferType, t.from(transferType, now), now)
+		if err != nil {
+			return t.check(err)
+		}
+		t.lock.Lock()
+		for _, r := range records {
+			// Pending transfers are counted once they are confirmed, failed ones never
+			if r.Status != "CONFIRMED" || !t.history.count(transferType+":"+strconv.FormatInt(r.TranID, 10), time.UnixMilli(r.Timestamp)) {
+				continue
+			}
+			k := transferKey{transferType: transferType, asset: r.Asset}
+			t.counts[k]++
+			t.amounts[k] += parseOrZero(r.Amount)
+		}
+		t.history.advance(transferType, now.Add(-historyOverlap))
+		t.lock.Unlock()
+	}
+	return nil
+}
+
+func (t *transfers) from(source string, now time.Time) time.Time {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.history.from(source, now)
+}
+
+/*
+Gather exports the transfers by their binance type and, for the types between known wallets, by source and target
+wallet named like the wallet collectors.
+*/
+func (t *transfers) Gather() []prometheus.Family {
+	counts := prometheus.NewCounter("binance_universal_transfers_total", "Confirmed universal transfers by binance transfer type and asset")
+	amounts := prometheus.NewCounter("binance_universal_transfer_amount_total", "Amount moved by universal transfers by binance transfer type and asset")
+	internalCounts := prometheus.NewCounter("binance_internal_transfers_total", "Confirmed transfers between the wallets of the account by source and target wallet and asset")
+	internalAmounts := prometheus.NewCounter("binance_internal_transfer_amount_total", "Amount moved between the wallets of the account by source and target wallet and asset")
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.history.started() {
+		return nil
+	}
+	keys := make([]transferKey, 0, len(t.counts))
+	for k := range t.counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.transferType != b.transferType {
+			return a.transferType < b.transferType
+		}
+		return a.asset < b.asset
+	})
+	for _, k := range keys {
+		counts.Add(float64(t.counts[k]), prometheus.L("type", k.transferType), prometheus.L("asset", k.asset))
+		amounts.Add(t.amounts[k], prometheus.L("type", k.transferType), prometheus.L("asset", k.asset))
+		if from, to, ok := transferDirection(k.transferType); ok {
+			labels := []prometheus.Label{prometheus.L("from", from), prometheus.L("to", to), prometheus.L("asset", k.asset)}
+			internalCounts.Add(float64(t.counts[k]), labels...)
+			internalAmounts.Add(t.amounts[k], labels...)
+		}
+	}
+	return []prometheus.Family{*counts, *amounts, *internalCounts, *internalAmounts}
+}
@@ -0,0 +1,216 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"go.uber.org/zap"
+)
+
+// seriesPerAsset is the number of series a single asset of a wallet exports
+const seriesPerAsset = 6
+
+var droppedSeries = prometheus.NewCounterVec("binance_dropped_series_total", "Series not exported because the wallet holds more assets than EXPORTER_MAX_ASSETS_PER_WALLET", "wallet")
+
+func init() {
+	prometheus.Default.MustRegister(droppedSeries)
+}
+
+// wallet exports the balances of one binance wallet, one gauge per asset field
+type wallet struct {
+	name        balance.Wallet
+	api         binance.BinanceAPI
+	fetch       func(ctx context.Context) error
+	raw         func() []binance.Asset // Assets of the last fetch
+	logger      *zap.Logger
+	labels      config.Assets
+	state       bool // Export a single balance metric with a state label
+	maxAssets   int
+	lock        sync.Mutex
+	lastDropped int // Assets dropped by the previous collection, to only log changes
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return newWallet(balance.Funding, api.GetFundingWallet, api.GetFundingAssets, api, cfg, l)
+	})
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return newWallet(balance.Spot, api.GetSpotWallet, api.GetSpotAssets, api, cfg, l)
+	})
+}
+
+func newWallet(name balance.Wallet, fetch func(ctx context.Context) error, raw func() []binance.Asset, api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) *wallet {
+	return &wallet{
+		name:      name,
+		api:       api,
+		fetch:     fetch,
+		raw:       raw,
+		logger:    l,
+		labels:    cfg.Assets,
+		state:     cfg.Metrics.StateLabel,
+		maxAssets: cfg.Metrics.MaxAssets,
+	}
+}
+
+func (w *wallet) Name() string {
+	return string(w.name)
+}
+
+// balances returns the balances of the last fetch
+func (w *wallet) balances() []balance.Balance {
+	return balance.FromBinance(w.name, w.raw())
+}
+
+func (w *wallet) Enabled() bool {
+	return len(w.DisableReason()) == 0
+}
+
+// Weight of getUserAsset, the funding wallet endpoint is cheaper but this is close enough
+func (w *wallet) Weight() int {
+	return 5
+}
+
+func (w *wallet) Priority() Priority {
+	return PriorityHigh
+}
+
+func (w *wallet) DisableReason() string {
+	return w.api.DisabledCollectors()[w.Name()]
+}
+
+func (w *wallet) Collect(ctx context.Context) error {
+	if err := w.fetch(ctx); err != nil {
+		return err
+	}
+	_, dropped := capAssets(excludeCategories(w.balances(), w.labels.ExcludeCategories), w.maxAssets)
+	if dropped > 0 {
+		droppedSeries.Add(float64(dropped*seriesPerAsset), w.Name())
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if dropped != w.lastDropped {
+		log := tracing.Logger(ctx, w.logger).With(zap.String("wallet", w.Name()), zap.Int("max_assets", w.maxAssets))
+		if dropped > 0 {
+			log.Warn("Wallet holds more assets than allowed, dropping the lowest valued ones", zap.Int("dropped_assets", dropped))
+		} else {
+			log.Info("Wallet is back under EXPORTER_MAX_ASSETS_PER_WALLET, exporting all of its assets")
+		}
+		w.lastDropped = dropped
+	}
+	return nil
+}
+
+func (w *wallet) Gather() []prometheus.Family {
+	balances, _ := capAssets(excludeCategories(w.balances(), w.labels.ExcludeCategories), w.maxAssets)
+	if w.state {
+		return balanceFamilies(balances, w.labels)
+	}
+	return assetFamilies(w.Name(), balances, w.labels)
+}
+
+// ValueBTC sums up the BTC valuation of all assets, including the ones dropped by EXPORTER_MAX_ASSETS_PER_WALLET or
+// excluded by category
+func (w *wallet) ValueBTC() (float64, bool) {
+	total := 0.0
+	for _, b := range w.balances() {
+		total += b.ValueBTC.Float()
+	}
+	return total, true
+}
+
+/*
+capAssets keeps the max highest valued assets (by BTC valuation) and returns how many were dropped. Accounts holding
+hundreds of airdropped dust tokens would otherwise flood prometheus with series. A max of 0 keeps everything.
+*/
+func capAssets(balances []balance.Balance, max int) ([]balance.Balance, int) {
+	if max == 0 || len(balances) <= max {
+		return balances, 0
+	}
+	sorted := append([]balance.Balance(nil), balances...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ValueBTC.Float() > sorted[j].ValueBTC.Float()
+	})
+	return sorted[:max], len(sorted) - max
+}
+
+func parseOrZero(value string) float64 {
+	v, _ := strconv.ParseFloat(value, 64)
+	return v
+}
+
+/*
+balanceFamilies exports all balance fields of all wallets as one gauge with wallet and state labels, which makes
+stacked dashboards of balance states a single query. The BTC valuation is not a state and gets its own gauge.
+*/
+func balanceFamilies(balances []balance.Balance, labels config.Assets) []prometheus.Family {
+	states := prometheus.NewGauge("binance_asset_balance", "Balance of the asset in the wallet by state")
+	btc := prometheus.NewGauge("binance_asset_btc_valuation", "Value of the asset in the wallet in BTC")
+
+	for _, b := range balances {
+		l := append([]prometheus.Label{b.Wallet.Label()}, assetLabels(labels, b.Asset)...)
+		for _, s := range []struct {
+			state  string
+			amount balance.Amount
+		}{
+			{"free", b.Free},
+			{"locked", b.Locked},
+			{"freeze", b.Freeze},
+			{"withdrawing", b.Withdrawing},
+			{"ipoable", b.Ipoable},
+		} {
+			addAmount(states, s.amount, append(l[:len(l):len(l)], prometheus.L("state", s.state))...)
+		}
+		addAmount(btc, b.ValueBTC, l...)
+	}
+	return []prometheus.Family{*states, *btc}
+}
+
+// assetFamilies converts the assets of a wallet into one gauge per asset field
+func assetFamilies(wallet string, balances []balance.Balance, labels config.Assets) []prometheus.Family {
+	prefix := "binance_" + wallet + "_asset_"
+	free := prometheus.NewGauge(prefix+"free", "Free balance of the asset in the "+wallet+" wallet")
+	locked := prometheus.NewGauge(prefix+"locked", "Locked balance of the asset in the "+wallet+" wallet")
+	freeze := prometheus.NewGauge(prefix+"freeze", "Frozen balance of the asset in the "+wallet+" wallet")
+	withdrawing := prometheus.NewGauge(prefix+"withdrawing", "Balance of the asset being withdrawn from the "+wallet+" wallet")
+	ipoable := prometheus.NewGauge(prefix+"ipoable", "Balance of the asset in the "+wallet+" wallet usable for IPO subscriptions")
+	btc := prometheus.NewGauge(prefix+"btc_valuation", "Value of the asset in the "+wallet+" wallet in BTC")
+	families := []*prometheus.Family{free, locked, freeze, withdrawing, ipoable, btc}
+	for _, f := range families {
+		f.Samples = make([]prometheus.Sample, 0, len(balances))
+	}
+
+	for _, b := range balances {
+		l := assetLabels(labels, b.Asset)
+		addAmount(free, b.Free, l...)
+		addAmount(locked, b.Locked, l...)
+		addAmount(freeze, b.Freeze, l...)
+		addAmount(withdrawing, b.Withdrawing, l...)
+		addAmount(ipoable, b.Ipoable, l...)
+		addAmount(btc, b.ValueBTC, l...)
+	}
+	return []prometheus.Family{*free, *locked, *freeze, *withdrawing, *ipoable, *btc}
+}
+
+// addAmount adds the sample only if the amount is a number, amounts the exchange left out are skipped
+func addAmount(f *prometheus.Family, amount balance.Amount, labels ...prometheus.Label) {
+	if v, ok := amount.Parse(); ok {
+		f.Add(v, labels...)
+	}
+}
+
+// addParsed adds the sample only if binance returned a parseable number, empty fields are skipped
+func addParsed(f *prometheus.Family, value string, labels ...prometheus.Label) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+	f.Add(v, labels...)
+}
@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+withdrawAddresses counts the addresses of the withdrawal address book and how many of them are whitelisted, so adding
+an address, the step before stealing funds from a compromised account, is alertable. Binance doesn't report whether the
+whitelist is switched on, only which addresses are on it.
+*/
+type withdrawAddresses struct {
+	permission
+	api       binance.BinanceAPI
+	lock      sync.Mutex
+	addresses []binance.WithdrawAddress
+	collected bool
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &withdrawAddresses{permission: permission{name: "withdraw_addresses", logger: l}, api: api}
+	})
+}
+
+func (w *withdrawAddresses) Name() string {
+	return w.name
+}
+
+func (w *withdrawAddresses) Enabled() bool {
+	return w.permitted()
+}
+
+func (w *withdrawAddresses) Weight() int {
+	return 10
+}
+
+func (w *withdrawAddresses) Priority() Priority {
+	return PriorityLow
+}
+
+// DefaultInterval is a few minutes, the address book changes rarely but a change should not go unnoticed for long
+func (w *withdrawAddresses) DefaultInterval() time.Duration {
+	return 5 * time.Minute
+}
+
+func (w *withdrawAddresses) Collect(ctx context.Context) error {
+	addresses, err := w.api.GetWithdrawAddresses(ctx)
+	if err != nil {
+		return w.check(err)
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.addresses, w.collected = addresses, true
+	return nil
+}
+
+func (w *withdrawAddresses) Gather() []prometheus.Family {
+	addresses := prometheus.NewGauge("binance_withdraw_addresses", "Addresses saved in the withdrawal address book by coin and network")
+	whitelisted := prometheus.NewGauge("binance_withdraw_whitelist_addresses", "Addresses of the withdrawal address book on the withdrawal whitelist")
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if !w.collected {
+		return nil
+	}
+	type key struct {
+		coin, network string
+		whitelisted   bool
+	}
+	counts := make(map[key]int)
+	total := 0
+	for _, a := range w.addresses {
+		counts[key{a.Coin, a.Network, a.WhiteStatus}]++
+		if a.WhiteStatus {
+			total++
+		}
+	}
+	for k, n := range counts {
+		addresses.Add(float64(n), prometheus.L("coin", k.coin), prometheus.L("network", k.network), prometheus.L("whitelisted", strconv.FormatBool(k.whitelisted)))
+	}
+	whitelisted.Add(float64(total))
+	return []prometheus.Family{*addresses, *whitelisted}
+}
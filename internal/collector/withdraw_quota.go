@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+// withdrawQuota exports how much of the rolling 24h withdrawal limit is left
+type withdrawQuota struct {
+	permission
+	api   binance.BinanceAPI
+	lock  sync.Mutex
+	quota *binance.WithdrawQuota
+}
+
+func init() {
+	register(func(api binance.BinanceAPI, cfg *config.Config, l *zap.Logger) Collector {
+		return &withdrawQuota{permission: permission{name: "withdraw_quota", logger: l}, api: api}
+	})
+}
+
+func (w *withdrawQuota) Name() string {
+	return w.name
+}
+
+func (w *withdrawQuota) Enabled() bool {
+	return w.permitted()
+}
+
+func (w *withdrawQuota) Weight() int {
+	return 10
+}
+
+func (w *withdrawQuota) Collect(ctx context.Context) error {
+	quota, err := w.api.GetWithdrawQuota(ctx)
+	if err != nil {
+		return w.check(err)
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.quota = &quota
+	return nil
+}
+
+func (w *withdrawQuota) Gather() []prometheus.Family {
+	limit := prometheus.NewGauge("binance_withdraw_quota_limit", "Rolling 24h withdrawal limit of the account, in the valuation binance reports it in")
+	used := prometheus.NewGauge("binance_withdraw_quota_used", "Part of the rolling 24h withdrawal limit already used")
+	ratio := prometheus.NewGauge("binance_withdraw_quota_used_ratio", "Used part of the rolling 24h withdrawal limit, 1 means no withdrawals are possible")
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.quota == nil {
+		return nil
+	}
+	addParsed(limit, w.quota.WdQuota)
+	addParsed(used, w.quota.UsedWdQuota)
+	if total := parseOrZero(w.quota.WdQuota); total > 0 {
+		ratio.Add(parseOrZero(w.quota.UsedWdQuota) / total)
+	}
+	return []prometheus.Family{*limit, *used, *ratio}
+}
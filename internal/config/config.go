@@ -0,0 +1,1018 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Entrio/subenv"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/expr"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/ledger"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/schedule"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/version"
+)
+
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
+
+	SnapshotJSON = "json"
+	SnapshotCSV  = "csv"
+
+	QuietPause  = "pause"
+	QuietReduce = "reduce"
+
+	StartupFailFast      = "fail-fast"
+	StartupServeDegraded = "serve-degraded"
+
+	RoutesMetrics = "metrics" // /metrics and /metrics/<account>
+	RoutesAPI     = "api"     // /api/v1
+	RoutesAdmin   = "admin"   // /debug and /-/
+	RoutesWeb     = "web"     // The landing page, /dashboard.json, /alerts.yaml and /metrics-docs
+
+	CategoryCrypto     = "crypto"
+	CategoryFiat       = "fiat"
+	CategoryFanToken   = "fan_token"
+	CategoryUntradable = "untradable" // Not tradable on spot, like launch tokens before their listing and delisted ones
+)
+
+// AssetCategories are the classes assets are sorted into from the binance coin metadata
+var AssetCategories = []string{CategoryCrypto, CategoryFiat, CategoryFanToken, CategoryUntradable}
+
+type (
+	// Config holds the exporter settings, binance credentials are read by the binance client itself
+	Config struct {
+		Account     string // Name of the binance account, shown on the landing page and serving its metrics at /metrics/<account>
+		Listeners   []Listener
+		UserAgent   string // Sent on every outbound request, EXPORTER_USER_AGENT_SUFFIX appended to the exporter version
+		Startup     Startup
+		Log         Log
+		Runtime     Runtime
+		Collection  Collection
+		Metrics     Metrics
+		Assets      Assets
+		Tracing     bool // Export OTLP traces, the exporter itself is configured through the OTEL_* variables
+		Sentry      Sentry
+		Admin       Admin
+		Auth        Auth
+		Scrape      Scrape
+		Leader      Leader
+		Market      Market
+		Derivatives Derivatives
+		History     History
+		Store       Store
+		PnL         PnL
+		Snapshot    Snapshot
+		Heartbeat   Heartbeat
+		Plugins     Plugins
+		FX          FX
+		Maintenance Maintenance
+		Security    Security
+		Alerts      Alerts
+	}
+	// Startup decides what happens when binance is unreachable or under maintenance as the exporter starts
+	Startup struct {
+		Policy          string        // fail-fast exits, serve-degraded serves right away and keeps checking in the background
+		RetryInterval   time.Duration // Time between status checks while serving degraded
+		ReadyCollectors []string      // Collectors a serve-degraded exporter waits for before it is ready, empty for all enabled ones
+	}
+	// Alerts are the thresholds of the alerting rules served on /alerts.yaml
+	Alerts struct {
+		MarginLevel    float64 // Margin level below which margin accounts alert, binance liquidates at 1.1
+		StaleIntervals int     // Poll intervals without a successful run before a collector counts as stale
+		Failures       int     // Failed runs of a collector in a row before it alerts
+		Depeg          float64 // Deviation of a held stablecoin from 1.00 above which it alerts
+		MaxLeverage    int     // Leverage configured for a futures symbol above which it alerts
+	}
+	// Snapshot periodically uploads the balances to S3 compatible object storage, disabled while Bucket is empty
+	Snapshot struct {
+		Endpoint  string // Base url like https://s3.eu-central-1.amazonaws.com, buckets are addressed path style
+		Bucket    string
+		Region    string
+		Prefix    string        // Prepended to the object keys
+		Format    string        // json or csv
+		Interval  time.Duration // Time between snapshots
+		AccessKey string
+		SecretKey string
+	}
+	/*
+		Heartbeat pings URL after every poll cycle in which all collectors succeeded, so a dead man's switch like
+		healthchecks.io notices an exporter that hangs or fails as a whole. Disabled while URL is empty.
+	*/
+	Heartbeat struct {
+		URL         string
+		MinInterval time.Duration // Pings are skipped until this long after the previous one
+		Timeout     time.Duration
+	}
+	// FX rates convert the USD totals into other currencies, the fx collector is disabled while Currencies is empty
+	FX struct {
+		Currencies []string      // Currency codes like EUR the totals are reported in
+		URL        string        // ECB daily reference rates XML or a JSON object of rates
+		Interval   time.Duration // Time between fetches of the rates
+		Timeout    time.Duration
+	}
+	// Maintenance reads the scheduled system maintenance from the binance.com announcements
+	Maintenance struct {
+		Enabled  bool
+		URL      string        // Article list of the maintenance catalog
+		Interval time.Duration // Time between fetches of the announcements
+		Timeout  time.Duration
+	}
+	// Security rules flag suspicious changes of the account as events, every rule is disabled at its zero value
+	Security struct {
+		BalanceDrop float64 // Share of the net value of the account lost within one poll cycle that is an event
+		NewNetworks bool    // A withdrawal to a coin and network the account never withdrew to before is an event
+		MaxEvents   int     // Most recent events listed on /api/v1/events
+	}
+	// Plugins are external commands printing samples as JSON, every one of them runs as a collector named plugin_<name>
+	Plugins struct {
+		Commands   map[string][]string // Plugin name -> command and its arguments
+		Timeout    time.Duration       // Time a run of a command may take
+		MaxSamples int                 // Samples accepted from one run, the run fails beyond that
+		Env        []string            // EXPORTER_*, B_* and AWS_* variables passed on to the commands, all others are dropped
+	}
+	// Store keeps collector state like the cost basis across restarts, it only lives in memory while Path is empty
+	Store struct {
+		Path string
+	}
+	// PnL tracks the cost basis of the base assets of Symbols from the trade history, it is disabled while empty
+	PnL struct {
+		Symbols       []string // Symbols quoted in a USD stablecoin, like BTCUSDT
+		Method        string   // How sales are matched against buys, ledger.Average or ledger.FIFO
+		BackfillPages int      // Pages of trades fetched per symbol and collection while catching up with the history
+		MaxLots       int      // Unsold buys kept per symbol for FIFO, the oldest ones are merged beyond that
+	}
+	// History collectors count records of the account history, the counters start over on every restart
+	History struct {
+		Payments          bool          // Count Binance Pay transactions and P2P orders, their endpoints are expensive so this is opt-in
+		Rebates           bool          // Count spot and futures referral rebates, opt-in for the same reason
+		Transfers         bool          // Count transfers between the wallets of the account
+		EarnSubscriptions bool          // Count Simple Earn flexible subscriptions, auto-subscribe sweeps included
+		Accruals          bool          // Count earn rewards, margin interest and funding fees, kept in the store across restarts
+		TransferTypes     []string      // Universal transfer types polled, all except the isolated margin ones while empty
+		Lookback          time.Duration // History counted at startup, so the counters don't start from zero
+		MaxEntries        int           // Ids of counted records remembered per collector to not count them twice
+	}
+	// Derivatives collectors poll account types only some users have, so they are opt-in
+	Derivatives struct {
+		PortfolioMargin bool // Poll the Portfolio Margin account, the classic endpoints show nothing of its balances
+		Options         bool // Poll the options account and positions
+	}
+	// Market data collectors only run for the symbols they are configured for
+	Market struct {
+		Watchlist          []string // Symbols like BTCUSDT, the ticker collector is disabled while empty
+		WatchHoldings      bool     // Add the symbol of every held asset against WatchlistQuote to the watchlist
+		WatchlistQuote     string   // Quote asset of the symbols discovered from the holdings
+		SpreadHistogram    bool     // Observe the spread of the watchlist symbols from the book ticker stream into a histogram
+		AssetPrices        bool     // Resolve the USD price of every held asset, the asset_prices collector is disabled otherwise
+		PriceBridges       []string // Assets an asset without a USDT symbol is priced through, tried in order
+		DepthSymbols       []string // Symbols whose order book is sampled, the depth collector is disabled while empty
+		DepthBands         []int    // Distances from the mid price in basis points the book volume is summed up within
+		DepthLimit         int      // Levels requested per side, deeper books cost more request weight
+		KlineSymbols       []string // Symbols kline indicators are derived for, the kline collector is disabled while empty
+		EarnAssets         []string // Assets whose Simple Earn flexible rates are exported, the earn_rates collector is disabled while empty
+		Stablecoins        []string // Stablecoins whose peg is monitored while held, the stablecoin_peg collector is disabled while empty
+		FuturesSymbols     []string // USDⓈ-M futures symbols to export the insurance fund and long/short ratio of, the futures_market collector is disabled while empty
+		LiquidationSymbols []string // USDⓈ-M futures symbols whose liquidation stream is counted, the liquidations collector is disabled while empty
+	}
+	// Leader election through a kubernetes Lease, only the leader of several replicas polls binance
+	Leader struct {
+		Enabled   bool
+		Lease     string        // Name of the Lease object
+		Namespace string        // Namespace of the Lease, defaults to the one of the pod
+		Identity  string        // Holder identity, defaults to $POD_NAME and then to the hostname
+		Duration  time.Duration // Time a standby waits before taking over an unrenewed lease
+	}
+	// Scrape limits protect the exporter from aggressive scrapers, 0 disables a limit
+	Scrape struct {
+		Concurrency int           // Concurrent /metrics renders
+		RateLimit   int           // Scrapes per minute and client IP
+		OnDemand    bool          // Collect on every scrape instead of polling in the background
+		Deadline    time.Duration // Time an on-demand collection may take, should be shorter than the scrape timeout
+	}
+	// Runtime fits the Go runtime to the limits of the container, GOMAXPROCS always follows the CPU quota
+	Runtime struct {
+		MemoryLimitRatio float64 // Share of the container memory limit used as soft memory limit, 0 leaves it alone
+	}
+	// Listener is an address the HTTP server listens on and the route groups it serves there, probes are served on all
+	Listener struct {
+		Address string   // TCP address, tcp4:// or tcp6:// for a single stack, or unix:///path/to.sock
+		Routes  []string // Route groups served, all of them while empty
+	}
+	// Auth are the bearer tokens of the route groups, a group without a token is served to anyone
+	Auth struct {
+		Metrics  string            // Required on /metrics and accepted on every /metrics/<account>
+		API      string            // Required on /api/v1
+		Accounts map[string]string // Account -> token required on its /metrics/<account> unless the metrics token is given
+	}
+	// Admin guards the /debug and /-/ endpoints, which are not served while Token is empty
+	Admin struct {
+		Token            string // Expected as "Authorization: Bearer <token>"
+		CaptureRequests  int    // Outbound requests kept per endpoint for /debug/requests, 0 disables capturing
+		CaptureBodyLimit int    // Bytes of every captured response body
+	}
+	Metrics struct {
+		Namespace   string            // Replaces the default binance namespace
+		Subsystem   string            // Optional prefix inserted after the namespace
+		ConstLabels map[string]string // Attached to every exported series
+		StateLabel  bool              // Export balances as binance_asset_balance{wallet,asset,state} instead of a metric per wallet and field
+		MaxAssets   int               // Assets exported per wallet, highest valued first, 0 for no limit
+		Derived     map[string]string // Metric name -> expression over the other metrics, evaluated after every poll cycle
+	}
+	// Assets adds alias and group labels to asset metrics, each label is only exported once it is configured
+	Assets struct {
+		Aliases      map[string]string // asset -> alias, e.g. WBTC -> BTC
+		Groups       map[string]string // asset or alias -> group, e.g. USDT -> stablecoins
+		DefaultGroup string            // Group of assets without one
+		// Categories come from the binance coin metadata, so classes of assets are handled without listing them
+		CategoryLabel     bool     // Add a category label
+		ExcludeCategories []string // Assets of these categories are not exported per asset, they still count towards the totals
+	}
+	Collection struct {
+		Interval     time.Duration            // Default time between runs of a collector
+		Intervals    map[string]time.Duration // Per collector overrides of Interval
+		Concurrency  int                      // Collectors running at the same time
+		CycleTimeout time.Duration            // Deadline of a whole poll cycle
+		WeightBudget int                      // Request weight per minute the collectors may spend, 0 for no limit
+		Priorities   map[string]string        // Per collector overrides of the priority, low, normal or high
+		Adaptive     Adaptive
+		SLO          SLO
+		Quiet        Quiet
+	}
+	// Quiet hours pause or slow down polling during the windows, e.g. while a nightly job shares the API key
+	Quiet struct {
+		Windows []string // Cron expressions of the start followed by the duration, like "0 2 * * * 90m"
+		Mode    string   // pause skips the cycles, reduce multiplies the intervals by Factor
+		Factor  float64
+	}
+	// SLO tracks the share of successful runs of every collector over its last Window runs
+	SLO struct {
+		Window int     // Runs the success ratio covers, 0 disables it
+		Target float64 // Success ratio the error budget is computed against, e.g. 0.95
+	}
+	/*
+		Adaptive scales all poll intervals with the request weight headroom of the key: the exporter polls faster while
+		little of WeightLimit is used and backs off while other consumers use it up. Interval stays between MinInterval
+		and MaxInterval, the other intervals scale along with it.
+	*/
+	Adaptive struct {
+		Enabled     bool
+		MinInterval time.Duration
+		MaxInterval time.Duration
+		WeightLimit int // Request weight per minute binance allows the IP
+	}
+	// Sentry error reporting, disabled while DSN is empty
+	Sentry struct {
+		DSN              string
+		Environment      string
+		FailureThreshold int // Consecutive collection failures before they get reported
+	}
+	Log struct {
+		Level  string // debug, info, warn or error
+		Format string // console or json
+		File   LogFile
+		Syslog string // Empty to disable, "local" for the local daemon or network://host:port
+		// Successful requests are access logged 1 in AccessSample, 0 only logs the others, errors are always logged
+		AccessSample int
+	}
+	// LogFile is an optional rotating log file, disabled while Path is empty
+	LogFile struct {
+		Path       string
+		MaxSizeMB  int
+		MaxBackups int
+		MaxAgeDays int
+	}
+)
+
+// usdQuotes are the stablecoins standing in for the dollar when valuing trades in USD
+var usdQuotes = []string{"USDT", "USDC", "FDUSD", "TUSD", "BUSD"}
+
+// USDBase returns the base asset of a symbol quoted in a USD stablecoin, e.g. BTC of BTCUSDT
+func USDBase(symbol string) (string, bool) {
+	for _, quote := range usdQuotes {
+		if base, ok := strings.CutSuffix(symbol, quote); ok && len(base) > 0 {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+// Load reads the configuration from the environment and validates it
+func Load() (*Config, error) {
+	intervals, err := parseDurations(subenv.Env("EXPORTER_COLLECTOR_INTERVALS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_COLLECTOR_INTERVALS: %w", err)
+	}
+
+	priorities, err := parsePairs(subenv.Env("EXPORTER_COLLECTOR_PRIORITIES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_COLLECTOR_PRIORITIES: %w", err)
+	}
+
+	constLabels, err := parsePairs(subenv.Env("EXPORTER_CONST_LABELS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_CONST_LABELS: %w", err)
+	}
+
+	accountTokens, err := parsePairs(subenv.Env("EXPORTER_ACCOUNT_TOKENS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_ACCOUNT_TOKENS: %w", err)
+	}
+
+	bands, err := parseInts(subenv.Env("EXPORTER_DEPTH_BPS", "10,50,100"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_DEPTH_BPS: %w", err)
+	}
+
+	aliases, err := parsePairs(subenv.Env("EXPORTER_ASSET_ALIASES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_ASSET_ALIASES: %w", err)
+	}
+	// Categories are label values and lower case, unlike the assets parseList is meant for
+	excludedCategories := parseList(subenv.Env("EXPORTER_EXCLUDE_ASSET_CATEGORIES", ""))
+	for i := range excludedCategories {
+		excludedCategories[i] = strings.ToLower(excludedCategories[i])
+	}
+	groups, err := parsePairs(subenv.Env("EXPORTER_ASSET_GROUPS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_ASSET_GROUPS: %w", err)
+	}
+
+	marginLevel, err := strconv.ParseFloat(subenv.Env("EXPORTER_ALERT_MARGIN_LEVEL", "1.5"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_ALERT_MARGIN_LEVEL: %w", err)
+	}
+
+	depeg, err := strconv.ParseFloat(subenv.Env("EXPORTER_ALERT_DEPEG", "0.02"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_ALERT_DEPEG: %w", err)
+	}
+
+	memoryLimitRatio, err := strconv.ParseFloat(subenv.Env("EXPORTER_MEMORY_LIMIT_RATIO", "0.9"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_MEMORY_LIMIT_RATIO: %w", err)
+	}
+
+	balanceDrop, err := strconv.ParseFloat(subenv.Env("EXPORTER_SECURITY_BALANCE_DROP", "0"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_SECURITY_BALANCE_DROP: %w", err)
+	}
+
+	derived, err := parseDefinitions(subenv.Env("EXPORTER_DERIVED_METRICS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_DERIVED_METRICS: %w", err)
+	}
+
+	plugins, err := parsePairs(subenv.Env("EXPORTER_PLUGINS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_PLUGINS: %w", err)
+	}
+	commands := make(map[string][]string, len(plugins))
+	for name, command := range plugins {
+		commands[name] = strings.Fields(command)
+	}
+
+	sloTarget, err := strconv.ParseFloat(subenv.Env("EXPORTER_SLO_TARGET", "0.95"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_SLO_TARGET: %w", err)
+	}
+
+	quietFactor, err := strconv.ParseFloat(subenv.Env("EXPORTER_QUIET_FACTOR", "4"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_QUIET_FACTOR: %w", err)
+	}
+
+	c := &Config{
+		Account:   subenv.Env("EXPORTER_ACCOUNT", "default"),
+		Listeners: parseListeners(subenv.Env("EXPORTER_LISTEN", ":1323")),
+		UserAgent: version.UserAgent(strings.TrimSpace(subenv.Env("EXPORTER_USER_AGENT_SUFFIX", ""))),
+		Startup: Startup{
+			Policy:          strings.ToLower(subenv.Env("EXPORTER_STARTUP_POLICY", StartupFailFast)),
+			RetryInterval:   time.Duration(subenv.EnvI("EXPORTER_STARTUP_RETRY_INTERVAL", 30)) * time.Second,
+			ReadyCollectors: parseList(subenv.Env("EXPORTER_READY_COLLECTORS", "")),
+		},
+		Log: Log{
+			Level:  subenv.Env("EXPORTER_LOG_LEVEL", "info"),
+			Format: subenv.Env("EXPORTER_LOG_FORMAT", LogFormatConsole),
+			File: LogFile{
+				Path:       subenv.Env("EXPORTER_LOG_FILE", ""),
+				MaxSizeMB:  subenv.EnvI("EXPORTER_LOG_FILE_MAX_SIZE", 100),
+				MaxBackups: subenv.EnvI("EXPORTER_LOG_FILE_MAX_BACKUPS", 5),
+				MaxAgeDays: subenv.EnvI("EXPORTER_LOG_FILE_MAX_AGE", 28),
+			},
+			Syslog:       subenv.Env("EXPORTER_LOG_SYSLOG", ""),
+			AccessSample: subenv.EnvI("EXPORTER_ACCESS_LOG_SAMPLE", 1),
+		},
+		Runtime: Runtime{
+			MemoryLimitRatio: memoryLimitRatio,
+		},
+		Metrics: Metrics{
+			Namespace:   subenv.Env("EXPORTER_METRIC_NAMESPACE", "binance"),
+			Subsystem:   subenv.Env("EXPORTER_METRIC_SUBSYSTEM", ""),
+			ConstLabels: constLabels,
+			StateLabel:  subenv.EnvB("EXPORTER_BALANCE_STATE_LABEL", true),
+			MaxAssets:   subenv.EnvI("EXPORTER_MAX_ASSETS_PER_WALLET", 0),
+			Derived:     derived,
+		},
+		Assets: Assets{
+			Aliases:           aliases,
+			Groups:            groups,
+			DefaultGroup:      subenv.Env("EXPORTER_ASSET_DEFAULT_GROUP", "other"),
+			CategoryLabel:     subenv.EnvB("EXPORTER_ASSET_CATEGORY_LABEL", false),
+			ExcludeCategories: excludedCategories,
+		},
+		Collection: Collection{
+			Interval:     time.Duration(subenv.EnvI("EXPORTER_POLL_INTERVAL", 60)) * time.Second,
+			Intervals:    intervals,
+			Concurrency:  subenv.EnvI("EXPORTER_CONCURRENCY", 4),
+			CycleTimeout: time.Duration(subenv.EnvI("EXPORTER_CYCLE_TIMEOUT", 0)) * time.Second,
+			WeightBudget: subenv.EnvI("EXPORTER_WEIGHT_BUDGET", 0),
+			Priorities:   priorities,
+			Adaptive: Adaptive{
+				Enabled:     subenv.EnvB("EXPORTER_ADAPTIVE_INTERVAL", false),
+				MinInterval: time.Duration(subenv.EnvI("EXPORTER_MIN_POLL_INTERVAL", 15)) * time.Second,
+				MaxInterval: time.Duration(subenv.EnvI("EXPORTER_MAX_POLL_INTERVAL", 300)) * time.Second,
+				WeightLimit: subenv.EnvI("EXPORTER_WEIGHT_LIMIT", 6000),
+			},
+			SLO: SLO{
+				Window: subenv.EnvI("EXPORTER_SLO_WINDOW", 60),
+				Target: sloTarget,
+			},
+			Quiet: Quiet{
+				Windows: parseSchedules(subenv.Env("EXPORTER_QUIET_HOURS", "")),
+				Mode:    strings.ToLower(subenv.Env("EXPORTER_QUIET_MODE", QuietPause)),
+				Factor:  quietFactor,
+			},
+		},
+		Tracing: subenv.EnvB("EXPORTER_TRACING", false),
+		Sentry: Sentry{
+			DSN:              subenv.Env("EXPORTER_SENTRY_DSN", ""),
+			Environment:      subenv.Env("EXPORTER_SENTRY_ENVIRONMENT", "production"),
+			FailureThreshold: subenv.EnvI("EXPORTER_SENTRY_FAILURE_THRESHOLD", 3),
+		},
+		Admin: Admin{
+			Token:            subenv.Env("EXPORTER_ADMIN_TOKEN", ""),
+			CaptureRequests:  subenv.EnvI("EXPORTER_DEBUG_REQUESTS", 0),
+			CaptureBodyLimit: subenv.EnvI("EXPORTER_DEBUG_BODY_LIMIT", 4096),
+		},
+		Auth: Auth{
+			Metrics:  subenv.Env("EXPORTER_METRICS_TOKEN", ""),
+			API:      subenv.Env("EXPORTER_API_TOKEN", ""),
+			Accounts: accountTokens,
+		},
+		Leader: Leader{
+			Enabled:   subenv.EnvB("EXPORTER_LEADER_ELECTION", false),
+			Lease:     subenv.Env("EXPORTER_LEADER_LEASE", "binance-exporter"),
+			Namespace: subenv.Env("EXPORTER_LEADER_NAMESPACE", ""),
+			Identity:  subenv.Env("EXPORTER_LEADER_IDENTITY", ""),
+			Duration:  time.Duration(subenv.EnvI("EXPORTER_LEADER_LEASE_DURATION", 15)) * time.Second,
+		},
+		Market: Market{
+			Watchlist:          parseList(subenv.Env("EXPORTER_WATCHLIST", "")),
+			WatchHoldings:      subenv.EnvB("EXPORTER_WATCHLIST_FROM_HOLDINGS", false),
+			WatchlistQuote:     strings.ToUpper(subenv.Env("EXPORTER_WATCHLIST_QUOTE", "USDT")),
+			SpreadHistogram:    subenv.EnvB("EXPORTER_SPREAD_HISTOGRAM", false),
+			AssetPrices:        subenv.EnvB("EXPORTER_ASSET_PRICES", false),
+			PriceBridges:       parseList(subenv.Env("EXPORTER_PRICE_BRIDGES", "BTC,BNB")),
+			DepthSymbols:       parseList(subenv.Env("EXPORTER_DEPTH_SYMBOLS", "")),
+			DepthBands:         bands,
+			DepthLimit:         subenv.EnvI("EXPORTER_DEPTH_LIMIT", 100),
+			KlineSymbols:       parseList(subenv.Env("EXPORTER_KLINE_SYMBOLS", "")),
+			EarnAssets:         parseList(subenv.Env("EXPORTER_EARN_ASSETS", "")),
+			Stablecoins:        parseList(subenv.Env("EXPORTER_STABLECOINS", "")),
+			FuturesSymbols:     parseList(subenv.Env("EXPORTER_FUTURES_MARKET_SYMBOLS", "")),
+			LiquidationSymbols: parseList(subenv.Env("EXPORTER_LIQUIDATION_SYMBOLS", "")),
+		},
+		Derivatives: Derivatives{
+			PortfolioMargin: subenv.EnvB("EXPORTER_PORTFOLIO_MARGIN", false),
+			Options:         subenv.EnvB("EXPORTER_OPTIONS", false),
+		},
+		History: History{
+			Payments:          subenv.EnvB("EXPORTER_PAYMENT_HISTORY", false),
+			Rebates:           subenv.EnvB("EXPORTER_REBATE_HISTORY", false),
+			Accruals:          subenv.EnvB("EXPORTER_ACCRUAL_HISTORY", false),
+			Transfers:         subenv.EnvB("EXPORTER_TRANSFER_HISTORY", false),
+			EarnSubscriptions: subenv.EnvB("EXPORTER_EARN_SUBSCRIPTION_HISTORY", false),
+			TransferTypes:     parseList(strings.ToUpper(subenv.Env("EXPORTER_TRANSFER_TYPES", ""))),
+			Lookback:          time.Duration(subenv.EnvI("EXPORTER_HISTORY_LOOKBACK_DAYS", 30)) * 24 * time.Hour,
+			MaxEntries:        subenv.EnvI("EXPORTER_HISTORY_MAX_ENTRIES", 100000),
+		},
+		Store: Store{
+			Path: subenv.Env("EXPORTER_STORE_PATH", ""),
+		},
+		PnL: PnL{
+			Symbols:       parseList(subenv.Env("EXPORTER_PNL_SYMBOLS", "")),
+			Method:        strings.ToLower(subenv.Env("EXPORTER_PNL_METHOD", ledger.Average)),
+			BackfillPages: subenv.EnvI("EXPORTER_PNL_BACKFILL_PAGES", 5),
+			MaxLots:       subenv.EnvI("EXPORTER_PNL_MAX_LOTS", 10000),
+		},
+		Snapshot: Snapshot{
+			Endpoint:  strings.TrimSuffix(subenv.Env("EXPORTER_SNAPSHOT_S3_ENDPOINT", "https://s3.amazonaws.com"), "/"),
+			Bucket:    subenv.Env("EXPORTER_SNAPSHOT_S3_BUCKET", ""),
+			Region:    subenv.Env("EXPORTER_SNAPSHOT_S3_REGION", "us-east-1"),
+			Prefix:    subenv.Env("EXPORTER_SNAPSHOT_S3_PREFIX", ""),
+			Format:    strings.ToLower(subenv.Env("EXPORTER_SNAPSHOT_FORMAT", SnapshotJSON)),
+			Interval:  time.Duration(subenv.EnvI("EXPORTER_SNAPSHOT_INTERVAL", 24*60*60)) * time.Second,
+			AccessKey: subenv.Env("AWS_ACCESS_KEY_ID", ""),
+			SecretKey: subenv.Env("AWS_SECRET_ACCESS_KEY", ""),
+		},
+		Heartbeat: Heartbeat{
+			URL:         subenv.Env("EXPORTER_HEARTBEAT_URL", ""),
+			MinInterval: time.Duration(subenv.EnvI("EXPORTER_HEARTBEAT_MIN_INTERVAL", 60)) * time.Second,
+			Timeout:     time.Duration(subenv.EnvI("EXPORTER_HEARTBEAT_TIMEOUT", 10)) * time.Second,
+		},
+		FX: FX{
+			Currencies: parseList(subenv.Env("EXPORTER_FX_CURRENCIES", "")),
+			URL:        subenv.Env("EXPORTER_FX_URL", "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"),
+			Interval:   time.Duration(subenv.EnvI("EXPORTER_FX_INTERVAL", 3600)) * time.Second,
+			Timeout:    time.Duration(subenv.EnvI("EXPORTER_FX_TIMEOUT", 10)) * time.Second,
+		},
+		Maintenance: Maintenance{
+			Enabled:  subenv.EnvB("EXPORTER_MAINTENANCE_FEED", false),
+			URL:      subenv.Env("EXPORTER_MAINTENANCE_URL", "https://www.binance.com/bapi/composite/v1/public/cms/article/list/query?type=1&catalogId=157&pageNo=1&pageSize=20"),
+			Interval: time.Duration(subenv.EnvI("EXPORTER_MAINTENANCE_INTERVAL", 900)) * time.Second,
+			Timeout:  time.Duration(subenv.EnvI("EXPORTER_MAINTENANCE_TIMEOUT", 10)) * time.Second,
+		},
+		Security: Security{
+			BalanceDrop: balanceDrop,
+			NewNetworks: subenv.EnvB("EXPORTER_SECURITY_NEW_NETWORKS", false),
+			MaxEvents:   subenv.EnvI("EXPORTER_SECURITY_EVENTS", 100),
+		},
+		Plugins: Plugins{
+			Commands:   commands,
+			Timeout:    time.Duration(subenv.EnvI("EXPORTER_PLUGIN_TIMEOUT", 10)) * time.Second,
+			MaxSamples: subenv.EnvI("EXPORTER_PLUGIN_MAX_SAMPLES", 1000),
+			Env:        parseList(subenv.Env("EXPORTER_PLUGIN_ENV", "")),
+		},
+		Alerts: Alerts{
+			MarginLevel:    marginLevel,
+			StaleIntervals: subenv.EnvI("EXPORTER_ALERT_STALE_INTERVALS", 3),
+			Failures:       subenv.EnvI("EXPORTER_ALERT_CONSECUTIVE_FAILURES", 5),
+			Depeg:          depeg,
+			MaxLeverage:    subenv.EnvI("EXPORTER_ALERT_MAX_LEVERAGE", 20),
+		},
+		Scrape: Scrape{
+			Concurrency: subenv.EnvI("EXPORTER_SCRAPE_CONCURRENCY", 4),
+			RateLimit:   subenv.EnvI("EXPORTER_SCRAPE_RATE_LIMIT", 0),
+			OnDemand:    subenv.EnvB("EXPORTER_COLLECT_ON_SCRAPE", false),
+			Deadline:    time.Duration(subenv.EnvI("EXPORTER_SCRAPE_DEADLINE", 8)) * time.Second,
+		},
+	}
+	return c, c.validate()
+}
+
+func (c *Config) validate() error {
+	switch c.Log.Format {
+	case LogFormatConsole, LogFormatJSON:
+	default:
+		return fmt.Errorf("invalid EXPORTER_LOG_FORMAT %q, expected %s or %s", c.Log.Format, LogFormatConsole, LogFormatJSON)
+	}
+	if len(c.Listeners) == 0 {
+		return fmt.Errorf("invalid EXPORTER_LISTEN, needs at least one address")
+	}
+	for _, l := range c.Listeners {
+		for _, routes := range l.Routes {
+			switch routes {
+			case RoutesMetrics, RoutesAPI, RoutesAdmin, RoutesWeb:
+			default:
+				return fmt.Errorf("invalid EXPORTER_LISTEN routes %q of %s, expected %s, %s, %s or %s", routes, l.Address, RoutesMetrics, RoutesAPI, RoutesAdmin, RoutesWeb)
+			}
+		}
+	}
+	if c.Runtime.MemoryLimitRatio < 0 || c.Runtime.MemoryLimitRatio > 1 {
+		return fmt.Errorf("invalid EXPORTER_MEMORY_LIMIT_RATIO %g, has to be between 0 and 1", c.Runtime.MemoryLimitRatio)
+	}
+	if c.Log.AccessSample < 0 {
+		return fmt.Errorf("invalid EXPORTER_ACCESS_LOG_SAMPLE %d, has to be 0 or positive", c.Log.AccessSample)
+	}
+	if len(c.Account) == 0 || url.PathEscape(c.Account) != c.Account {
+		return fmt.Errorf("invalid EXPORTER_ACCOUNT %q, has to be a non-empty path segment", c.Account)
+	}
+	for account, token := range c.Auth.Accounts {
+		if account != c.Account || len(token) == 0 {
+			return fmt.Errorf("invalid EXPORTER_ACCOUNT_TOKENS entry for %q, expected account=token of the account %q", account, c.Account)
+		}
+	}
+	if c.Collection.Interval <= 0 {
+		return fmt.Errorf("invalid EXPORTER_POLL_INTERVAL %s, has to be positive", c.Collection.Interval)
+	}
+	if c.Collection.Concurrency <= 0 {
+		return fmt.Errorf("invalid EXPORTER_CONCURRENCY %d, has to be positive", c.Collection.Concurrency)
+	}
+	if c.Collection.WeightBudget < 0 {
+		return fmt.Errorf("invalid EXPORTER_WEIGHT_BUDGET %d, has to be 0 or positive", c.Collection.WeightBudget)
+	}
+	if a := c.Collection.Adaptive; a.Enabled {
+		if a.MinInterval <= 0 || a.MinInterval > c.Collection.Interval || a.MaxInterval < c.Collection.Interval {
+			return fmt.Errorf("invalid EXPORTER_MIN_POLL_INTERVAL %s or EXPORTER_MAX_POLL_INTERVAL %s, EXPORTER_POLL_INTERVAL %s has to be between them", a.MinInterval, a.MaxInterval, c.Collection.Interval)
+		}
+		if a.WeightLimit <= 0 {
+			return fmt.Errorf("invalid EXPORTER_WEIGHT_LIMIT %d, has to be positive", a.WeightLimit)
+		}
+	}
+	if !prometheus.ValidName(c.Metrics.Namespace) {
+		return fmt.Errorf("invalid EXPORTER_METRIC_NAMESPACE %q", c.Metrics.Namespace)
+	}
+	if len(c.Metrics.Subsystem) > 0 && !prometheus.ValidName(c.Metrics.Subsystem) {
+		return fmt.Errorf("invalid EXPORTER_METRIC_SUBSYSTEM %q", c.Metrics.Subsystem)
+	}
+	for name := range c.Metrics.ConstLabels {
+		if !prometheus.ValidName(name) {
+			return fmt.Errorf("invalid constant label name %q", name)
+		}
+	}
+	for name, expression := range c.Metrics.Derived {
+		if !prometheus.ValidName(name) {
+			return fmt.Errorf("invalid derived metric name %q", name)
+		}
+		if _, err := expr.Parse(expression); err != nil {
+			return fmt.Errorf("invalid derived metric %s: %w", name, err)
+		}
+	}
+	if c.Metrics.MaxAssets < 0 {
+		return fmt.Errorf("invalid EXPORTER_MAX_ASSETS_PER_WALLET %d, has to be 0 or positive", c.Metrics.MaxAssets)
+	}
+	for _, category := range c.Assets.ExcludeCategories {
+		known := false
+		for _, k := range AssetCategories {
+			known = known || k == category
+		}
+		if !known {
+			return fmt.Errorf("invalid EXPORTER_EXCLUDE_ASSET_CATEGORIES %s, expected one of %s", category, strings.Join(AssetCategories, ", "))
+		}
+	}
+	if c.Collection.SLO.Window < 0 {
+		return fmt.Errorf("invalid EXPORTER_SLO_WINDOW %d, has to be 0 or positive", c.Collection.SLO.Window)
+	}
+	if c.Collection.SLO.Target <= 0 || c.Collection.SLO.Target >= 1 {
+		return fmt.Errorf("invalid EXPORTER_SLO_TARGET %g, has to be between 0 and 1", c.Collection.SLO.Target)
+	}
+	for _, window := range c.Collection.Quiet.Windows {
+		if _, err := schedule.Parse(window); err != nil {
+			return fmt.Errorf("invalid EXPORTER_QUIET_HOURS: %w", err)
+		}
+	}
+	switch c.Collection.Quiet.Mode {
+	case QuietPause, QuietReduce:
+	default:
+		return fmt.Errorf("invalid EXPORTER_QUIET_MODE %q, expected %s or %s", c.Collection.Quiet.Mode, QuietPause, QuietReduce)
+	}
+	if c.Collection.Quiet.Factor < 1 {
+		return fmt.Errorf("invalid EXPORTER_QUIET_FACTOR %g, has to be at least 1", c.Collection.Quiet.Factor)
+	}
+	if len(c.Heartbeat.URL) > 0 {
+		if u, err := url.Parse(c.Heartbeat.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid EXPORTER_HEARTBEAT_URL, expected a http or https url")
+		}
+		if c.Heartbeat.Timeout <= 0 {
+			return fmt.Errorf("invalid EXPORTER_HEARTBEAT_TIMEOUT %s, has to be positive", c.Heartbeat.Timeout)
+		}
+	}
+	if len(c.FX.Currencies) > 0 {
+		if u, err := url.Parse(c.FX.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid EXPORTER_FX_URL %q, expected a http or https url", c.FX.URL)
+		}
+		if c.FX.Interval <= 0 || c.FX.Timeout <= 0 {
+			return fmt.Errorf("invalid EXPORTER_FX_INTERVAL %s or EXPORTER_FX_TIMEOUT %s, have to be positive", c.FX.Interval, c.FX.Timeout)
+		}
+	}
+	if c.Maintenance.Enabled {
+		if u, err := url.Parse(c.Maintenance.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid EXPORTER_MAINTENANCE_URL %q, expected a http or https url", c.Maintenance.URL)
+		}
+		if c.Maintenance.Interval <= 0 || c.Maintenance.Timeout <= 0 {
+			return fmt.Errorf("invalid EXPORTER_MAINTENANCE_INTERVAL %s or EXPORTER_MAINTENANCE_TIMEOUT %s, have to be positive", c.Maintenance.Interval, c.Maintenance.Timeout)
+		}
+	}
+	if c.Security.BalanceDrop < 0 || c.Security.BalanceDrop >= 1 {
+		return fmt.Errorf("invalid EXPORTER_SECURITY_BALANCE_DROP %g, has to be 0 or between 0 and 1", c.Security.BalanceDrop)
+	}
+	if c.Security.MaxEvents <= 0 {
+		return fmt.Errorf("invalid EXPORTER_SECURITY_EVENTS %d, has to be positive", c.Security.MaxEvents)
+	}
+	for name, command := range c.Plugins.Commands {
+		if !prometheus.ValidName(name) || len(command) == 0 {
+			return fmt.Errorf("invalid EXPORTER_PLUGINS entry %q, expected name=command with a name like a metric name", name)
+		}
+	}
+	if len(c.Plugins.Commands) > 0 && (c.Plugins.Timeout <= 0 || c.Plugins.MaxSamples <= 0) {
+		return fmt.Errorf("invalid EXPORTER_PLUGIN_TIMEOUT %s or EXPORTER_PLUGIN_MAX_SAMPLES %d, have to be positive", c.Plugins.Timeout, c.Plugins.MaxSamples)
+	}
+	if c.Admin.CaptureRequests < 0 {
+		return fmt.Errorf("invalid EXPORTER_DEBUG_REQUESTS %d, has to be 0 or positive", c.Admin.CaptureRequests)
+	}
+	if c.Admin.CaptureRequests > 0 && c.Admin.CaptureBodyLimit <= 0 {
+		return fmt.Errorf("invalid EXPORTER_DEBUG_BODY_LIMIT %d, has to be positive", c.Admin.CaptureBodyLimit)
+	}
+	if c.Scrape.Concurrency < 0 {
+		return fmt.Errorf("invalid EXPORTER_SCRAPE_CONCURRENCY %d, has to be 0 or positive", c.Scrape.Concurrency)
+	}
+	if c.Scrape.RateLimit < 0 {
+		return fmt.Errorf("invalid EXPORTER_SCRAPE_RATE_LIMIT %d, has to be 0 or positive", c.Scrape.RateLimit)
+	}
+	if c.Scrape.OnDemand && c.Scrape.Deadline <= 0 {
+		return fmt.Errorf("invalid EXPORTER_SCRAPE_DEADLINE %s, has to be positive", c.Scrape.Deadline)
+	}
+	if c.Market.WatchHoldings && len(c.Market.WatchlistQuote) == 0 {
+		return fmt.Errorf("EXPORTER_WATCHLIST_QUOTE can't be empty with EXPORTER_WATCHLIST_FROM_HOLDINGS")
+	}
+	for _, bps := range c.Market.DepthBands {
+		if bps <= 0 {
+			return fmt.Errorf("invalid EXPORTER_DEPTH_BPS %d, has to be positive", bps)
+		}
+	}
+	switch c.Market.DepthLimit {
+	case 5, 10, 20, 50, 100, 500, 1000, 5000:
+	default:
+		return fmt.Errorf("invalid EXPORTER_DEPTH_LIMIT %d, expected one of 5, 10, 20, 50, 100, 500, 1000, 5000", c.Market.DepthLimit)
+	}
+	if c.History.Lookback < 0 || c.History.Lookback > 90*24*time.Hour {
+		return fmt.Errorf("invalid EXPORTER_HISTORY_LOOKBACK_DAYS %d, has to be between 0 and 90", c.History.Lookback/(24*time.Hour))
+	}
+	for _, symbol := range c.PnL.Symbols {
+		if _, ok := USDBase(symbol); !ok {
+			return fmt.Errorf("invalid EXPORTER_PNL_SYMBOLS %s, expected a symbol quoted in one of %s", symbol, strings.Join(usdQuotes, ", "))
+		}
+	}
+	switch c.PnL.Method {
+	case ledger.Average, ledger.FIFO:
+	default:
+		return fmt.Errorf("invalid EXPORTER_PNL_METHOD %q, expected %s or %s", c.PnL.Method, ledger.Average, ledger.FIFO)
+	}
+	if c.History.MaxEntries <= 0 {
+		return fmt.Errorf("invalid EXPORTER_HISTORY_MAX_ENTRIES %d, has to be positive", c.History.MaxEntries)
+	}
+	if c.PnL.MaxLots < 2 {
+		return fmt.Errorf("invalid EXPORTER_PNL_MAX_LOTS %d, has to be at least 2", c.PnL.MaxLots)
+	}
+	if c.PnL.BackfillPages <= 0 {
+		return fmt.Errorf("invalid EXPORTER_PNL_BACKFILL_PAGES %d, has to be positive", c.PnL.BackfillPages)
+	}
+	switch c.Startup.Policy {
+	case StartupFailFast:
+	case StartupServeDegraded:
+		if c.Startup.RetryInterval <= 0 {
+			return fmt.Errorf("invalid EXPORTER_STARTUP_RETRY_INTERVAL %s, has to be positive", c.Startup.RetryInterval)
+		}
+	default:
+		return fmt.Errorf("invalid EXPORTER_STARTUP_POLICY %q, expected %s or %s", c.Startup.Policy, StartupFailFast, StartupServeDegraded)
+	}
+	if len(c.Snapshot.Bucket) > 0 {
+		switch c.Snapshot.Format {
+		case SnapshotJSON, SnapshotCSV:
+		default:
+			return fmt.Errorf("invalid EXPORTER_SNAPSHOT_FORMAT %q, expected %s or %s", c.Snapshot.Format, SnapshotJSON, SnapshotCSV)
+		}
+		if c.Snapshot.Interval <= 0 {
+			return fmt.Errorf("invalid EXPORTER_SNAPSHOT_INTERVAL %s, has to be positive", c.Snapshot.Interval)
+		}
+		if len(c.Snapshot.AccessKey) == 0 || len(c.Snapshot.SecretKey) == 0 {
+			return fmt.Errorf("EXPORTER_SNAPSHOT_S3_BUCKET is set but AWS_ACCESS_KEY_ID or AWS_SECRET_ACCESS_KEY is missing")
+		}
+	}
+	if c.Alerts.MarginLevel <= 1 {
+		return fmt.Errorf("invalid EXPORTER_ALERT_MARGIN_LEVEL %g, has to be above 1", c.Alerts.MarginLevel)
+	}
+	if c.Alerts.Depeg <= 0 || c.Alerts.Depeg >= 1 {
+		return fmt.Errorf("invalid EXPORTER_ALERT_DEPEG %g, has to be between 0 and 1", c.Alerts.Depeg)
+	}
+	if c.Alerts.MaxLeverage <= 0 {
+		return fmt.Errorf("invalid EXPORTER_ALERT_MAX_LEVERAGE %d, has to be positive", c.Alerts.MaxLeverage)
+	}
+	if c.Alerts.Failures <= 0 {
+		return fmt.Errorf("invalid EXPORTER_ALERT_CONSECUTIVE_FAILURES %d, has to be positive", c.Alerts.Failures)
+	}
+	if c.Alerts.StaleIntervals <= 0 {
+		return fmt.Errorf("invalid EXPORTER_ALERT_STALE_INTERVALS %d, has to be positive", c.Alerts.StaleIntervals)
+	}
+	if c.Leader.Enabled && c.Leader.Duration < 3*time.Second {
+		return fmt.Errorf("invalid EXPORTER_LEADER_LEASE_DURATION %s, has to be at least 3s", c.Leader.Duration)
+	}
+	for name, priority := range c.Collection.Priorities {
+		switch priority {
+		case "low", "normal", "high":
+		default:
+			return fmt.Errorf("invalid priority %q for collector %s, expected low, normal or high", priority, name)
+		}
+	}
+	for name, interval := range c.Collection.Intervals {
+		if interval <= 0 {
+			return fmt.Errorf("invalid interval %s for collector %s, has to be positive", interval, name)
+		}
+	}
+	// Without an explicit deadline a cycle may take up to the shortest interval, so cycles never pile up
+	if tick := c.Collection.Tick(); c.Collection.CycleTimeout <= 0 || c.Collection.CycleTimeout > tick {
+		c.Collection.CycleTimeout = tick
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration that is safe to show, secrets are masked
+func (c Config) Redacted() Config {
+	c.Sentry.DSN = mask(c.Sentry.DSN)
+	c.Heartbeat.URL = mask(c.Heartbeat.URL)
+	c.Admin.Token = mask(c.Admin.Token)
+	c.Auth.Metrics = mask(c.Auth.Metrics)
+	c.Auth.API = mask(c.Auth.API)
+	accounts := make(map[string]string, len(c.Auth.Accounts))
+	for account, token := range c.Auth.Accounts {
+		accounts[account] = mask(token)
+	}
+	c.Auth.Accounts = accounts
+	c.Snapshot.SecretKey = mask(c.Snapshot.SecretKey)
+	return c
+}
+
+func mask(secret string) string {
+	if len(secret) == 0 {
+		return ""
+	}
+	return "********"
+}
+
+// Symbols returns every symbol any market data collector watches, sorted
+func (m Market) Symbols() []string {
+	seen := make(map[string]struct{})
+	for _, list := range [][]string{m.Watchlist, m.DepthSymbols, m.KlineSymbols} {
+		for _, symbol := range list {
+			seen[symbol] = struct{}{}
+		}
+	}
+	res := make([]string, 0, len(seen))
+	for symbol := range seen {
+		res = append(res, symbol)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// IntervalOf returns the poll interval of the named collector
+func (c Collection) IntervalOf(name string) time.Duration {
+	if interval, ok := c.Intervals[name]; ok {
+		return interval
+	}
+	return c.Interval
+}
+
+// Tick is the shortest configured interval, the poller wakes up this often to run the collectors that are due
+func (c Collection) Tick() time.Duration {
+	tick := c.Interval
+	for _, interval := range c.Intervals {
+		if interval < tick {
+			tick = interval
+		}
+	}
+	if c.Adaptive.Enabled {
+		// Intervals shrink down to MinInterval, so the poller has to wake up that much more often
+		tick = time.Duration(float64(tick) * float64(c.Adaptive.MinInterval) / float64(c.Interval))
+	}
+	return tick
+}
+
+/*
+parsePairs parses a comma separated list of name=value pairs, e.g. "env=prod,owner=treasury"
+*/
+func parsePairs(s string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=value, got %q", pair)
+		}
+		res[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return res, nil
+}
+
+/*
+parseDefinitions parses a semicolon separated list of name=expression pairs, expressions contain commas and equal signs
+of their own, e.g. a=sum(x{y="1",z="2"})/2;b=x*2
+*/
+func parseDefinitions(s string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, definition := range strings.Split(s, ";") {
+		if definition = strings.TrimSpace(definition); len(definition) == 0 {
+			continue
+		}
+		name, expression, ok := strings.Cut(definition, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=expression, got %q", definition)
+		}
+		res[strings.TrimSpace(name)] = strings.TrimSpace(expression)
+	}
+	return res, nil
+}
+
+/*
+parseListeners parses a comma separated list of addresses, each optionally followed by the route groups served on it,
+e.g. "[::]:9191=metrics+web,127.0.0.1:9192=admin"
+*/
+func parseListeners(s string) []Listener {
+	res := make([]Listener, 0)
+	for _, entry := range strings.Split(s, ",") {
+		address, routes, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		if len(address) == 0 {
+			continue
+		}
+		l := Listener{Address: address}
+		for _, r := range strings.Split(routes, "+") {
+			if r = strings.ToLower(strings.TrimSpace(r)); len(r) > 0 {
+				l.Routes = append(l.Routes, r)
+			}
+		}
+		res = append(res, l)
+	}
+	return res
+}
+
+// Serves reports whether the listener serves the route group
+func (l Listener) Serves(routes string) bool {
+	if len(l.Routes) == 0 {
+		return true
+	}
+	for _, r := range l.Routes {
+		if r == routes {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSchedules parses a semicolon separated list of windows, which contain spaces and commas of their own
+func parseSchedules(s string) []string {
+	res := make([]string, 0)
+	for _, window := range strings.Split(s, ";") {
+		if window = strings.TrimSpace(window); len(window) > 0 {
+			res = append(res, window)
+		}
+	}
+	return res
+}
+
+// parseList parses a comma separated list of symbols or assets, entries are upper cased
+func parseList(s string) []string {
+	res := make([]string, 0)
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); len(entry) > 0 {
+			res = append(res, strings.ToUpper(entry))
+		}
+	}
+	return res
+}
+
+// parseInts parses a comma separated list of integers
+func parseInts(s string) ([]int, error) {
+	res := make([]int, 0)
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); len(entry) == 0 {
+			continue
+		}
+		v, err := strconv.Atoi(entry)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+/*
+parseDurations parses a comma separated list of name=duration pairs, e.g. "spot=60s,prices=15s"
+*/
+func parseDurations(s string) (map[string]time.Duration, error) {
+	pairs, err := parsePairs(s)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]time.Duration, len(pairs))
+	for name, value := range pairs {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		res[name] = d
+	}
+	return res, nil
+}
+
+// Naming returns the exposition naming rules, constant labels are sorted by name
+func (m Metrics) Naming() prometheus.Naming {
+	n := prometheus.Naming{Namespace: m.Namespace, Subsystem: m.Subsystem}
+	names := make([]string, 0, len(m.ConstLabels))
+	for name := range m.ConstLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n.ConstLabels = append(n.ConstLabels, prometheus.L(name, m.ConstLabels[name]))
+	}
+	return n
+}
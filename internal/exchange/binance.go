@@ -0,0 +1,49 @@
+package exchange
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/binance"
+)
+
+// Binance is the provider for binance, backed by the same client the binance collectors use
+type Binance struct {
+	api binance.BinanceAPI
+}
+
+func NewBinance(api binance.BinanceAPI) *Binance {
+	return &Binance{api: api}
+}
+
+func (b *Binance) Name() string {
+	return "binance"
+}
+
+func (b *Binance) Status(ctx context.Context) (Status, error) {
+	status, err := b.api.GetSystemStatus(ctx)
+	if err != nil || status != binance.Online {
+		return Maintenance, err
+	}
+	return Online, nil
+}
+
+func (b *Binance) Balances(ctx context.Context) ([]balance.Balance, error) {
+	if err := b.api.GetSpotWallet(ctx); err != nil {
+		return nil, err
+	}
+	if err := b.api.GetFundingWallet(ctx); err != nil {
+		return nil, err
+	}
+	res := balance.FromBinance(balance.Spot, b.api.GetSpotAssets())
+	return append(res, balance.FromBinance(balance.Funding, b.api.GetFundingAssets())...), nil
+}
+
+func (b *Binance) Price(ctx context.Context, base, quote string) (float64, error) {
+	price, err := b.api.GetAvgPrice(ctx, base+quote)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(price.Price, 64)
+}
@@ -0,0 +1,43 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+)
+
+// Status of an exchange API
+type Status uint
+
+const (
+	Online Status = iota
+	Maintenance
+)
+
+func (s Status) String() string {
+	switch s {
+	case Online:
+		return "Online"
+	case Maintenance:
+		return "Under maintenance"
+	}
+	return "Unknown Status"
+}
+
+type (
+	/*
+		Provider is what the exporter needs from any exchange: whether its API is up, the balances of the account and
+		prices to value them. Binance is the only provider so far, its collectors export a lot more through the binance
+		client directly. Further providers, e.g. a read-only Kraken or Coinbase one, implement this and get their name
+		as the exchange label of their metrics.
+	*/
+	Provider interface {
+		// Name is the exchange label of the metrics of the provider, e.g. binance
+		Name() string
+		Status(ctx context.Context) (Status, error)
+		// Balances returns the balance of every asset held in any wallet of the account
+		Balances(ctx context.Context) ([]balance.Balance, error)
+		// Price returns the price of one base in quote
+		Price(ctx context.Context, base, quote string) (float64, error)
+	}
+)
@@ -0,0 +1,415 @@
+/*
+Package expr evaluates the expressions of derived metrics. An expression is arithmetic over aggregations of the current
+samples of the exported metrics, written like PromQL without the time dimension:
+
+	sum(binance_asset_btc_valuation{group="stablecoins"}) / binance_total_balance_btc
+
+Selectors are a metric name with optional label matchers using = or !=, a bare selector is summed up. The aggregations
+are sum, min, max, avg and count, numbers, parentheses and + - * / work as usual.
+*/
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+)
+
+type (
+	// Expression is a parsed expression
+	Expression struct {
+		source string
+		root   node
+	}
+
+	// node evaluates to a value, false if it has none, like the minimum of no samples or a division by zero
+	node interface {
+		eval(index map[string][]prometheus.Sample) (float64, bool)
+	}
+
+	number float64
+
+	negation struct {
+		operand node
+	}
+
+	binary struct {
+		op          byte
+		left, right node
+	}
+
+	aggregation struct {
+		function string
+		selector selector
+	}
+
+	selector struct {
+		metric   string
+		matchers []matcher
+	}
+
+	matcher struct {
+		label, value string
+		negated      bool
+	}
+)
+
+// aggregations are the functions a selector can be wrapped in
+var aggregations = map[string]bool{"sum": true, "min": true, "max": true, "avg": true, "count": true}
+
+// Parse parses the expression
+func Parse(s string) (*Expression, error) {
+	p := &parser{lexer: lexer{input: s}}
+	p.next()
+	root, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if p.token.kind != eof {
+		return nil, p.errorf("unexpected %s", p.token)
+	}
+	return &Expression{source: s, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression over the families, false if it has no value
+func (e *Expression) Eval(families []prometheus.Family) (float64, bool) {
+	index := make(map[string][]prometheus.Sample, len(families))
+	for _, f := range families {
+		for _, s := range f.Samples {
+			// Histogram buckets and counts are not selectable by the family name
+			if len(s.Suffix) == 0 {
+				index[f.Name] = append(index[f.Name], s)
+			}
+		}
+	}
+	v, ok := e.root.eval(index)
+	if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+func (n number) eval(map[string][]prometheus.Sample) (float64, bool) {
+	return float64(n), true
+}
+
+func (n negation) eval(index map[string][]prometheus.Sample) (float64, bool) {
+	v, ok := n.operand.eval(index)
+	return -v, ok
+}
+
+func (b binary) eval(index map[string][]prometheus.Sample) (float64, bool) {
+	l, ok := b.left.eval(index)
+	if !ok {
+		return 0, false
+	}
+	r, ok := b.right.eval(index)
+	if !ok {
+		return 0, false
+	}
+	switch b.op {
+	case '+':
+		return l + r, true
+	case '-':
+		return l - r, true
+	case '*':
+		return l * r, true
+	default:
+		if r == 0 {
+			return 0, false
+		}
+		return l / r, true
+	}
+}
+
+func (a aggregation) eval(index map[string][]prometheus.Sample) (float64, bool) {
+	values := a.selector.values(index)
+	if a.function == "count" {
+		return float64(len(values)), true
+	}
+	if a.function == "sum" {
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum, true
+	}
+	if len(values) == 0 {
+		return 0, false
+	}
+	res := values[0]
+	for _, v := range values[1:] {
+		switch a.function {
+		case "min":
+			res = math.Min(res, v)
+		case "max":
+			res = math.Max(res, v)
+		default:
+			res += v
+		}
+	}
+	if a.function == "avg" {
+		res /= float64(len(values))
+	}
+	return res, true
+}
+
+// values returns the values of the samples of the metric all matchers match
+func (s selector) values(index map[string][]prometheus.Sample) []float64 {
+	res := make([]float64, 0)
+	for _, sample := range index[s.metric] {
+		matches := true
+		for _, m := range s.matchers {
+			value := ""
+			for _, l := range sample.Labels {
+				if l.Name == m.label {
+					value = l.Value
+				}
+			}
+			matches = matches && (value == m.value) != m.negated
+		}
+		if matches {
+			res = append(res, sample.Value)
+		}
+	}
+	return res
+}
+
+/*
+parser is a recursive descent parser of the grammar
+
+	expression = term {("+" | "-") term}
+	term       = factor {("*" | "/") factor}
+	factor     = number | "-" factor | "(" expression ")" | aggregation "(" selector ")" | selector
+	selector   = name ["{" [matcher {"," matcher}] "}"]
+	matcher    = name ("=" | "!=") string
+*/
+type parser struct {
+	lexer lexer
+	token token
+}
+
+func (p *parser) next() {
+	p.token = p.lexer.next()
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid expression %q at %d: %s", p.lexer.input, p.token.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if p.token.kind != kind || (len(text) > 0 && p.token.text != text) {
+		expected := kind.String()
+		if len(text) > 0 {
+			expected = strconv.Quote(text)
+		}
+		return p.errorf("expected %s, got %s", expected, p.token)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) expression() (node, error) {
+	left, err := p.term()
+	for err == nil && p.token.kind == operator && (p.token.text == "+" || p.token.text == "-") {
+		op := p.token.text[0]
+		p.next()
+		var right node
+		if right, err = p.term(); err == nil {
+			left = binary{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.factor()
+	for err == nil && p.token.kind == operator && (p.token.text == "*" || p.token.text == "/") {
+		op := p.token.text[0]
+		p.next()
+		var right node
+		if right, err = p.factor(); err == nil {
+			left = binary{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) factor() (node, error) {
+	switch t := p.token; {
+	case t.kind == numberLiteral:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %s", t.text)
+		}
+		p.next()
+		return number(v), nil
+	case t.kind == operator && t.text == "-":
+		p.next()
+		operand, err := p.factor()
+		return negation{operand: operand}, err
+	case t.kind == punctuation && t.text == "(":
+		p.next()
+		n, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(punctuation, ")")
+	case t.kind == identifier:
+		p.next()
+		if !aggregations[t.text] || p.token.kind != punctuation || p.token.text != "(" {
+			s, err := p.selector(t.text)
+			return aggregation{function: "sum", selector: s}, err
+		}
+		p.next()
+		if p.token.kind != identifier {
+			return nil, p.errorf("expected a metric name, got %s", p.token)
+		}
+		name := p.token.text
+		p.next()
+		s, err := p.selector(name)
+		if err != nil {
+			return nil, err
+		}
+		return aggregation{function: t.text, selector: s}, p.expect(punctuation, ")")
+	default:
+		return nil, p.errorf("unexpected %s", t)
+	}
+}
+
+// selector parses the label matchers following the metric name
+func (p *parser) selector(metric string) (selector, error) {
+	s := selector{metric: metric}
+	if !prometheus.ValidName(metric) {
+		return s, p.errorf("invalid metric name %s", metric)
+	}
+	if p.token.kind != punctuation || p.token.text != "{" {
+		return s, nil
+	}
+	p.next()
+	for p.token.kind != punctuation || p.token.text != "}" {
+		if len(s.matchers) > 0 {
+			if err := p.expect(punctuation, ","); err != nil {
+				return s, err
+			}
+		}
+		label := p.token
+		if err := p.expect(identifier, ""); err != nil {
+			return s, err
+		}
+		op := p.token
+		if op.kind != operator || (op.text != "=" && op.text != "!=") {
+			return s, p.errorf("expected = or !=, got %s", op)
+		}
+		p.next()
+		value := p.token
+		if err := p.expect(stringLiteral, ""); err != nil {
+			return s, err
+		}
+		s.matchers = append(s.matchers, matcher{label: label.text, value: value.text, negated: op.text == "!="})
+	}
+	p.next()
+	return s, nil
+}
+
+type (
+	tokenKind int
+
+	token struct {
+		kind tokenKind
+		text string // Unquoted for strings
+		pos  int
+	}
+
+	lexer struct {
+		input string
+		pos   int
+	}
+)
+
+const (
+	eof tokenKind = iota
+	invalid
+	numberLiteral
+	stringLiteral
+	identifier
+	operator
+	punctuation
+)
+
+func (k tokenKind) String() string {
+	return [...]string{"end of expression", "invalid token", "number", "string", "name", "operator", "punctuation"}[k]
+}
+
+func (t token) String() string {
+	if t.kind == eof {
+		return t.kind.String()
+	}
+	return strconv.Quote(t.text)
+}
+
+func (l *lexer) next() token {
+	for l.pos < len(l.input) && strings.ContainsRune(" \t\n", rune(l.input[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: eof, pos: start}
+	}
+	c := l.input[l.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for l.pos < len(l.input) && (isDigit(l.input[l.pos]) || l.input[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: numberLiteral, text: l.input[start:l.pos], pos: start}
+	case isNameStart(c):
+		for l.pos < len(l.input) && (isNameStart(l.input[l.pos]) || isDigit(l.input[l.pos])) {
+			l.pos++
+		}
+		return token{kind: identifier, text: l.input[start:l.pos], pos: start}
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.input) && l.input[l.pos] != '"' {
+			if l.input[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.input) {
+			return token{kind: invalid, text: l.input[start:], pos: start}
+		}
+		l.pos++
+		text, err := strconv.Unquote(l.input[start:l.pos])
+		if err != nil {
+			return token{kind: invalid, text: l.input[start:l.pos], pos: start}
+		}
+		return token{kind: stringLiteral, text: text, pos: start}
+	case c == '!' && strings.HasPrefix(l.input[l.pos:], "!="):
+		l.pos += 2
+		return token{kind: operator, text: "!=", pos: start}
+	case strings.IndexByte("+-*/=", c) >= 0:
+		l.pos++
+		return token{kind: operator, text: string(c), pos: start}
+	case strings.IndexByte("(){},", c) >= 0:
+		l.pos++
+		return token{kind: punctuation, text: string(c), pos: start}
+	}
+	l.pos++
+	return token{kind: invalid, text: string(c), pos: start}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
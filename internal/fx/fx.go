@@ -0,0 +1,121 @@
+/*
+Package fx fetches foreign exchange rates to report USD valuations in other currencies. The default source are the
+daily reference rates of the European Central Bank, which need no key. Any other URL answering a JSON object of rates
+works as well:
+
+	{"base": "USD", "rates": {"EUR": 0.92, "GBP": 0.79}}
+
+A missing base means USD. Rates of either source are converted to units of the currency per US dollar.
+*/
+package fx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// USD is the currency rates are quoted against
+const USD = "USD"
+
+type (
+	// Rates are units of the currency per US dollar by currency code
+	Rates map[string]float64
+
+	// Source fetches the rates from URL
+	Source struct {
+		httpclient *http.Client
+		url        string
+		userAgent  string
+	}
+
+	// ecbEnvelope is the eurofxref-daily.xml document, rates are per euro
+	ecbEnvelope struct {
+		Cube struct {
+			Cube struct {
+				Time  string `xml:"time,attr"`
+				Rates []struct {
+					Currency string  `xml:"currency,attr"`
+					Rate     float64 `xml:"rate,attr"`
+				} `xml:"Cube"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	}
+
+	jsonRates struct {
+		Base  string             `json:"base"`
+		Rates map[string]float64 `json:"rates"`
+	}
+)
+
+func New(url, userAgent string, timeout time.Duration) *Source {
+	return &Source{httpclient: &http.Client{Timeout: timeout}, url: url, userAgent: userAgent}
+}
+
+// Fetch returns the current rates, an ECB document is recognized by being XML
+func (s *Source) Fetch(ctx context.Context) (Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.userAgent)
+	res, err := s.httpclient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("fx url answered %s", res.Status)
+	}
+
+	base, rates := "", make(map[string]float64)
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("<")) {
+		doc := ecbEnvelope{}
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("invalid ECB rates: %w", err)
+		}
+		base = "EUR"
+		for _, r := range doc.Cube.Cube.Rates {
+			rates[r.Currency] = r.Rate
+		}
+	} else {
+		doc := jsonRates{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("invalid fx rates: %w", err)
+		}
+		base, rates = doc.Base, doc.Rates
+	}
+	return normalize(strings.ToUpper(base), rates)
+}
+
+// normalize converts rates per unit of base into rates per US dollar
+func normalize(base string, rates map[string]float64) (Rates, error) {
+	if len(base) == 0 {
+		base = USD
+	}
+	res := make(Rates, len(rates)+1)
+	for currency, rate := range rates {
+		if rate > 0 {
+			res[strings.ToUpper(currency)] = rate
+		}
+	}
+	res[base] = 1
+	usd, ok := res[USD]
+	if !ok {
+		return nil, fmt.Errorf("fx rates per %s have no %s rate to convert them", base, USD)
+	}
+	for currency, rate := range res {
+		res[currency] = rate / usd
+	}
+	return res, nil
+}
@@ -0,0 +1,234 @@
+package grafana
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/version"
+)
+
+const (
+	panelWidth  = 12 // Two panels side by side on the 24 column grid
+	panelHeight = 8
+)
+
+type (
+	// Options describe the exporter the dashboard is generated for
+	Options struct {
+		Accounts   []string
+		Collectors []string // Enabled collectors, only their panels are generated
+		Wallets    []string // Enabled wallet collectors
+		Naming     prometheus.Naming
+		StateLabel bool // Balances are exported as binance_asset_balance{wallet,asset,state}
+	}
+
+	// Dashboard is the subset of the grafana dashboard model the generated dashboards use
+	Dashboard struct {
+		UID           string     `json:"uid"`
+		Title         string     `json:"title"`
+		Tags          []string   `json:"tags"`
+		SchemaVersion int        `json:"schemaVersion"`
+		Refresh       string     `json:"refresh"`
+		Time          TimeRange  `json:"time"`
+		Templating    Templating `json:"templating"`
+		Panels        []Panel    `json:"panels"`
+	}
+	TimeRange struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	Templating struct {
+		List []Variable `json:"list"`
+	}
+	Variable struct {
+		Name  string `json:"name"`
+		Label string `json:"label"`
+		Type  string `json:"type"`
+		Query string `json:"query"`
+	}
+	Panel struct {
+		ID          int          `json:"id"`
+		Type        string       `json:"type"`
+		Title       string       `json:"title"`
+		GridPos     GridPos      `json:"gridPos"`
+		Datasource  *Datasource  `json:"datasource,omitempty"`
+		Targets     []Target     `json:"targets,omitempty"`
+		FieldConfig *FieldConfig `json:"fieldConfig,omitempty"`
+		Collapsed   bool         `json:"collapsed,omitempty"`
+		Panels      []Panel      `json:"panels,omitempty"` // Only for rows, always empty since rows are not collapsed
+	}
+	GridPos struct {
+		H int `json:"h"`
+		W int `json:"w"`
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	Datasource struct {
+		Type string `json:"type"`
+		UID  string `json:"uid"`
+	}
+	Target struct {
+		Expr         string `json:"expr"`
+		LegendFormat string `json:"legendFormat,omitempty"`
+		RefID        string `json:"refId"`
+	}
+	FieldConfig struct {
+		Defaults FieldDefaults `json:"defaults"`
+	}
+	FieldDefaults struct {
+		Unit string `json:"unit,omitempty"`
+	}
+
+	// section is a row of panels shown once its collector is enabled
+	section struct {
+		collector string // Empty for sections shown regardless of the collectors
+		title     string
+		panels    func(o Options) []Panel
+	}
+)
+
+/*
+sections of the generated dashboard in the order they are laid out. Metric names are the built-in ones, the queries
+go through the configured naming before they end up in the dashboard.
+*/
+var sections = []section{
+	{collector: "totals", title: "Totals", panels: func(Options) []Panel {
+		return []Panel{
+			stat("Total balance", "btc", "binance_total_balance_btc"),
+			stat("Total balance", "currencyUSD", "binance_total_balance_usd"),
+			timeseries("Total balance in USD", "currencyUSD", target("binance_total_balance_usd", "total")),
+		}
+	}},
+	{title: "Wallets", panels: walletPanels},
+	{collector: "margin", title: "Margin", panels: func(Options) []Panel {
+		return []Panel{
+			timeseries("Cross margin level", "none", target(`binance_margin_level{account="cross"}`, "cross")),
+			timeseries("Margin net asset", "btc", target("binance_margin_net_asset_btc", "{{account}} {{symbol}}")),
+		}
+	}},
+	{collector: "isolated_margin", title: "Isolated margin", panels: func(Options) []Panel {
+		return []Panel{
+			timeseries("Isolated margin level", "none", target(`binance_margin_level{account="isolated"}`, "{{symbol}}")),
+		}
+	}},
+	{collector: "futures", title: "Futures", panels: func(Options) []Panel {
+		return []Panel{
+			timeseries("Unrealized profit", "currencyUSD", target("sum by (symbol, side) (binance_futures_unrealized_profit)", "{{symbol}} {{side}}")),
+			timeseries("Account margin ratio", "percentunit", target("binance_futures_account_margin_ratio", "account")),
+		}
+	}},
+	{title: "Liquidation", panels: func(o Options) []Panel {
+		if !o.enabled("margin") && !o.enabled("isolated_margin") && !o.enabled("futures") {
+			return nil
+		}
+		return []Panel{
+			timeseries("Distance to liquidation", "percentunit", target("min by (kind, symbol, side) (binance_liquidation_distance_ratio)", "{{kind}} {{symbol}} {{side}}")),
+		}
+	}},
+	{collector: "earn", title: "Earn", panels: func(Options) []Panel {
+		return []Panel{
+			timeseries("Simple Earn positions", "none", target("sum by (asset, type) (binance_earn_position_amount)", "{{asset}} {{type}}")),
+			timeseries("Simple Earn APR", "percentunit", target("binance_earn_position_apr", "{{asset}} {{product}}")),
+		}
+	}},
+	{collector: "pnl", title: "PnL", panels: func(Options) []Panel {
+		return []Panel{
+			timeseries("Unrealized PnL", "currencyUSD", target("binance_asset_unrealized_pnl_usd", "{{asset}}")),
+			timeseries("Realized PnL per day", "currencyUSD", target(
+				"sum by (symbol) (increase(binance_realized_profit_usd_total[1d])) - sum by (symbol) (increase(binance_realized_loss_usd_total[1d]))", "{{symbol}}")),
+		}
+	}},
+	{collector: "ticker", title: "Market", panels: func(Options) []Panel {
+		return []Panel{
+			timeseries("Average price", "none", target("binance_symbol_avg_price", "{{symbol}}")),
+			timeseries("Spread", "none", target("binance_symbol_spread_bps", "{{symbol}} bps")),
+		}
+	}},
+	{title: "API health", panels: func(Options) []Panel {
+		return []Panel{
+			timeseries("Binance API errors", "reqps", target("sum by (code) (rate(binance_api_errors_total[5m]))", "{{code}}")),
+			timeseries("Collector errors", "reqps", target("sum by (collector) (rate(binance_collector_errors_total[5m]))", "{{collector}}")),
+			timeseries("Collector duration", "s", target("binance_collector_duration_seconds", "{{collector}}")),
+			timeseries("Time since last successful collection", "s", target("time() - binance_collector_last_success_timestamp_seconds", "{{collector}}")),
+		}
+	}},
+}
+
+// Build generates a dashboard with a row for every enabled collector that has panels
+func Build(o Options) Dashboard {
+	d := Dashboard{
+		UID:           version.Name,
+		Title:         "Binance " + strings.Join(o.Accounts, ", "),
+		Tags:          []string{"binance", version.Name},
+		SchemaVersion: 38,
+		Refresh:       "1m",
+		Time:          TimeRange{From: "now-24h", To: "now"},
+		Templating:    Templating{List: []Variable{{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"}}},
+		Panels:        make([]Panel, 0),
+	}
+	y := 0
+	for _, s := range sections {
+		if len(s.collector) > 0 && !o.enabled(s.collector) {
+			continue
+		}
+		panels := s.panels(o)
+		if len(panels) == 0 {
+			continue
+		}
+		d.Panels = append(d.Panels, Panel{Type: "row", Title: s.title, GridPos: GridPos{H: 1, W: 24, Y: y}})
+		y++
+		for i, p := range panels {
+			p.GridPos = GridPos{H: panelHeight, W: panelWidth, X: (i % 2) * panelWidth, Y: y + (i/2)*panelHeight}
+			p.Datasource = &Datasource{Type: "prometheus", UID: "${datasource}"}
+			for j := range p.Targets {
+				p.Targets[j].Expr = o.Naming.Query(p.Targets[j].Expr)
+			}
+			d.Panels = append(d.Panels, p)
+		}
+		y += (len(panels) + 1) / 2 * panelHeight
+	}
+	for i := range d.Panels {
+		d.Panels[i].ID = i + 1
+	}
+	return d
+}
+
+// walletPanels shows the BTC value of every enabled wallet by asset
+func walletPanels(o Options) []Panel {
+	panels := make([]Panel, 0, len(o.Wallets))
+	for _, wallet := range o.Wallets {
+		expr := fmt.Sprintf("binance_%s_asset_btc_valuation", wallet)
+		if o.StateLabel {
+			expr = fmt.Sprintf(`binance_asset_btc_valuation{wallet=%q}`, wallet)
+		}
+		panels = append(panels, timeseries(strings.ToUpper(wallet[:1])+wallet[1:]+" wallet by asset", "btc", target("sum by (asset) ("+expr+")", "{{asset}}")))
+	}
+	return panels
+}
+
+func (o Options) enabled(collector string) bool {
+	for _, c := range o.Collectors {
+		if c == collector {
+			return true
+		}
+	}
+	return false
+}
+
+func target(expr, legend string) Target {
+	return Target{Expr: expr, LegendFormat: legend}
+}
+
+func timeseries(title, unit string, targets ...Target) Panel {
+	for i := range targets {
+		targets[i].RefID = string(rune('A' + i))
+	}
+	return Panel{Type: "timeseries", Title: title, Targets: targets, FieldConfig: &FieldConfig{Defaults: FieldDefaults{Unit: unit}}}
+}
+
+func stat(title, unit, expr string) Panel {
+	p := timeseries(title, unit, target(expr, ""))
+	p.Type = "stat"
+	return p
+}
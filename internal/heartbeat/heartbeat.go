@@ -0,0 +1,86 @@
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	pings       = prometheus.NewCounterVec("binance_heartbeat_pings_total", "Heartbeat pings sent to EXPORTER_HEARTBEAT_URL by result", "result")
+	lastSuccess = prometheus.NewGaugeVec("binance_heartbeat_last_success_timestamp_seconds", "Unix time of the last heartbeat ping that was answered")
+)
+
+func init() {
+	prometheus.Default.MustRegister(pings, lastSuccess)
+}
+
+/*
+Pusher pings a dead man's switch after successful poll cycles. The switch alerts once the pings stop, which also covers
+a hung exporter and a broken alerting pipeline, neither of which prometheus can alert on itself. Pings never hold up
+the poller: a ping still in flight or sent less than MinInterval ago makes the next one a no-op.
+*/
+type Pusher struct {
+	httpclient *http.Client
+	cfg        config.Heartbeat
+	userAgent  string
+	logger     *zap.Logger
+	lock       sync.Mutex
+	busy       bool
+	last       time.Time // Start of the last ping
+}
+
+func New(cfg config.Heartbeat, userAgent string, l *zap.Logger) *Pusher {
+	return &Pusher{httpclient: &http.Client{Timeout: cfg.Timeout}, cfg: cfg, userAgent: userAgent, logger: l}
+}
+
+// Ping sends a heartbeat in the background unless one is in flight or the last one is younger than MinInterval
+func (p *Pusher) Ping() {
+	p.lock.Lock()
+	now := time.Now()
+	if p.busy || now.Sub(p.last) < p.cfg.MinInterval {
+		p.lock.Unlock()
+		return
+	}
+	p.busy, p.last = true, now
+	p.lock.Unlock()
+
+	go func() {
+		err := p.ping(context.Background())
+		p.lock.Lock()
+		p.busy = false
+		p.lock.Unlock()
+		if err != nil {
+			pings.Inc("failure")
+			p.logger.Warn("Failed to send the heartbeat", zap.Error(err))
+			return
+		}
+		pings.Inc("success")
+		lastSuccess.Set(float64(time.Now().Unix()))
+	}()
+}
+
+func (p *Pusher) ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", p.userAgent)
+	res, err := p.httpclient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1024))
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("heartbeat url answered %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/clock"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+/*
+Elector competes for a kubernetes Lease so that only one of several replicas polls binance. The standby keeps serving
+the metrics of its last poll, flagged as stale.
+
+Expiry is judged by the local time the lease record was last seen changing rather than its renewTime, so clock skew
+between the replicas doesn't matter.
+*/
+type Elector struct {
+	client   *leaseClient
+	identity string
+	duration time.Duration
+	logger   *zap.Logger
+	clock    clock.Clock
+	leader   atomic.Bool
+
+	lock       sync.Mutex
+	observed   leaseSpec // Last record seen
+	observedAt time.Time // Local time the record last changed
+	renewedAt  time.Time // Local time this replica last renewed its lease
+}
+
+// New creates an elector for the configured Lease, the identity defaults to $POD_NAME and then to the hostname
+func New(cfg config.Leader, userAgent string, l *zap.Logger) (*Elector, error) {
+	client, err := newLeaseClient(cfg.Lease, cfg.Namespace, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	identity := cfg.Identity
+	if len(identity) == 0 {
+		identity = os.Getenv("POD_NAME")
+	}
+	if len(identity) == 0 {
+		if identity, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("failed to determine the identity: %w", err)
+		}
+	}
+	return &Elector{
+		client:   client,
+		identity: identity,
+		duration: cfg.Duration,
+		logger:   l.With(zap.String("lease", cfg.Lease), zap.String("identity", identity)),
+		clock:    clock.Real,
+	}, nil
+}
+
+// UseClock replaces the wall clock the renewals and the expiry of the lease are timed with
+func (e *Elector) UseClock(c clock.Clock) {
+	e.clock = c
+}
+
+// IsLeader reports whether this replica currently holds the lease
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run tries to acquire or renew the lease every third of its duration until ctx is done
+func (e *Elector) Run(ctx context.Context) {
+	ticker := e.clock.NewTicker(e.duration / 3)
+	defer ticker.Stop()
+	for {
+		e.step(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+func (e *Elector) step(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.duration/3)
+	defer cancel()
+	acquired, err := e.tryAcquireOrRenew(ctx)
+	if err != nil {
+		e.logger.Warn("Failed to acquire or renew the lease", zap.Error(err))
+	}
+
+	e.lock.Lock()
+	// A leader that can't renew in time has to assume another replica took over
+	leader := acquired || (err != nil && e.leader.Load() && e.clock.Since(e.renewedAt) < e.duration)
+	e.lock.Unlock()
+
+	if was := e.leader.Swap(leader); was != leader {
+		if leader {
+			e.logger.Info("Became the leader, polling binance")
+		} else {
+			e.logger.Warn("Lost the lease, standing by and serving stale metrics")
+		}
+	}
+}
+
+func (e *Elector) tryAcquireOrRenew(ctx context.Context) (bool, error) {
+	now := e.clock.Now()
+	spec := leaseSpec{
+		HolderIdentity:       e.identity,
+		LeaseDurationSeconds: int(e.duration / time.Second),
+		AcquireTime:          formatMicroTime(now),
+		RenewTime:            formatMicroTime(now),
+	}
+
+	current, err := e.client.get(ctx)
+	if errors.Is(err, errNotFound) {
+		if _, err := e.client.create(ctx, spec); err != nil {
+			return false, err
+		}
+		e.renewed(spec, now)
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	e.lock.Lock()
+	if current.Spec != e.observed {
+		e.observed = current.Spec
+		e.observedAt = now
+	}
+	expired := e.observedAt.Add(e.duration).Before(now)
+	e.lock.Unlock()
+
+	holder := current.Spec.HolderIdentity
+	if len(holder) > 0 && holder != e.identity && !expired {
+		return false, nil
+	}
+
+	spec.LeaseTransitions = current.Spec.LeaseTransitions
+	if holder == e.identity {
+		spec.AcquireTime = current.Spec.AcquireTime
+	} else {
+		spec.LeaseTransitions++
+	}
+	current.Spec = spec
+	if _, err := e.client.update(ctx, current); err != nil {
+		if errors.Is(err, errConflict) {
+			// Another replica won the race
+			return false, nil
+		}
+		return false, err
+	}
+	e.renewed(spec, now)
+	return true, nil
+}
+
+func (e *Elector) renewed(spec leaseSpec, now time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.observed = spec
+	e.observedAt = now
+	e.renewedAt = now
+}
+
+// Gather exports whether the metrics of this replica are stale because it is a standby
+func (e *Elector) Gather() []prometheus.Family {
+	f := prometheus.NewGauge("binance_metrics_stale", "Replica is a leader election standby that doesn't poll binance, its metrics are stale")
+	stale := 1.0
+	if e.IsLeader() {
+		stale = 0
+	}
+	f.Add(stale, prometheus.L("identity", e.identity))
+	return []prometheus.Family{*f}
+}
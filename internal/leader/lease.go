@@ -0,0 +1,153 @@
+package leader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	microTimeLayout   = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+var (
+	errNotFound = errors.New("lease not found")
+	errConflict = errors.New("lease was updated concurrently")
+)
+
+type (
+	// lease is the subset of a coordination.k8s.io/v1 Lease the elector reads and writes
+	lease struct {
+		APIVersion string        `json:"apiVersion"`
+		Kind       string        `json:"kind"`
+		Metadata   leaseMetadata `json:"metadata"`
+		Spec       leaseSpec     `json:"spec"`
+	}
+	leaseMetadata struct {
+		Name            string `json:"name"`
+		Namespace       string `json:"namespace"`
+		ResourceVersion string `json:"resourceVersion,omitempty"`
+	}
+	leaseSpec struct {
+		HolderIdentity       string `json:"holderIdentity,omitempty"`
+		LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+		AcquireTime          string `json:"acquireTime,omitempty"`
+		RenewTime            string `json:"renewTime,omitempty"`
+		LeaseTransitions     int    `json:"leaseTransitions"`
+	}
+
+	// leaseClient talks to the kubernetes API with the service account of the pod
+	leaseClient struct {
+		httpclient *http.Client
+		url        string // Lease collection of the namespace
+		name       string
+		namespace  string
+		userAgent  string
+	}
+)
+
+// newLeaseClient creates a client from the in-cluster service account, namespace defaults to the one of the pod
+func newLeaseClient(name, namespace, userAgent string) (*leaseClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if len(host) == 0 || len(port) == 0 {
+		return nil, errors.New("not running in kubernetes, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+	if len(namespace) == 0 {
+		ns, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the pod namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificates in the cluster CA")
+	}
+	return &leaseClient{
+		httpclient: &http.Client{
+			Timeout:   5 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+		url:       fmt.Sprintf("https://%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", net.JoinHostPort(host, port), namespace),
+		name:      name,
+		namespace: namespace,
+		userAgent: userAgent,
+	}, nil
+}
+
+func (c *leaseClient) get(ctx context.Context) (*lease, error) {
+	l := &lease{}
+	return l, c.do(ctx, http.MethodGet, c.url+"/"+c.name, nil, l)
+}
+
+func (c *leaseClient) create(ctx context.Context, spec leaseSpec) (*lease, error) {
+	l := &lease{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+		Metadata:   leaseMetadata{Name: c.name, Namespace: c.namespace},
+		Spec:       spec,
+	}
+	return l, c.do(ctx, http.MethodPost, c.url, l, l)
+}
+
+// update replaces the lease, failing with errConflict when it changed since it was read
+func (c *leaseClient) update(ctx context.Context, l *lease) (*lease, error) {
+	res := &lease{}
+	return res, c.do(ctx, http.MethodPut, c.url+"/"+c.name, l, res)
+}
+
+func (c *leaseClient) do(ctx context.Context, method, url string, body, target interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	// Projected service account tokens are rotated, so the token is read for every request
+	token, err := os.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return fmt.Errorf("failed to read the service account token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	res, err := c.httpclient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case res.StatusCode == http.StatusConflict:
+		return errConflict
+	case res.StatusCode >= 300:
+		return fmt.Errorf("%s %s returned %s", method, url, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(target)
+}
+
+func formatMicroTime(t time.Time) string {
+	return t.UTC().Format(microTimeLayout)
+}
@@ -0,0 +1,159 @@
+package ledger
+
+// Methods of matching sales against the cost of earlier buys
+const (
+	Average = "average" // Sales take out their share of the average cost
+	FIFO    = "fifo"    // Sales consume the oldest buys first
+)
+
+/*
+Fill is a trade of the account reduced to what the cost basis depends on. Price and Quantity are in the quote and base
+asset of the symbol. The commission only counts if it was paid in one of those two assets, commissions paid in BNB
+don't change the cost basis.
+*/
+type Fill struct {
+	ID              int64
+	Buy             bool
+	Price           float64
+	Quantity        float64
+	QuoteQuantity   float64
+	Commission      float64
+	CommissionBase  bool // Commission paid in the base asset
+	CommissionQuote bool // Commission paid in the quote asset
+}
+
+type (
+	/*
+		Position is the holding of the base asset built up by the trades of one symbol: buys add to the cost, sales take
+		their cost out according to Method and realize the difference to their proceeds. Deposits, withdrawals and
+		transfers don't show up in the trade history, so the position only covers what was bought on binance.
+	*/
+	Position struct {
+		Method         string  `json:"method"`
+		Quantity       float64 `json:"quantity"`
+		Cost           float64 `json:"cost"`            // In the quote asset
+		Lots           []Lot   `json:"lots,omitempty"`  // Buys not sold yet, oldest first, only kept for FIFO
+		RealizedProfit float64 `json:"realized_profit"` // Sum of the sales that made a profit, in the quote asset
+		RealizedLoss   float64 `json:"realized_loss"`   // Sum of the sales that made a loss, positive
+		LastTradeID    int64   `json:"last_trade_id"`   // Trades up to this id are included
+	}
+	Lot struct {
+		Quantity float64 `json:"quantity"`
+		Cost     float64 `json:"cost"`
+	}
+)
+
+// NewPosition creates an empty position matching sales with method
+func NewPosition(method string) *Position {
+	return &Position{Method: method}
+}
+
+// Apply adds the fill to the position, fills at or below LastTradeID were applied already and are skipped
+func (p *Position) Apply(f Fill) {
+	if f.ID <= p.LastTradeID {
+		return
+	}
+	p.LastTradeID = f.ID
+
+	quantity := f.Quantity
+	if f.Buy {
+		cost := f.QuoteQuantity
+		if f.CommissionBase {
+			quantity -= f.Commission
+		}
+		if f.CommissionQuote {
+			cost += f.Commission
+		}
+		p.Quantity += quantity
+		p.Cost += cost
+		if p.Method == FIFO {
+			p.Lots = append(p.Lots, Lot{Quantity: quantity, Cost: cost})
+		}
+		return
+	}
+
+	proceeds := f.QuoteQuantity
+	if f.CommissionBase {
+		// The commission was taken on top of the sold quantity
+		quantity += f.Commission
+	}
+	if f.CommissionQuote {
+		proceeds -= f.Commission
+	}
+	if p.Quantity <= 0 || quantity <= 0 {
+		// Selling what was never bought on binance, there is no cost to take out
+		return
+	}
+	if quantity > p.Quantity {
+		// Only the part that was bought on binance realizes anything
+		proceeds *= p.Quantity / quantity
+		quantity = p.Quantity
+	}
+
+	cost := 0.0
+	if p.Method == FIFO {
+		cost = p.consumeLots(quantity)
+	} else {
+		cost = p.AverageCost() * quantity
+	}
+	p.Cost -= cost
+	p.Quantity -= quantity
+	if p.Quantity <= 0 {
+		p.Quantity, p.Cost, p.Lots = 0, 0, nil
+	}
+	if gain := proceeds - cost; gain >= 0 {
+		p.RealizedProfit += gain
+	} else {
+		p.RealizedLoss -= gain
+	}
+}
+
+// consumeLots takes quantity out of the oldest lots and returns their cost
+func (p *Position) consumeLots(quantity float64) float64 {
+	cost := 0.0
+	for quantity > 0 && len(p.Lots) > 0 {
+		lot := &p.Lots[0]
+		if lot.Quantity > quantity {
+			part := lot.Cost * quantity / lot.Quantity
+			lot.Quantity -= quantity
+			lot.Cost -= part
+			return cost + part
+		}
+		cost += lot.Cost
+		quantity -= lot.Quantity
+		p.Lots = p.Lots[1:]
+	}
+	return cost
+}
+
+/*
+CapLots merges the oldest lots into one until at most limit are left and returns how many lots were merged away. The
+quantity and cost of the position stay the same, only sales consuming the merged lot get their average cost instead of
+the cost of the single buys.
+*/
+func (p *Position) CapLots(limit int) int {
+	if len(p.Lots) <= limit {
+		return 0
+	}
+	n := len(p.Lots) - limit + 1
+	merged := Lot{}
+	for _, lot := range p.Lots[:n] {
+		merged.Quantity += lot.Quantity
+		merged.Cost += lot.Cost
+	}
+	p.Lots = append([]Lot{merged}, p.Lots[n:]...)
+	return n - 1
+}
+
+// AverageCost returns the cost of one unit of the base asset, 0 while nothing is held
+func (p *Position) AverageCost() float64 {
+	if p.Quantity <= 0 {
+		return 0
+	}
+	return p.Cost / p.Quantity
+}
+
+// UnrealizedPnL returns what the position would gain over its cost if it was sold at price
+func (p *Position) UnrealizedPnL(price float64) float64 {
+	return p.Quantity*price - p.Cost
+}
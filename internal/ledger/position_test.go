@@ -0,0 +1,111 @@
+package ledger
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPositionAverageCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		fills    []Fill
+		quantity float64
+		average  float64
+		profit   float64
+		loss     float64
+	}{
+		{
+			name:   "buys",
+			method: Average,
+			fills: []Fill{
+				{ID: 1, Buy: true, Price: 100, Quantity: 1, QuoteQuantity: 100},
+				{ID: 2, Buy: true, Price: 200, Quantity: 3, QuoteQuantity: 600},
+			},
+			quantity: 4,
+			average:  175,
+		},
+		{
+			name:   "partial sell keeps the average",
+			method: Average,
+			fills: []Fill{
+				{ID: 1, Buy: true, Price: 100, Quantity: 2, QuoteQuantity: 200},
+				{ID: 2, Buy: true, Price: 200, Quantity: 2, QuoteQuantity: 400},
+				{ID: 3, Price: 250, Quantity: 1, QuoteQuantity: 250},
+			},
+			quantity: 3,
+			average:  150,
+			profit:   100,
+		},
+		{
+			name:   "partial sell consumes the oldest lot first",
+			method: FIFO,
+			fills: []Fill{
+				{ID: 1, Buy: true, Price: 100, Quantity: 2, QuoteQuantity: 200},
+				{ID: 2, Buy: true, Price: 200, Quantity: 2, QuoteQuantity: 400},
+				{ID: 3, Price: 50, Quantity: 3, QuoteQuantity: 150},
+			},
+			quantity: 1,
+			average:  200,
+			loss:     250,
+		},
+		{
+			name:   "base asset commission lowers the bought quantity",
+			method: Average,
+			fills: []Fill{
+				{ID: 1, Buy: true, Price: 100, Quantity: 2, QuoteQuantity: 200, Commission: 0.4, CommissionBase: true},
+			},
+			quantity: 1.6,
+			average:  125,
+		},
+		{
+			name:   "base asset commission on a sale is sold on top",
+			method: Average,
+			fills: []Fill{
+				{ID: 1, Buy: true, Price: 100, Quantity: 4, QuoteQuantity: 400},
+				{ID: 2, Price: 150, Quantity: 1, QuoteQuantity: 150, Commission: 1, CommissionBase: true},
+			},
+			quantity: 2,
+			average:  100,
+			loss:     50,
+		},
+		{
+			name:   "replayed fills are skipped",
+			method: Average,
+			fills: []Fill{
+				{ID: 1, Buy: true, Price: 100, Quantity: 1, QuoteQuantity: 100},
+				{ID: 1, Buy: true, Price: 100, Quantity: 1, QuoteQuantity: 100},
+			},
+			quantity: 1,
+			average:  100,
+		},
+		{
+			name:   "selling everything clears the position",
+			method: Average,
+			fills: []Fill{
+				{ID: 1, Buy: true, Price: 100, Quantity: 1, QuoteQuantity: 100},
+				{ID: 2, Price: 120, Quantity: 1, QuoteQuantity: 120},
+			},
+			profit: 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPosition(tt.method)
+			for _, f := range tt.fills {
+				p.Apply(f)
+			}
+			if !near(p.Quantity, tt.quantity) || !near(p.AverageCost(), tt.average) {
+				t.Errorf("got %g at %g, want %g at %g", p.Quantity, p.AverageCost(), tt.quantity, tt.average)
+			}
+			if !near(p.RealizedProfit, tt.profit) || !near(p.RealizedLoss, tt.loss) {
+				t.Errorf("realized a profit of %g and a loss of %g, want %g and %g", p.RealizedProfit, p.RealizedLoss, tt.profit, tt.loss)
+			}
+		})
+	}
+}
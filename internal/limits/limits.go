@@ -0,0 +1,88 @@
+package limits
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/automaxprocs/maxprocs"
+	"go.uber.org/zap"
+)
+
+// cgroupMemoryLimits are the memory limit files of cgroup v2 and v1, as seen from inside a container
+var cgroupMemoryLimits = []string{"/sys/fs/cgroup/memory.max", "/sys/fs/cgroup/memory/memory.limit_in_bytes"}
+
+// unlimited is where cgroup v1 starts reporting no memory limit, it rounds the maximum int64 down to a page
+const unlimited = 1 << 62
+
+func init() {
+	prometheus.Default.MustRegister(prometheus.GathererFunc(gather))
+}
+
+/*
+Setup fits the Go runtime to the limits of the container it runs in, without them Go sees every CPU of the host and is
+throttled by the CPU quota, and collects garbage only once the heap doubled, long after the memory limit OOM killed the
+process. GOMAXPROCS is set to the CPU quota unless the GOMAXPROCS variable is set, the soft memory limit to ratio of the
+container memory limit unless the GOMEMLIMIT variable is set or ratio is 0. Outside of a container nothing changes.
+*/
+func Setup(ratio float64, l *zap.Logger) {
+	if _, err := maxprocs.Set(maxprocs.Logger(l.Sugar().Infof)); err != nil {
+		l.Warn("Failed to set GOMAXPROCS from the CPU quota", zap.Error(err))
+	}
+	if ratio <= 0 || len(os.Getenv("GOMEMLIMIT")) > 0 {
+		return
+	}
+	limit, ok, err := containerMemoryLimit()
+	if err != nil {
+		l.Warn("Failed to read the memory limit of the container", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	memoryLimit := int64(float64(limit) * ratio)
+	debug.SetMemoryLimit(memoryLimit)
+	l.Info("Set the memory limit from the container", zap.Int64("limit_bytes", memoryLimit), zap.Int64("container_bytes", limit), zap.Float64("ratio", ratio))
+}
+
+// containerMemoryLimit returns the memory limit of the cgroup, false if there is none
+func containerMemoryLimit() (int64, bool, error) {
+	for _, path := range cgroupMemoryLimits {
+		raw, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		value := strings.TrimSpace(string(raw))
+		if value == "max" {
+			return 0, false, nil
+		}
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, false, err
+		}
+		return limit, limit < unlimited, nil
+	}
+	return 0, false, nil
+}
+
+// gather exports the effective GOMAXPROCS and soft memory limit, the limit only while there is one
+func gather() []prometheus.Family {
+	procs := prometheus.NewGauge("binance_exporter_gomaxprocs", "Operating system threads the Go runtime executes code on at the same time")
+	memory := prometheus.NewGauge("binance_exporter_memory_limit_bytes", "Soft memory limit of the Go runtime from GOMEMLIMIT or the container memory limit")
+
+	procs.Add(float64(runtime.GOMAXPROCS(0)))
+	// A negative input only reads the limit
+	if limit := debug.SetMemoryLimit(-1); limit < math.MaxInt64 {
+		memory.Add(float64(limit))
+	}
+	return []prometheus.Family{*procs, *memory}
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+/*
+New builds the exporter logger. Errors go to stderr and everything else to stdout, both filtered by the configured
+level. Optionally every entry is also teed to a rotating file and/or syslog. The provided secrets are redacted from
+every entry.
+*/
+func New(cfg config.Log, secrets ...string) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORTER_LOG_LEVEL: %w", err)
+	}
+
+	highPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return lvl >= zapcore.ErrorLevel && lvl >= level
+	})
+	lowPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return lvl < zapcore.ErrorLevel && lvl >= level
+	})
+
+	consoleDebugging := zapcore.Lock(os.Stdout)
+	consoleErrors := zapcore.Lock(os.Stderr)
+
+	encoder := newEncoder(cfg.Format)
+
+	cores := []zapcore.Core{
+		zapcore.NewCore(encoder, consoleErrors, highPriority),
+		zapcore.NewCore(encoder, consoleDebugging, lowPriority),
+	}
+
+	if len(cfg.File.Path) > 0 {
+		cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(&lumberjack.Logger{
+			Filename:   cfg.File.Path,
+			MaxSize:    cfg.File.MaxSizeMB,
+			MaxBackups: cfg.File.MaxBackups,
+			MaxAge:     cfg.File.MaxAgeDays,
+		}), level))
+	}
+
+	if len(cfg.Syslog) > 0 {
+		sc, err := newSyslogCore(cfg.Syslog, encoder, level)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		cores = append(cores, sc)
+	}
+
+	core := zapcore.NewTee(cores...)
+
+	// Make sure signatures and keys never end up in the logs, no matter which call site logs them
+	return zap.New(Redact(core, secrets...)), nil
+}
+
+// newEncoder returns a json encoder for log shippers or the development console encoder for local use
+func newEncoder(format string) zapcore.Encoder {
+	if format == config.LogFormatJSON {
+		ec := zap.NewProductionEncoderConfig()
+		ec.EncodeTime = zapcore.ISO8601TimeEncoder
+		return zapcore.NewJSONEncoder(ec)
+	}
+	return zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+}
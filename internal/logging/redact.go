@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redacted = "REDACTED"
+
+/*
+secretPattern matches secrets carried in query strings (signature=...), json bodies ("listenKey":"...") and
+headers (X-MBX-APIKEY: ...). The first two groups are kept, the value is replaced.
+*/
+var secretPattern = regexp.MustCompile(`(?i)(signature|listenKey|apiKey|secretKey|X-MBX-APIKEY)(=|"\s*:\s*"|:\s*)([^&\s",}]+)`)
+
+// sensitiveKeys are field keys whose values are always secrets, matched case-insensitively as substrings
+var sensitiveKeys = []string{"signature", "secret", "apikey", "api_key", "listenkey", "private"}
+
+/*
+redactingCore wraps a zapcore.Core and scrubs secrets from the message and every string, stringer and error field
+before the entry reaches the wrapped core.
+*/
+type redactingCore struct {
+	zapcore.Core
+	replacer *strings.Replacer
+}
+
+/*
+Redact wraps core so that signatures, listen keys and the provided literal secrets (API keys) never reach it.
+Empty secrets are ignored.
+*/
+func Redact(core zapcore.Core, secrets ...string) zapcore.Core {
+	pairs := make([]string, 0, len(secrets)*2)
+	for _, s := range secrets {
+		if len(s) > 0 {
+			pairs = append(pairs, s, redacted)
+		}
+	}
+	return &redactingCore{Core: core, replacer: strings.NewReplacer(pairs...)}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.fields(fields)), replacer: c.replacer}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.redact(ent.Message)
+	// Go through Check again so a wrapped tee only writes to the cores enabled for this level
+	if ce := c.Core.Check(ent, nil); ce != nil {
+		ce.Write(c.fields(fields)...)
+	}
+	return nil
+}
+
+// sensitiveKey reports whether the whole value of a field with this key is a secret
+func sensitiveKey(key string) bool {
+	k := strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *redactingCore) redact(s string) string {
+	return secretPattern.ReplaceAllString(c.replacer.Replace(s), "${1}${2}"+redacted)
+}
+
+func (c *redactingCore) fields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		if sensitiveKey(f.Key) {
+			out[i] = zap.String(f.Key, redacted)
+			continue
+		}
+		switch f.Type {
+		case zapcore.StringType:
+			f.String = c.redact(f.String)
+		case zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok {
+				f = zap.String(f.Key, c.redact(err.Error()))
+			}
+		case zapcore.StringerType:
+			if s, ok := f.Interface.(interface{ String() string }); ok {
+				f = zap.String(f.Key, c.redact(s.String()))
+			}
+		}
+		out[i] = f
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+/*
+Package maintenance reads the scheduled system maintenance binance announces. Binance has no API for it, the
+announcements of the maintenance catalog of binance.com are the only source. Their start is taken from the title,
+which carries it like
+
+	Binance Will Perform Scheduled System Maintenance (2024-03-20 03:00 UTC)
+
+A title with a date but no time starts at 00:00 UTC of that date, titles without a date are ignored.
+*/
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+type (
+	// Window is a scheduled maintenance
+	Window struct {
+		ID        string // Code of the announcement
+		Title     string
+		Start     time.Time
+		Announced time.Time
+	}
+
+	// Feed fetches the announcements from URL
+	Feed struct {
+		httpclient *http.Client
+		url        string
+		userAgent  string
+	}
+
+	// articleList is the body of the article list query of the binance CMS
+	articleList struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Catalogs []struct {
+				Articles []article `json:"articles"`
+			} `json:"catalogs"`
+		} `json:"data"`
+	}
+
+	article struct {
+		Code        string `json:"code"`
+		Title       string `json:"title"`
+		ReleaseDate int64  `json:"releaseDate"` // Unix milliseconds
+	}
+)
+
+// start matches the date and optional time of the maintenance in an announcement title
+var start = regexp.MustCompile(`(\d{4}-\d{2}-\d{2})(?:[ T]+(\d{1,2}:\d{2}))?`)
+
+func New(url, userAgent string, timeout time.Duration) *Feed {
+	return &Feed{httpclient: &http.Client{Timeout: timeout}, url: url, userAgent: userAgent}
+}
+
+// Fetch returns the maintenance windows of the latest announcements
+func (f *Feed) Fetch(ctx context.Context) ([]Window, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	res, err := f.httpclient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("maintenance url answered %s", res.Status)
+	}
+
+	list := articleList{}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("invalid announcements: %w", err)
+	}
+	if len(list.Code) > 0 && list.Code != "000000" {
+		return nil, fmt.Errorf("announcements failed with code %s: %s", list.Code, list.Message)
+	}
+	windows := make([]Window, 0)
+	for _, catalog := range list.Data.Catalogs {
+		for _, a := range catalog.Articles {
+			if t, ok := parseStart(a.Title); ok {
+				windows = append(windows, Window{ID: a.Code, Title: a.Title, Start: t, Announced: time.UnixMilli(a.ReleaseDate)})
+			}
+		}
+	}
+	return windows, nil
+}
+
+// parseStart returns the start of the maintenance in the title, false if it has no date
+func parseStart(title string) (time.Time, bool) {
+	m := start.FindStringSubmatch(title)
+	if m == nil {
+		return time.Time{}, false
+	}
+	if len(m[2]) > 0 {
+		if t, err := time.Parse("2006-01-02 15:04", m[1]+" "+m[2]); err == nil {
+			return t, true
+		}
+	}
+	t, err := time.Parse("2006-01-02", m[1])
+	return t, err == nil
+}
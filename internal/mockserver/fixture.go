@@ -0,0 +1,74 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// redacted replaces the value of any sensitive field in a recorded body
+const redacted = "REDACTED"
+
+// sensitiveFields are stripped from recorded bodies so fixtures can be committed
+var sensitiveFields = map[string]struct{}{
+	"apiKey":     {},
+	"secretKey":  {},
+	"listenKey":  {},
+	"address":    {},
+	"addressTag": {},
+	"txId":       {},
+	"email":      {},
+}
+
+// Fixture is a recorded binance response
+type Fixture struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"` // Only the ones in recordedHeaders
+	Body    json.RawMessage   `json:"body"`
+}
+
+// recordedHeaders are the response headers the exporter reads, everything else is left out of the fixtures
+var recordedHeaders = []string{"X-Mbx-Used-Weight-1m"}
+
+/*
+fixtureName maps a request to the file its response is stored in. The query string is ignored since it mostly
+carries timestamps and signatures which change on every request.
+*/
+func fixtureName(method, path string) string {
+	p := strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")
+	return method + "_" + p + ".json"
+}
+
+/*
+Scrub returns the body with the values of all sensitive fields replaced. Bodies that are not json are stored as a
+json string so the fixture file stays valid.
+*/
+func Scrub(body []byte) json.RawMessage {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		s, _ := json.Marshal(string(body))
+		return s
+	}
+	out, err := json.Marshal(scrubValue(v))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func scrubValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if _, ok := sensitiveFields[k]; ok {
+				t[k] = redacted
+				continue
+			}
+			t[k] = scrubValue(child)
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = scrubValue(child)
+		}
+	}
+	return v
+}
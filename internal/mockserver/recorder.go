@@ -0,0 +1,74 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+/*
+Recorder is a http.RoundTripper that stores every response it proxies as a fixture. Only the method and path of the
+request end up in the fixture name, so signatures and keys never get written to disk.
+*/
+type Recorder struct {
+	dir    string
+	next   http.RoundTripper
+	logger *zap.Logger
+	lock   sync.Mutex
+}
+
+func NewRecorder(dir string, next http.RoundTripper, l *zap.Logger) *Recorder {
+	return &Recorder{dir: dir, next: next, logger: l}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	f := Fixture{Status: res.StatusCode, Body: Scrub(body)}
+	for _, h := range recordedHeaders {
+		if v := res.Header.Get(h); len(v) > 0 {
+			if f.Headers == nil {
+				f.Headers = make(map[string]string)
+			}
+			f.Headers[h] = v
+		}
+	}
+	r.save(fixtureName(req.Method, req.URL.Path), f)
+	return res, nil
+}
+
+func (r *Recorder) save(name string, f Fixture) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	out, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		r.logger.Warn("Failed to encode fixture.", zap.String("fixture", name), zap.Error(err))
+		return
+	}
+	if err = os.MkdirAll(r.dir, 0o755); err != nil {
+		r.logger.Warn("Failed to create fixture directory.", zap.String("dir", r.dir), zap.Error(err))
+		return
+	}
+	if err = os.WriteFile(filepath.Join(r.dir, name), out, 0o644); err != nil {
+		r.logger.Warn("Failed to write fixture.", zap.String("fixture", name), zap.Error(err))
+		return
+	}
+	r.logger.Debug("Recorded fixture", zap.String("fixture", name), zap.Int("status", f.Status))
+}
@@ -0,0 +1,60 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// Server replays recorded binance responses from a fixture directory
+type Server struct {
+	dir    string
+	logger *zap.Logger
+}
+
+func New(dir string, l *zap.Logger) *Server {
+	return &Server{dir: dir, logger: l}
+}
+
+// Start runs a server for the fixture directory on a random local port, callers have to Close it
+func Start(dir string, l *zap.Logger) *httptest.Server {
+	return httptest.NewServer(New(dir, l))
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := fixtureName(r.Method, r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+
+	raw, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, os.ErrNotExist) {
+			status = http.StatusNotFound
+		}
+		s.logger.Warn("No fixture for request", zap.String("method", r.Method), zap.String("path", r.URL.Path), zap.String("fixture", name))
+		w.WriteHeader(status)
+		_, _ = fmt.Fprintf(w, `{"code":-1,"msg":"no fixture %s"}`, name)
+		return
+	}
+
+	f := Fixture{}
+	if err = json.Unmarshal(raw, &f); err != nil {
+		s.logger.Error("Failed to decode fixture.", zap.String("fixture", name), zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, `{"code":-1,"msg":"invalid fixture %s"}`, name)
+		return
+	}
+
+	s.logger.Debug("Serving fixture", zap.String("fixture", name), zap.Int("status", f.Status))
+	for h, v := range f.Headers {
+		w.Header().Set(h, v)
+	}
+	w.WriteHeader(f.Status)
+	_, _ = w.Write(f.Body)
+}
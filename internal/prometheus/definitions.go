@@ -1,8 +1,66 @@
 package prometheus
 
+import "time"
+
+const (
+	// ContentType is the content type of the text exposition format rendered by Write
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+	// OpenMetricsType is the content type of the OpenMetrics format rendered by WriteOpenMetrics
+	OpenMetricsType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+const (
+	GaugeType     = "gauge"
+	CounterType   = "counter"
+	HistogramType = "histogram"
+)
+
 type (
-	Gauge struct {
-		Name string // Actual name that appears after #TYPE
-		Type string // Display this is f,d,s etc
+	// Family is a group of samples sharing the same metric name, help text and type
+	Family struct {
+		Name    string // Actual name that appears after #TYPE
+		Help    string // Human readable description that appears after #HELP
+		Type    string // One of GaugeType, CounterType, HistogramType
+		Samples []Sample
+	}
+
+	// Sample is a single value of a family, identified by its labels
+	Sample struct {
+		Suffix   string // Appended to the family name, e.g. _bucket, _sum and _count of histograms
+		Labels   []Label
+		Value    float64
+		Exemplar *Exemplar // Only rendered by WriteOpenMetrics
+	}
+
+	// Exemplar links a sample to an example of what it counted, e.g. the trace of a slow request
+	Exemplar struct {
+		Labels    []Label
+		Value     float64
+		Timestamp time.Time
+	}
+
+	Label struct {
+		Name  string
+		Value string
 	}
 )
+
+// NewGauge creates an empty gauge family
+func NewGauge(name, help string) *Family {
+	return &Family{Name: name, Help: help, Type: GaugeType}
+}
+
+// NewCounter creates an empty counter family
+func NewCounter(name, help string) *Family {
+	return &Family{Name: name, Help: help, Type: CounterType}
+}
+
+// Add appends a sample with the given labels to the family
+func (f *Family) Add(value float64, labels ...Label) {
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: value})
+}
+
+// L is a shorthand for building a Label
+func L(name, value string) Label {
+	return Label{Name: name, Value: value}
+}
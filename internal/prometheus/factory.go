@@ -0,0 +1,99 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrDuplicate    = errors.New("metric registered twice")
+	ErrInvalidName  = errors.New("invalid metric name")
+	ErrInvalidLabel = errors.New("invalid label name")
+	ErrLabelValues  = errors.New("wrong number of label values")
+)
+
+var factoryErrors = NewCounterVec("binance_metric_factory_errors_total", "Metric definitions and updates the metric factory rejected, by reason", "reason")
+
+func init() {
+	Default.MustRegister(factoryErrors)
+}
+
+/*
+Factory creates vectors and registers them with a registry, for code that shouldn't be able to crash the exporter like
+plugin collectors. Names get the binance namespace unless they have it, names and labels are validated and duplicate
+names are rejected. Every rejection, including updates with the wrong number of label values later on, is returned or
+dropped and counted in binance_metric_factory_errors_total instead of panicking.
+*/
+type Factory struct {
+	registry *Registry
+}
+
+func NewFactory(r *Registry) *Factory {
+	return &Factory{registry: r}
+}
+
+func (f *Factory) NewCounterVec(name, help string, labels ...string) (*Vec, error) {
+	return f.vec(CounterType, name, help, labels)
+}
+
+func (f *Factory) NewGaugeVec(name, help string, labels ...string) (*Vec, error) {
+	return f.vec(GaugeType, name, help, labels)
+}
+
+func (f *Factory) NewHistogramVec(name, help string, buckets []float64, labels ...string) (*HistogramVec, error) {
+	name, err := f.validate(name, labels, true)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			factoryErrors.Inc("invalid_buckets")
+			return nil, fmt.Errorf("buckets of %s are not increasing", name)
+		}
+	}
+	h := NewHistogramVec(name, help, buckets, labels...)
+	h.onError = f.onError
+	return h, f.register(h)
+}
+
+func (f *Factory) vec(typ, name, help string, labels []string) (*Vec, error) {
+	name, err := f.validate(name, labels, false)
+	if err != nil {
+		return nil, err
+	}
+	v := &Vec{name: name, help: help, typ: typ, labels: labels, values: make(map[string]*vecValue), onError: f.onError}
+	return v, f.register(v)
+}
+
+// validate returns the namespaced name, histograms reserve the le label for their buckets
+func (f *Factory) validate(name string, labels []string, histogram bool) (string, error) {
+	if !strings.HasPrefix(name, DefaultNamespace+"_") {
+		name = DefaultNamespace + "_" + name
+	}
+	if !ValidName(name) {
+		factoryErrors.Inc("invalid_name")
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	seen := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		if !ValidName(l) || strings.HasPrefix(l, "__") || seen[l] || (histogram && l == "le") {
+			factoryErrors.Inc("invalid_label")
+			return "", fmt.Errorf("%w: %q of %s", ErrInvalidLabel, l, name)
+		}
+		seen[l] = true
+	}
+	return name, nil
+}
+
+func (f *Factory) register(g Gatherer) error {
+	if err := f.registry.Register(g); err != nil {
+		factoryErrors.Inc("duplicate")
+		return err
+	}
+	return nil
+}
+
+func (f *Factory) onError(error) {
+	factoryErrors.Inc("label_values")
+}
@@ -0,0 +1,106 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets suit the duration of HTTP requests in seconds
+var LatencyBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type (
+	// HistogramVec is a thread safe set of histograms sharing a name, buckets and label names
+	HistogramVec struct {
+		name    string
+		help    string
+		buckets []float64 // Upper bounds in increasing order, the +Inf bucket is implicit
+		labels  []string
+		lock    sync.Mutex
+		values  map[string]*histogramValue
+		onError func(error) // Called instead of panicking on observations with the wrong number of label values
+	}
+	histogramValue struct {
+		labels    []string
+		counts    []uint64 // Observations per bucket, not cumulative, the last one is +Inf
+		exemplars []*Exemplar
+		sum       float64
+		count     uint64
+	}
+)
+
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return &HistogramVec{name: name, help: help, buckets: buckets, labels: labels, values: make(map[string]*histogramValue)}
+}
+
+func (h *HistogramVec) Name() string {
+	return h.name
+}
+
+// Observe adds v to the histogram identified by the label values
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.ObserveWithExemplar(v, nil, labelValues...)
+}
+
+// ObserveWithExemplar adds v and makes it the exemplar of its bucket, unless exemplar is empty
+func (h *HistogramVec) ObserveWithExemplar(v float64, exemplar []Label, labelValues ...string) {
+	if h.onError != nil && len(labelValues) != len(h.labels) {
+		h.onError(fmt.Errorf("%w: %s expects %d label values, got %d", ErrLabelValues, h.name, len(h.labels), len(labelValues)))
+		return
+	}
+	k := key(h.name, h.labels, labelValues)
+	i := sort.SearchFloat64s(h.buckets, v)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	val, ok := h.values[k]
+	if !ok {
+		val = &histogramValue{
+			labels:    append([]string(nil), labelValues...),
+			counts:    make([]uint64, len(h.buckets)+1),
+			exemplars: make([]*Exemplar, len(h.buckets)+1),
+		}
+		h.values[k] = val
+	}
+	val.counts[i]++
+	val.sum += v
+	val.count++
+	if len(exemplar) > 0 {
+		val.exemplars[i] = &Exemplar{Labels: exemplar, Value: v, Timestamp: time.Now()}
+	}
+}
+
+// Gather returns the cumulative buckets, sum and count of every histogram as a single family ordered by label values
+func (h *HistogramVec) Gather() []Family {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	keys := make([]string, 0, len(h.values))
+	for k := range h.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	f := Family{Name: h.name, Help: h.help, Type: HistogramType, Samples: make([]Sample, 0, len(keys)*(len(h.buckets)+3))}
+	for _, k := range keys {
+		val := h.values[k]
+		labels := make([]Label, len(h.labels))
+		for i, name := range h.labels {
+			labels[i] = L(name, val.labels[i])
+		}
+		cumulative := uint64(0)
+		for i := range val.counts {
+			cumulative += val.counts[i]
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = string(appendValue(nil, h.buckets[i]))
+			}
+			bucket := append(labels[:len(labels):len(labels)], L("le", le))
+			f.Samples = append(f.Samples, Sample{Suffix: "_bucket", Labels: bucket, Value: float64(cumulative), Exemplar: val.exemplars[i]})
+		}
+		f.Samples = append(f.Samples, Sample{Suffix: "_sum", Labels: labels, Value: val.sum})
+		f.Samples = append(f.Samples, Sample{Suffix: "_count", Labels: labels, Value: float64(val.count)})
+	}
+	return []Family{f}
+}
@@ -0,0 +1,95 @@
+package prometheus
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultNamespace is the namespace all metrics are defined with
+const DefaultNamespace = "binance"
+
+var nameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+/*
+Naming rewrites metric names and attaches constant labels at exposition time, so metrics are defined with their
+canonical binance_ names everywhere in the code and only the rendered output changes.
+*/
+type Naming struct {
+	Namespace   string // Replaces DefaultNamespace, empty keeps it
+	Subsystem   string // Inserted between the namespace and the rest of the name
+	ConstLabels []Label
+}
+
+// ValidName reports whether s can be used as a metric or label name
+func ValidName(s string) bool {
+	return nameRegexp.MatchString(s)
+}
+
+// Apply returns copies of the families with renamed metrics and the constant labels appended to every sample
+func (n Naming) Apply(families []Family) []Family {
+	if (len(n.Namespace) == 0 || n.Namespace == DefaultNamespace) && len(n.Subsystem) == 0 && len(n.ConstLabels) == 0 {
+		return families
+	}
+	out := make([]Family, len(families))
+	for i, f := range families {
+		f.Name = n.Name(f.Name)
+		if len(n.ConstLabels) > 0 {
+			samples := make([]Sample, len(f.Samples))
+			for j, s := range f.Samples {
+				s.Labels = n.withConstLabels(s.Labels)
+				samples[j] = s
+			}
+			f.Samples = samples
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// Name returns the exported name of the metric, e.g. for queries against it
+func (n Naming) Name(name string) string {
+	rest := strings.TrimPrefix(name, DefaultNamespace+"_")
+	namespace := n.Namespace
+	if len(namespace) == 0 {
+		namespace = DefaultNamespace
+	}
+	parts := []string{namespace}
+	if len(n.Subsystem) > 0 {
+		parts = append(parts, n.Subsystem)
+	}
+	return strings.Join(append(parts, rest), "_")
+}
+
+// Query applies the naming to every metric with the default namespace in a PromQL expression
+func (n Naming) Query(expr string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(expr, DefaultNamespace+"_")
+		if i < 0 {
+			return b.String() + expr
+		}
+		end := i
+		for end < len(expr) && (expr[end] == '_' || expr[end] >= 'a' && expr[end] <= 'z' || expr[end] >= '0' && expr[end] <= '9') {
+			end++
+		}
+		b.WriteString(expr[:i])
+		b.WriteString(n.Name(expr[i:end]))
+		expr = expr[end:]
+	}
+}
+
+// withConstLabels appends the constant labels, labels already set on the sample win
+func (n Naming) withConstLabels(labels []Label) []Label {
+	out := make([]Label, len(labels), len(labels)+len(n.ConstLabels))
+	copy(out, labels)
+outer:
+	for _, cl := range n.ConstLabels {
+		for _, l := range labels {
+			if l.Name == cl.Name {
+				continue outer
+			}
+		}
+		out = append(out, cl)
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type (
+	// Gatherer produces metric families at scrape time
+	Gatherer interface {
+		Gather() []Family
+	}
+
+	// GathererFunc adapts a plain function to a Gatherer
+	GathererFunc func() []Family
+
+	// Registry collects the families of all registered gatherers
+	Registry struct {
+		lock      sync.RWMutex
+		gatherers []Gatherer
+		names     map[string]struct{}
+	}
+)
+
+// Default is the registry package level metrics register themselves with
+var Default = NewRegistry()
+
+func (f GathererFunc) Gather() []Family {
+	return f()
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]struct{})}
+}
+
+// MustRegister adds the gatherers to the registry, it panics if a vector with the same name was already registered
+func (r *Registry) MustRegister(gs ...Gatherer) {
+	for _, g := range gs {
+		if err := r.Register(g); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+// Register adds the gatherer to the registry unless it is a vector with the name of one that was already registered
+func (r *Registry) Register(g Gatherer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if v, ok := g.(interface{ Name() string }); ok {
+		if _, dup := r.names[v.Name()]; dup {
+			return fmt.Errorf("%w: %s", ErrDuplicate, v.Name())
+		}
+		r.names[v.Name()] = struct{}{}
+	}
+	r.gatherers = append(r.gatherers, g)
+	return nil
+}
+
+// Gather returns the families of all gatherers ordered by name
+func (r *Registry) Gather() []Family {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	var families []Family
+	for _, g := range r.gatherers {
+		families = append(families, g.Gather()...)
+	}
+	sort.SliceStable(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+	return families
+}
@@ -0,0 +1,131 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type (
+	// Vec is a thread safe set of counters or gauges sharing a name and label names, updated as events happen
+	Vec struct {
+		name   string
+		help   string
+		typ    string
+		labels []string
+		lock   sync.Mutex
+		values map[string]*vecValue
+		// Called instead of panicking on updates with the wrong number of label values, see Factory
+		onError func(error)
+	}
+	vecValue struct {
+		labels []string
+		value  float64
+	}
+)
+
+func NewCounterVec(name, help string, labels ...string) *Vec {
+	return &Vec{name: name, help: help, typ: CounterType, labels: labels, values: make(map[string]*vecValue)}
+}
+
+func NewGaugeVec(name, help string, labels ...string) *Vec {
+	return &Vec{name: name, help: help, typ: GaugeType, labels: labels, values: make(map[string]*vecValue)}
+}
+
+func (v *Vec) Name() string {
+	return v.name
+}
+
+// Inc increments the series identified by the label values by one
+func (v *Vec) Inc(labelValues ...string) {
+	v.Add(1, labelValues...)
+}
+
+// Add adds delta to the series identified by the label values
+func (v *Vec) Add(delta float64, labelValues ...string) {
+	if !v.valid(labelValues) {
+		return
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.get(labelValues).value += delta
+}
+
+// Set overwrites the series identified by the label values, only meaningful for gauges
+func (v *Vec) Set(value float64, labelValues ...string) {
+	if !v.valid(labelValues) {
+		return
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.get(labelValues).value = value
+}
+
+// valid reports whether there is a value for every label, vectors without onError panic in key instead
+func (v *Vec) valid(labelValues []string) bool {
+	if v.onError == nil || len(labelValues) == len(v.labels) {
+		return true
+	}
+	v.onError(fmt.Errorf("%w: %s expects %d label values, got %d", ErrLabelValues, v.name, len(v.labels), len(labelValues)))
+	return false
+}
+
+// Delete removes the series identified by the label values
+func (v *Vec) Delete(labelValues ...string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	delete(v.values, v.key(labelValues))
+}
+
+// Reset removes all series
+func (v *Vec) Reset() {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.values = make(map[string]*vecValue)
+}
+
+// Gather returns the current values as a single family ordered by label values
+func (v *Vec) Gather() []Family {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	f := Family{Name: v.name, Help: v.help, Type: v.typ, Samples: make([]Sample, 0, len(keys))}
+	for _, k := range keys {
+		val := v.values[k]
+		labels := make([]Label, len(v.labels))
+		for i, name := range v.labels {
+			labels[i] = L(name, val.labels[i])
+		}
+		f.Samples = append(f.Samples, Sample{Labels: labels, Value: val.value})
+	}
+	return []Family{f}
+}
+
+func (v *Vec) get(labelValues []string) *vecValue {
+	k := v.key(labelValues)
+	val, ok := v.values[k]
+	if !ok {
+		val = &vecValue{labels: append([]string(nil), labelValues...)}
+		v.values[k] = val
+	}
+	return val
+}
+
+func (v *Vec) key(labelValues []string) string {
+	return key(v.name, v.labels, labelValues)
+}
+
+// key identifies the series of a vector by its label values, it panics unless there is a value for every label
+func key(name string, labels, labelValues []string) string {
+	if len(labelValues) != len(labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", name, len(labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
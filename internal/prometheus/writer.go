@@ -0,0 +1,171 @@
+package prometheus
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// writers are reused across scrapes, every scrape renders about the same amount of output
+var writers = sync.Pool{New: func() interface{} { return bufio.NewWriterSize(nil, 32*1024) }}
+
+/*
+Write renders the families in the prometheus text exposition format. Families sharing a name, e.g. the same metric
+exported by several collectors, are merged since the format requires all samples of a metric to be grouped together.
+Families without samples are skipped.
+*/
+func Write(w io.Writer, families ...Family) error {
+	bw := writers.Get().(*bufio.Writer)
+	bw.Reset(w)
+	defer func() {
+		bw.Reset(nil)
+		writers.Put(bw)
+	}()
+	var value []byte // Scratch space of the formatted values
+	for _, f := range Merge(families) {
+		if len(f.Samples) == 0 {
+			continue
+		}
+		if len(f.Help) > 0 {
+			bw.WriteString("# HELP ")
+			bw.WriteString(f.Name)
+			bw.WriteByte(' ')
+			bw.WriteString(helpEscaper.Replace(f.Help))
+			bw.WriteByte('\n')
+		}
+		bw.WriteString("# TYPE ")
+		bw.WriteString(f.Name)
+		bw.WriteByte(' ')
+		bw.WriteString(f.Type)
+		bw.WriteByte('\n')
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name)
+			bw.WriteString(s.Suffix)
+			writeLabels(bw, s.Labels)
+			bw.WriteByte(' ')
+			value = appendValue(value[:0], s.Value)
+			bw.Write(value)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+/*
+WriteOpenMetrics renders the families in the OpenMetrics text format, which unlike the prometheus format carries
+exemplars. Counters are declared without their _total suffix as OpenMetrics requires, counters not named _total are
+declared unknown since OpenMetrics counter samples always end in _total.
+*/
+func WriteOpenMetrics(w io.Writer, families ...Family) error {
+	bw := writers.Get().(*bufio.Writer)
+	bw.Reset(w)
+	defer func() {
+		bw.Reset(nil)
+		writers.Put(bw)
+	}()
+	var value []byte
+	for _, f := range Merge(families) {
+		if len(f.Samples) == 0 {
+			continue
+		}
+		name, typ := f.Name, f.Type
+		if typ == CounterType {
+			if trimmed, ok := strings.CutSuffix(name, "_total"); ok {
+				name = trimmed
+			} else {
+				typ = "unknown"
+			}
+		}
+		if len(f.Help) > 0 {
+			bw.WriteString("# HELP ")
+			bw.WriteString(name)
+			bw.WriteByte(' ')
+			bw.WriteString(labelEscaper.Replace(f.Help))
+			bw.WriteByte('\n')
+		}
+		bw.WriteString("# TYPE ")
+		bw.WriteString(name)
+		bw.WriteByte(' ')
+		bw.WriteString(typ)
+		bw.WriteByte('\n')
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name)
+			bw.WriteString(s.Suffix)
+			writeLabels(bw, s.Labels)
+			bw.WriteByte(' ')
+			value = appendValue(value[:0], s.Value)
+			bw.Write(value)
+			if e := s.Exemplar; e != nil {
+				bw.WriteString(" # ")
+				if len(e.Labels) == 0 {
+					bw.WriteString("{}")
+				}
+				writeLabels(bw, e.Labels)
+				bw.WriteByte(' ')
+				value = appendValue(value[:0], e.Value)
+				bw.Write(value)
+				bw.WriteByte(' ')
+				value = strconv.AppendFloat(value[:0], float64(e.Timestamp.UnixNano())/1e9, 'f', 3, 64)
+				bw.Write(value)
+			}
+			bw.WriteByte('\n')
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// Merge combines families with the same name into the first one of them, keeping the order of first appearance
+func Merge(families []Family) []Family {
+	index := make(map[string]int, len(families))
+	out := make([]Family, 0, len(families))
+	for _, f := range families {
+		i, ok := index[f.Name]
+		if !ok {
+			index[f.Name] = len(out)
+			out = append(out, f)
+			continue
+		}
+		merged := out[i]
+		merged.Samples = append(append([]Sample(nil), merged.Samples...), f.Samples...)
+		out[i] = merged
+	}
+	return out
+}
+
+func writeLabels(bw *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	bw.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(l.Name)
+		bw.WriteString(`="`)
+		bw.WriteString(labelEscaper.Replace(l.Value))
+		bw.WriteByte('"')
+	}
+	bw.WriteByte('}')
+}
+
+func appendValue(b []byte, v float64) []byte {
+	switch {
+	case math.IsInf(v, 1):
+		return append(b, "+Inf"...)
+	case math.IsInf(v, -1):
+		return append(b, "-Inf"...)
+	case math.IsNaN(v):
+		return append(b, "NaN"...)
+	}
+	return strconv.AppendFloat(b, v, 'g', -1, 64)
+}
@@ -0,0 +1,25 @@
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+// BenchmarkWrite renders the asset families of an account holding a few hundred assets
+func BenchmarkWrite(b *testing.B) {
+	free := NewGauge("binance_spot_free", "Free balance of the asset")
+	locked := NewGauge("binance_spot_locked", "Locked balance of the asset")
+	for i := 0; i < 300; i++ {
+		asset := fmt.Sprintf("ASSET%d", i)
+		free.Add(float64(i)*1.25, L("asset", asset), L("account", "main"))
+		locked.Add(float64(i)/3, L("asset", asset), L("account", "main"))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Write(io.Discard, *free, *locked); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
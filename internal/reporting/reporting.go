@@ -0,0 +1,67 @@
+package reporting
+
+import (
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+const flushTimeout = 2 * time.Second
+
+// failureThreshold is the number of consecutive collection failures after which a failure streak gets reported
+var failureThreshold = 3
+
+/*
+Setup initialises error reporting to sentry. Without a DSN nothing is set up and every call in this package is a no-op,
+since the sentry hub drops events when it has no client. The returned function flushes pending events.
+*/
+func Setup(dsn, environment string, threshold int) (func(), error) {
+	if len(dsn) == 0 {
+		return func() {}, nil
+	}
+	if threshold > 0 {
+		failureThreshold = threshold
+	}
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         dsn,
+		Environment: environment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() { sentry.Flush(flushTimeout) }, nil
+}
+
+// CaptureError reports err right away, tags give the context (endpoint, status code, binance error code, ...)
+func CaptureError(err error, tags map[string]string) {
+	sentry.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+		sentry.CaptureException(err)
+	})
+}
+
+/*
+CollectionFailed reports a collector that keeps failing. It is called on every failure with the length of the current
+streak, but only the streak reaching the threshold is reported so an outage results in a single event.
+*/
+func CollectionFailed(collector string, consecutive int, err error, tags map[string]string) {
+	if consecutive != failureThreshold {
+		return
+	}
+	t := map[string]string{"collector": collector}
+	for k, v := range tags {
+		t[k] = v
+	}
+	CaptureError(err, t)
+}
+
+/*
+Recover reports a panic and re-panics, it has to be deferred at the top of main and of every long running goroutine.
+*/
+func Recover() {
+	if r := recover(); r != nil {
+		sentry.CurrentHub().Recover(r)
+		sentry.Flush(flushTimeout)
+		panic(r)
+	}
+}
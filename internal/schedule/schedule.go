@@ -0,0 +1,136 @@
+/*
+Package schedule parses recurring time windows. A window is a cron expression of its start followed by its duration:
+
+	30 2 * * 1-5 90m
+
+starts at 02:30 UTC from Monday to Friday and lasts 90 minutes. The five fields are minute, hour, day of month, month
+and day of week (0 or 7 is Sunday), each a *, a number, a range like 1-5 or a list of them, optionally with a step
+like 0-30/10. Like cron, a restricted day of month and day of week match if either does.
+*/
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDuration is the longest window, a week covers every weekly schedule
+const maxDuration = 7 * 24 * time.Hour
+
+type (
+	// Window is a recurring time window in UTC
+	Window struct {
+		source   string
+		fields   [5]field
+		Duration time.Duration
+	}
+
+	// field is the set of values a cron field matches, a bit per value
+	field struct {
+		bits uint64
+		any  bool // The field is *, which decides how days of month and week combine
+	}
+)
+
+// bounds are the lowest and highest value of every field
+var bounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// Parse parses a window of a cron expression followed by a duration like 90m
+func Parse(s string) (*Window, error) {
+	parts := strings.Fields(s)
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("invalid window %q, expected five cron fields and a duration", s)
+	}
+	w := &Window{source: strings.Join(parts, " ")}
+	for i := range w.fields {
+		f, err := parseField(parts[i], bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", s, err)
+		}
+		w.fields[i] = f
+	}
+	// Sunday is both 0 and 7
+	if w.fields[4].bits&(1<<7) != 0 {
+		w.fields[4].bits |= 1
+	}
+	d, err := time.ParseDuration(parts[5])
+	if err != nil {
+		return nil, fmt.Errorf("invalid window %q: %w", s, err)
+	}
+	if d < time.Minute || d > maxDuration {
+		return nil, fmt.Errorf("invalid window %q, the duration has to be between 1m and %s", s, maxDuration)
+	}
+	w.Duration = d
+	return w, nil
+}
+
+func (w *Window) String() string {
+	return w.source
+}
+
+// Contains reports whether t is within a window started at most Duration before it
+func (w *Window) Contains(t time.Time) bool {
+	t = t.UTC().Truncate(time.Minute)
+	for start := time.Duration(0); start < w.Duration; start += time.Minute {
+		if w.starts(t.Add(-start)) {
+			return true
+		}
+	}
+	return false
+}
+
+// starts reports whether a window starts at the minute t
+func (w *Window) starts(t time.Time) bool {
+	if !w.fields[0].has(t.Minute()) || !w.fields[1].has(t.Hour()) || !w.fields[3].has(int(t.Month())) {
+		return false
+	}
+	dom, dow := w.fields[2], w.fields[4]
+	if dom.any || dow.any {
+		return dom.has(t.Day()) && dow.has(int(t.Weekday()))
+	}
+	return dom.has(t.Day()) || dow.has(int(t.Weekday()))
+}
+
+func (f field) has(v int) bool {
+	return f.bits&(1<<uint(v)) != 0
+}
+
+// parseField parses a comma separated list of values, ranges and steps between lo and hi
+func parseField(s string, lo, hi int) (field, error) {
+	f := field{any: s == "*"}
+	for _, part := range strings.Split(s, ",") {
+		span, stepText, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return f, fmt.Errorf("invalid step %q", part)
+			}
+		}
+		from, to := lo, hi
+		if span != "*" {
+			first, last, isRange := strings.Cut(span, "-")
+			var err error
+			if from, err = strconv.Atoi(first); err != nil {
+				return f, fmt.Errorf("invalid value %q", part)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(last); err != nil {
+					return f, fmt.Errorf("invalid range %q", part)
+				}
+			} else if stepped {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return f, fmt.Errorf("%q is out of range %d-%d", part, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			f.bits |= 1 << uint(v)
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/capture"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/labstack/echo/v4"
+)
+
+// AdminAuth rejects requests that do not carry the admin token as a bearer token
+func AdminAuth(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !authorized(c, []string{token}) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or missing admin token")
+			}
+			return next(c)
+		}
+	}
+}
+
+// ConfigHandler returns the effective configuration with secrets masked
+func ConfigHandler(cfg *config.Config) echo.HandlerFunc {
+	redacted := cfg.Redacted()
+	return func(c echo.Context) error {
+		return c.JSONPretty(http.StatusOK, redacted, "  ")
+	}
+}
+
+// RequestsHandler returns the captured outbound requests by endpoint, oldest first
+func RequestsHandler(b *capture.Buffer) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSONPretty(http.StatusOK, b.Exchanges(), "  ")
+	}
+}
+
+/*
+RefreshHandler runs an out-of-band poll cycle, limited to one collector with ?collector=<name>. The cycle runs on ctx
+rather than the request context so a client hanging up doesn't cancel the collection halfway.
+*/
+func RefreshHandler(ctx context.Context, col *collector.Registry) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ran, err := col.Refresh(ctx, c.QueryParam("collector"))
+		switch {
+		case errors.Is(err, collector.ErrUnknownCollector):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, collector.ErrCollectorDisabled):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		case err != nil:
+			return err
+		}
+		return c.JSON(http.StatusOK, map[string][]string{"refreshed": ran})
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/alerting"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/labstack/echo/v4"
+)
+
+// AlertsHandler serves a prometheus rule file with alerts for the collectors enabled right now and the configured thresholds
+func AlertsHandler(cfg *config.Config, col *collector.Registry) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		rules := alerting.Build(alerting.Options{
+			Intervals:      col.Intervals(),
+			Wallets:        col.Wallets(),
+			Naming:         cfg.Metrics.Naming(),
+			StateLabel:     cfg.Metrics.StateLabel,
+			MarginLevel:    cfg.Alerts.MarginLevel,
+			StaleIntervals: cfg.Alerts.StaleIntervals,
+			Failures:       cfg.Alerts.Failures,
+			Depeg:          cfg.Alerts.Depeg,
+			MaxLeverage:    cfg.Alerts.MaxLeverage,
+		})
+		c.Response().Header().Set(echo.HeaderContentType, "application/yaml; charset=utf-8")
+		c.Response().WriteHeader(http.StatusOK)
+		return alerting.Write(c.Response(), rules)
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+/*
+BearerAuth rejects requests that do not carry one of the tokens as a bearer token, empty tokens are ignored. Without any
+token every request is let through, so a route group is only guarded once its token is configured.
+*/
+func BearerAuth(tokens ...string) echo.MiddlewareFunc {
+	tokens = nonEmpty(tokens)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if len(tokens) == 0 {
+			return next
+		}
+		return func(c echo.Context) error {
+			if !authorized(c, tokens) {
+				return unauthorized(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+/*
+AccountAuth guards /metrics/:account with the token of the account or any of the shared tokens, e.g. the metrics token.
+An account without a token of its own only needs a shared one, or none if no shared token is configured either.
+*/
+func AccountAuth(accounts map[string]string, shared ...string) echo.MiddlewareFunc {
+	shared = nonEmpty(shared)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tokens := shared
+			if token := accounts[c.Param("account")]; len(token) > 0 {
+				tokens = append([]string{token}, shared...)
+			}
+			if len(tokens) > 0 && !authorized(c, tokens) {
+				return unauthorized(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// authorized reports whether the request carries one of the tokens, every token is compared in constant time
+func authorized(c echo.Context, tokens []string) bool {
+	given, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !ok {
+		return false
+	}
+	match := 0
+	for _, token := range tokens {
+		match |= subtle.ConstantTimeCompare([]byte(given), []byte(token))
+	}
+	return match == 1
+}
+
+func unauthorized(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+	return echo.NewHTTPError(http.StatusUnauthorized, "invalid or missing token")
+}
+
+func nonEmpty(tokens []string) []string {
+	res := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if len(token) > 0 {
+			res = append(res, token)
+		}
+	}
+	return res
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/grafana"
+	"github.com/labstack/echo/v4"
+)
+
+// DashboardHandler serves a grafana dashboard generated for the collectors enabled right now, ready to be imported
+func DashboardHandler(cfg *config.Config, accounts []string, col *collector.Registry) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSONPretty(http.StatusOK, grafana.Build(grafana.Options{
+			Accounts:   accounts,
+			Collectors: enabledCollectors(col),
+			Wallets:    col.Wallets(),
+			Naming:     cfg.Metrics.Naming(),
+			StateLabel: cfg.Metrics.StateLabel,
+		}), "  ")
+	}
+}
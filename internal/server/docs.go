@@ -0,0 +1,40 @@
+package server
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/labstack/echo/v4"
+)
+
+var docsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Metrics</title></head>
+<body>
+<h1>Metrics</h1>
+<table>
+<tr><th>Name</th><th>Type</th><th>Labels</th><th>Collector</th><th>Endpoints</th><th>Description</th></tr>
+{{range .}}<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{range $i, $l := .Labels}}{{if $i}}, {{end}}{{$l}}{{end}}</td><td>{{.Collector}}</td><td>{{range $i, $e := .Endpoints}}{{if $i}}<br>{{end}}{{$e}}{{end}}</td><td>{{.Help}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// MetricsDocsHandler lists every exported metric with its type, labels, collector and binance endpoints, as json to clients asking for it
+func MetricsDocsHandler(col *collector.Registry, naming prometheus.Naming) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		docs := col.Docs(prometheus.Default.Gather())
+		for i := range docs {
+			docs[i].Name = naming.Name(docs[i].Name)
+		}
+		if strings.Contains(c.Request().Header.Get(echo.HeaderAccept), echo.MIMEApplicationJSON) {
+			return c.JSON(http.StatusOK, docs)
+		}
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		c.Response().WriteHeader(http.StatusOK)
+		return docsTemplate.Execute(c.Response(), docs)
+	}
+}
@@ -0,0 +1,15 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/labstack/echo/v4"
+)
+
+// EventsHandler lists the recent events of the security rules, newest first
+func EventsHandler(col *collector.Registry) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSONPretty(http.StatusOK, map[string][]collector.SecurityEvent{"events": col.Events()}, "  ")
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/labstack/echo/v4"
+)
+
+// HealthHandler answers the liveness probe, the process serving the request is all it checks
+func HealthHandler(c echo.Context) error {
+	return c.String(http.StatusOK, "OK")
+}
+
+/*
+ReadyHandler answers the readiness probe, the exporter is ready once a collection cycle completed. Serving degraded it
+is only ready once every enabled collector, or every one of EXPORTER_READY_COLLECTORS, succeeded at least once, so
+traffic doesn't shift to an exporter without data.
+*/
+func ReadyHandler(col *collector.Registry, cfg config.Startup) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !col.Ready() {
+			return c.String(http.StatusServiceUnavailable, "waiting for the first collection")
+		}
+		if cfg.Policy == config.StartupServeDegraded {
+			if waiting := col.Waiting(cfg.ReadyCollectors); len(waiting) > 0 {
+				return c.String(http.StatusServiceUnavailable, "waiting for the first successful run of "+strings.Join(waiting, ", "))
+			}
+		}
+		return c.String(http.StatusOK, "OK")
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/version"
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Link is an endpoint advertised on the landing page
+	Link struct {
+		Path        string `json:"path"`
+		Description string `json:"description"`
+	}
+	// Landing is what the index page shows, served as json to clients asking for it
+	Landing struct {
+		Name       string   `json:"name"`
+		Version    string   `json:"version"`
+		Accounts   []string `json:"accounts"`
+		Collectors []string `json:"collectors"`
+		Links      []Link   `json:"links"`
+	}
+)
+
+var landingTemplate = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Name}}</title></head>
+<body>
+<h1>{{.Name}}</h1>
+<p>Version {{.Version}}</p>
+<h2>Accounts</h2>
+<ul>{{range .Accounts}}<li>{{.}}</li>{{end}}</ul>
+<h2>Enabled collectors</h2>
+<ul>{{range .Collectors}}<li>{{.}}</li>{{else}}<li>none</li>{{end}}</ul>
+<h2>Endpoints</h2>
+<ul>{{range .Links}}<li><a href="{{.Path}}">{{.Path}}</a> {{.Description}}</li>{{end}}</ul>
+</body>
+</html>
+`))
+
+// DefaultLinks are the endpoints every exporter serves
+var DefaultLinks = []Link{
+	{Path: "/metrics", Description: "Prometheus metrics"},
+	{Path: "/metrics-docs", Description: "Type, labels and source of every exported metric"},
+	{Path: "/healthz", Description: "Liveness probe"},
+	{Path: "/readyz", Description: "Readiness probe"},
+	{Path: "/dashboard.json", Description: "Grafana dashboard of the enabled collectors"},
+	{Path: "/alerts.yaml", Description: "Prometheus alerting rules of the enabled collectors"},
+}
+
+// LandingHandler serves the index page listing the exporter, its accounts, enabled collectors and endpoints
+func LandingHandler(accounts []string, col *collector.Registry, links []Link) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		info := Landing{
+			Name:       version.Name,
+			Version:    version.Version,
+			Accounts:   accounts,
+			Collectors: enabledCollectors(col),
+			Links:      links,
+		}
+		if strings.Contains(c.Request().Header.Get(echo.HeaderAccept), echo.MIMEApplicationJSON) {
+			return c.JSON(http.StatusOK, info)
+		}
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		c.Response().WriteHeader(http.StatusOK)
+		return landingTemplate.Execute(c.Response(), info)
+	}
+}
+
+func enabledCollectors(col *collector.Registry) []string {
+	res := make([]string, 0)
+	for _, c := range col.Collectors() {
+		if c.Enabled() {
+			res = append(res, c.Name())
+		}
+	}
+	return res
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+const (
+	rejectConcurrency = "concurrency"
+	rejectRateLimit   = "rate_limit"
+)
+
+var rejectedScrapes = prometheus.NewCounterVec("binance_scrapes_rejected_total", "Scrapes answered with 429 because of the concurrency or rate limit", "reason")
+
+func init() {
+	prometheus.Default.MustRegister(rejectedScrapes)
+}
+
+// ScrapeConcurrency rejects requests while max of them are already being served, max 0 disables the limit
+func ScrapeConcurrency(max int) echo.MiddlewareFunc {
+	sem := make(chan struct{}, max)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if max <= 0 {
+			return next
+		}
+		return func(c echo.Context) error {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				return next(c)
+			default:
+				rejectedScrapes.Inc(rejectConcurrency)
+				return tooManyRequests(c, time.Second)
+			}
+		}
+	}
+}
+
+// ScrapeRateLimit allows perMinute requests per client IP, perMinute 0 disables the limit
+func ScrapeRateLimit(perMinute int) echo.MiddlewareFunc {
+	if perMinute <= 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	}
+	every := time.Minute / time.Duration(perMinute)
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Every(every),
+			Burst:     1,
+			ExpiresIn: 10 * time.Minute,
+		}),
+		DenyHandler: func(c echo.Context, _ string, _ error) error {
+			rejectedScrapes.Inc(rejectRateLimit)
+			return tooManyRequests(c, every)
+		},
+	})
+}
+
+func tooManyRequests(c echo.Context, retryAfter time.Duration) error {
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	return echo.NewHTTPError(http.StatusTooManyRequests, "too many scrapes")
+}
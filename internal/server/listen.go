@@ -0,0 +1,52 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+/*
+Listen opens the listener for address, either a TCP address like ":1323" or "tcp://127.0.0.1:1323", or a unix domain
+socket like "unix:///run/binance_exporter.sock". A socket left behind by a previous run is removed first, any other
+file at the path is left alone and fails the listen. TCP addresses like ":1323" or "[::]:1323" accept IPv4 and IPv6
+where the system is dual-stack, "tcp4://" and "tcp6://" restrict them to one of the two.
+*/
+func Listen(address string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(address, "unix://"); ok {
+		if len(path) == 0 {
+			return nil, fmt.Errorf("missing socket path in %q", address)
+		}
+		if err := removeSocket(path); err != nil {
+			return nil, err
+		}
+		return net.Listen("unix", path)
+	}
+	for _, network := range []string{"tcp4", "tcp6"} {
+		if host, ok := strings.CutPrefix(address, network+"://"); ok {
+			return net.Listen(network, host)
+		}
+	}
+	return net.Listen("tcp", strings.TrimPrefix(address, "tcp://"))
+}
+
+// removeSocket removes the socket at path, refusing to remove anything that isn't a socket
+func removeSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check for a stale socket: %w", err)
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket, refusing to remove it", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"github.com/labstack/echo/v4"
+)
+
+/*
+MetricsHandler serves the metrics of the collectors and of the exporter itself. With EXPORTER_COLLECT_ON_SCRAPE every
+scrape collects first while collect returns true, e.g. once binance is online and this replica leads.
+*/
+func MetricsHandler(cfg *config.Config, col *collector.Registry, collect func() bool) echo.HandlerFunc {
+	naming := cfg.Metrics.Naming()
+	return func(c echo.Context) error {
+		if cfg.Scrape.OnDemand && collect() {
+			scrapeCtx, cancel := ScrapeContext(c.Request(), cfg.Scrape.Deadline)
+			col.Collect(scrapeCtx)
+			cancel()
+		}
+		return writeFamilies(c, naming.Apply(append(col.Gather(), prometheus.Default.Gather()...)))
+	}
+}
+
+/*
+AccountMetricsHandler serves the metrics of the collectors of the account named by the account path parameter, so a
+team can be given scrape access to its own account only. The metrics of the exporter itself, like the request weight
+used by the process, are shared by all accounts and only served on /metrics.
+*/
+func AccountMetricsHandler(cfg *config.Config, accounts map[string]*collector.Registry, collect func() bool) echo.HandlerFunc {
+	naming := cfg.Metrics.Naming()
+	return func(c echo.Context) error {
+		col, ok := accounts[c.Param("account")]
+		if !ok {
+			return echo.NewHTTPError(http.StatusNotFound, "unknown account")
+		}
+		if cfg.Scrape.OnDemand && collect() {
+			scrapeCtx, cancel := ScrapeContext(c.Request(), cfg.Scrape.Deadline)
+			col.Collect(scrapeCtx)
+			cancel()
+		}
+		return writeFamilies(c, naming.Apply(col.Gather()))
+	}
+}
+
+// writeFamilies writes the families in the exposition format the client asked for
+func writeFamilies(c echo.Context, families []prometheus.Family) error {
+	// Exemplars only exist in OpenMetrics, prometheus asks for it once exemplar storage is enabled
+	if strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "application/openmetrics-text") {
+		c.Response().Header().Set(echo.HeaderContentType, prometheus.OpenMetricsType)
+		c.Response().WriteHeader(http.StatusOK)
+		return prometheus.WriteOpenMetrics(c.Response(), families...)
+	}
+	c.Response().Header().Set(echo.HeaderContentType, prometheus.ContentType)
+	c.Response().WriteHeader(http.StatusOK)
+	return prometheus.Write(c.Response(), families...)
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/tracing"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+/*
+RequestID keeps the X-Request-ID of incoming requests or generates one when it is absent, echoes it in the response and
+puts it into the request context. On-demand collections of a scrape run on that context, so the log lines of their
+binance calls carry the id of the scrape that triggered them.
+*/
+func RequestID() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			req := c.Request()
+			c.SetRequest(req.WithContext(tracing.WithRequestID(req.Context(), id)))
+		},
+	})
+}
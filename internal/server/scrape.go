@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// scrapeTimeoutHeader is sent by prometheus with the scrape timeout of the job
+	scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+	// scrapeTimeoutSlack is left of the scrape timeout to render and send the metrics
+	scrapeTimeoutSlack = 500 * time.Millisecond
+)
+
+/*
+ScrapeContext returns the context an on-demand collection for the scrape runs in. It is cancelled when the scraper
+goes away, so an aborted scrape cancels the binance requests it started, and it ends after deadline or shortly before
+the scrape timeout prometheus announced, whichever comes first.
+*/
+func ScrapeContext(r *http.Request, deadline time.Duration) (context.Context, context.CancelFunc) {
+	if s, err := strconv.ParseFloat(r.Header.Get(scrapeTimeoutHeader), 64); err == nil {
+		if timeout := time.Duration(s*float64(time.Second)) - scrapeTimeoutSlack; timeout > 0 && timeout < deadline {
+			deadline = timeout
+		}
+	}
+	return context.WithTimeout(r.Context(), deadline)
+}
@@ -0,0 +1,122 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	amzDateLayout = "20060102T150405Z"
+	amzDayLayout  = "20060102"
+)
+
+/*
+bucket uploads objects to an S3 compatible bucket with AWS signature version 4. Only the single PUT the snapshots need
+is implemented, which saves pulling in an SDK. Buckets are addressed path style, which AWS, MinIO, Ceph and R2 all
+understand.
+*/
+type bucket struct {
+	httpclient *http.Client
+	endpoint   *url.URL
+	name       string
+	region     string
+	accessKey  string
+	secretKey  string
+	userAgent  string
+}
+
+func newBucket(endpoint, name, region, accessKey, secretKey, userAgent string) (*bucket, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q, expected a http or https url", endpoint)
+	}
+	return &bucket{
+		httpclient: &http.Client{Timeout: time.Minute},
+		endpoint:   u,
+		name:       name,
+		region:     region,
+		accessKey:  accessKey,
+		secretKey:  secretKey,
+		userAgent:  userAgent,
+	}, nil
+}
+
+// put uploads body as the object key
+func (b *bucket) put(ctx context.Context, key, contentType string, body []byte) error {
+	u := *b.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + b.name + "/" + key
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", b.userAgent)
+	b.sign(req, body, time.Now().UTC())
+
+	res, err := b.httpclient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("S3 answered %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// sign adds the signature version 4 authorization header to the request
+func (b *bucket) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", now.Format(amzDateLayout))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	canonicalHeaders := "content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + now.Format(amzDateLayout) + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := now.Format(amzDayLayout) + "/" + b.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format(amzDateLayout) + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+b.secretKey), now.Format(amzDayLayout))
+	key = hmacSHA256(key, b.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.accessKey, scope, strings.Join(signed, ";"), signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,127 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/balance"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/collector"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/config"
+	"github.com/WildSage-Labs/binance_prometheus_exporter/internal/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	uploads     = prometheus.NewCounterVec("binance_snapshot_uploads_total", "Snapshot uploads to object storage by result", "result")
+	lastSuccess = prometheus.NewGaugeVec("binance_snapshot_last_success_timestamp_seconds", "Unix time of the last snapshot uploaded to object storage")
+)
+
+func init() {
+	prometheus.Default.MustRegister(uploads, lastSuccess)
+}
+
+/*
+Uploader writes a snapshot of all balances and valuations to object storage every interval, so there is an audit
+trail that doesn't depend on the retention of the metrics pipeline. Objects are keyed by account and time, e.g.
+snapshots/default/20261014T000000Z.json.
+*/
+type Uploader struct {
+	bucket  *bucket
+	cfg     config.Snapshot
+	account string
+	source  func() collector.Snapshot
+	active  func() bool // Snapshots are skipped while this returns false, e.g. on standby replicas
+	logger  *zap.Logger
+}
+
+// New creates an uploader of the snapshots source returns, active may be nil to always upload
+func New(cfg config.Snapshot, userAgent, account string, source func() collector.Snapshot, active func() bool, l *zap.Logger) (*Uploader, error) {
+	b, err := newBucket(cfg.Endpoint, cfg.Bucket, cfg.Region, cfg.AccessKey, cfg.SecretKey, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	return &Uploader{bucket: b, cfg: cfg, account: account, source: source, active: active, logger: l}, nil
+}
+
+// Run uploads a snapshot right away and then every interval until ctx is done
+func (u *Uploader) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if u.active == nil || u.active() {
+			u.upload(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *Uploader) upload(ctx context.Context) {
+	s := u.source()
+	body, contentType, err := encode(s, u.cfg.Format, u.account)
+	if err == nil {
+		key := u.cfg.Prefix + u.account + "/" + s.Time.Format(amzDateLayout) + "." + u.cfg.Format
+		if err = u.bucket.put(ctx, key, contentType, body); err == nil {
+			uploads.Inc("success")
+			lastSuccess.Set(float64(s.Time.Unix()))
+			u.logger.Info("Uploaded snapshot", zap.String("bucket", u.cfg.Bucket), zap.String("key", key))
+			return
+		}
+	}
+	uploads.Inc("failure")
+	u.logger.Warn("Failed to upload snapshot", zap.String("bucket", u.cfg.Bucket), zap.Error(err))
+}
+
+/*
+encode renders the snapshot as an indented JSON document or as CSV with one row per wallet and asset. The CSV adds a
+row per valuation with the wallet column set to the collector and BTC as asset, followed by the totals.
+*/
+func encode(s collector.Snapshot, format, account string) ([]byte, string, error) {
+	if format == config.SnapshotJSON {
+		doc := struct {
+			Account string `json:"account"`
+			collector.Snapshot
+		}{Account: account, Snapshot: s}
+		body, err := json.MarshalIndent(doc, "", "  ")
+		return body, "application/json", err
+	}
+
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	at := s.Time.Format(time.RFC3339)
+	_ = w.Write([]string{"time", "account", "wallet", "asset", "free", "locked", "freeze", "withdrawing", "btc_valuation"})
+	wallets := make([]string, 0, len(s.Wallets))
+	for name := range s.Wallets {
+		wallets = append(wallets, string(name))
+	}
+	sort.Strings(wallets)
+	for _, name := range wallets {
+		for _, b := range s.Wallets[balance.Wallet(name)] {
+			_ = w.Write([]string{at, account, name, b.Asset, b.Free.String(), b.Locked.String(), b.Freeze.String(), b.Withdrawing.String(), b.ValueBTC.String()})
+		}
+	}
+	collectors := make([]string, 0, len(s.Valuations))
+	for name := range s.Valuations {
+		collectors = append(collectors, name)
+	}
+	sort.Strings(collectors)
+	for _, name := range collectors {
+		_ = w.Write([]string{at, account, "valuation:" + name, "BTC", "", "", "", "", formatFloat(s.Valuations[name])})
+	}
+	_ = w.Write([]string{at, account, "total", "BTC", "", "", "", "", formatFloat(s.TotalBTC)})
+	_ = w.Write([]string{at, account, "total", "USD", "", "", "", "", formatFloat(s.TotalUSD)})
+	w.Flush()
+	return buf.Bytes(), "text/csv", w.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+/*
+Store keeps small amounts of state across restarts, like the cost basis derived from the trade history, so it doesn't
+have to be fetched from binance again. Values are JSON documents grouped in buckets. Every Put rewrites the whole file
+through a rename, the file is never left half written. A store without a path only lives in memory.
+*/
+type Store struct {
+	path string
+	lock sync.Mutex
+	data map[string]map[string]json.RawMessage // bucket -> key -> value
+}
+
+// Default is the store collectors keep their state in, in memory until main opens the configured file
+var Default = New()
+
+// New creates a store that only lives in memory
+func New() *Store {
+	return &Store{data: make(map[string]map[string]json.RawMessage)}
+}
+
+// Open reads the store at path, a missing file is an empty store. An empty path opens an in memory store.
+func Open(path string) (*Store, error) {
+	s := New()
+	if len(path) == 0 {
+		return s, nil
+	}
+	s.path = path
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &s.data); err != nil {
+		return nil, fmt.Errorf("corrupt store %s: %w", path, err)
+	}
+	if s.data == nil {
+		s.data = make(map[string]map[string]json.RawMessage)
+	}
+	return s, nil
+}
+
+// Persistent reports whether the store survives restarts
+func (s *Store) Persistent() bool {
+	return len(s.path) > 0
+}
+
+// Get decodes the value of key into v and reports whether it exists
+func (s *Store) Get(bucket, key string, v interface{}) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	raw, ok := s.data[bucket][key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+// Keys returns the sorted keys of the bucket
+func (s *Store) Keys(bucket string) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	keys := make([]string, 0, len(s.data[bucket]))
+	for key := range s.data[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Put stores v under key and writes the store to disk
+func (s *Store) Put(bucket, key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.data[bucket] == nil {
+		s.data[bucket] = make(map[string]json.RawMessage)
+	}
+	s.data[bucket][key] = raw
+	return s.flush()
+}
+
+// flush writes the store to a temporary file next to it and renames that over the store, the caller holds the lock
+func (s *Store) flush() error {
+	if len(s.path) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,80 @@
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	Ready = "READY=1"
+	Alive = "WATCHDOG=1"
+)
+
+/*
+Notify sends state to the service manager through $NOTIFY_SOCKET. It returns false without an error when the process
+is not running under systemd.
+*/
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if len(socket) == 0 {
+		return false, nil
+	}
+	// Abstract sockets are announced with a leading @
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout configured with WatchdogSec=, 0 when the watchdog is disabled
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); len(pid) > 0 && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+/*
+Watchdog pings the systemd watchdog at half its interval for as long as healthy returns true. Once healthy reports a
+problem, e.g. a hung poller, the pings stop and systemd restarts the service. It returns right away when the watchdog
+is disabled.
+*/
+func Watchdog(ctx context.Context, healthy func() bool, l *zap.Logger) {
+	interval := WatchdogInterval()
+	if interval == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !healthy() {
+				l.Warn("Poller is stuck, skipping the systemd watchdog ping")
+				continue
+			}
+			if _, err := Notify(Alive); err != nil {
+				l.Warn("Failed to ping the systemd watchdog", zap.Error(err))
+			}
+		}
+	}
+}
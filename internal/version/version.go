@@ -0,0 +1,16 @@
+package version
+
+// Name of the exporter as reported on the landing page and to binance
+const Name = "binance_prometheus_exporter"
+
+// Version is set at build time with -ldflags "-X github.com/WildSage-Labs/binance_prometheus_exporter/internal/version.Version=v1.2.3"
+var Version = "dev"
+
+// UserAgent identifies the exporter on outbound requests, the suffix is appended after a space unless it is empty
+func UserAgent(suffix string) string {
+	ua := Name + "/" + Version
+	if len(suffix) > 0 {
+		ua += " " + suffix
+	}
+	return ua
+}